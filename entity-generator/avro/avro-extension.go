@@ -0,0 +1,35 @@
+package avro
+
+import (
+	eg "github.com/mabels/wueste/entity-generator"
+)
+
+// ExtensionHook is the behaviour of an eg.Extension in the avro generator,
+// it is registered in Extension.Hooks with the key "avro".
+type ExtensionHook struct {
+	// Field changes the field of a property which has the extension.
+	Field func(field eg.JSONDict, value any)
+	// Record changes the record of an object which has the extension.
+	Record func(record eg.JSONDict, value any)
+}
+
+// applyExtensions runs the hooks of the extensions of prop on the field or
+// record js.
+func applyExtensions(prop eg.Property, js eg.JSONDict, record bool) {
+	for _, ev := range prop.Meta().Extensions() {
+		hook, found := ev.Hook("avro")
+		if !found {
+			continue
+		}
+		avroHook, ok := hook.(ExtensionHook)
+		if !ok {
+			continue
+		}
+		if record && avroHook.Record != nil {
+			avroHook.Record(js, ev.Value)
+		}
+		if !record && avroHook.Field != nil {
+			avroHook.Field(js, ev.Value)
+		}
+	}
+}
@@ -0,0 +1,312 @@
+package avro
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	eg "github.com/mabels/wueste/entity-generator"
+	"github.com/mabels/wueste/entity-generator/rusty"
+)
+
+type AvroType = string
+
+const (
+	NULL    AvroType = "null"
+	BOOLEAN AvroType = "boolean"
+	INT     AvroType = "int"
+	LONG    AvroType = "long"
+	FLOAT   AvroType = "float"
+	DOUBLE  AvroType = "double"
+	STRING  AvroType = "string"
+	RECORD  AvroType = "record"
+	ARRAY   AvroType = "array"
+	MAP     AvroType = "map"
+	ENUM    AvroType = "enum"
+)
+
+const (
+	TIMESTAMP_MILLIS = "timestamp-millis"
+	DATE             = "date"
+	TIME_MILLIS      = "time-millis"
+)
+
+// the json name of a field is kept in this attribute if it is not a valid avro name
+const JSON_NAME = "jsonName"
+
+var reNonAvroName = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+var reAvroNameStart = regexp.MustCompile(`^[^A-Za-z_]`)
+
+func avroName(name string) string {
+	name = reNonAvroName.ReplaceAllString(name, "_")
+	if name == "" || reAvroNameStart.MatchString(name) {
+		name = "_" + name
+	}
+	return name
+}
+
+func avroNamespace(parts []string) string {
+	out := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p == "" {
+			continue
+		}
+		out = append(out, strings.ToLower(avroName(p)))
+	}
+	return strings.Join(out, ".")
+}
+
+type avroGenerator struct {
+	namespace string
+	// fullnames of the records already defined in this schema to the id of
+	// their object
+	defined map[string]string
+}
+
+// objectNames walks up the parents like getObjectName in the ts generator
+// and returns the names from the root to the given object.
+func objectNames(p eg.Property, fieldName string) []string {
+	names := []string{}
+	if p.Type() == eg.OBJECT {
+		title := p.(eg.PropertyObject).Title()
+		if title == "" {
+			title = fieldName
+		}
+		names = append(names, title)
+	}
+	parent := p.Meta().Parent()
+	for parent.IsSome() {
+		if parent.Value().Type() == eg.OBJECT {
+			names = append(names, parent.Value().(eg.PropertyObject).Title())
+		}
+		parent = parent.Value().Meta().Parent()
+	}
+	for i, j := 0, len(names)-1; i < j; i, j = i+1, j-1 {
+		names[i], names[j] = names[j], names[i]
+	}
+	return names
+}
+
+func (g *avroGenerator) recordName(po eg.PropertyObject, fieldName string) (string, string) {
+	names := objectNames(po, fieldName)
+	name := avroName(names[len(names)-1])
+	ns := avroNamespace(append([]string{g.namespace}, names[:len(names)-1]...))
+	return name, ns
+}
+
+func fullName(name, ns string) string {
+	if ns == "" {
+		return name
+	}
+	return ns + "." + name
+}
+
+func (g *avroGenerator) toAvroType(prop eg.Property, fieldName string) rusty.Result[any] {
+	switch prop.Type() {
+	case eg.OBJECT:
+		po := prop.(eg.PropertyObject)
+		if po.Properties() == nil || po.Properties().Len() == 0 {
+			ret := eg.NewJSONDict()
+			ret.Set("type", MAP)
+			ret.Set("values", []any{NULL, BOOLEAN, LONG, DOUBLE, STRING})
+			return rusty.Ok[any](ret)
+		}
+		return g.toRecord(po, fieldName)
+	case eg.STRING:
+		ps := prop.(eg.PropertyString)
+		if ps.Format().IsSome() {
+			ret := eg.NewJSONDict()
+			switch ps.Format().Value() {
+			case eg.DATE_TIME:
+				ret.Set("type", LONG)
+				ret.Set("logicalType", TIMESTAMP_MILLIS)
+				return rusty.Ok[any](ret)
+			case eg.DATE:
+				ret.Set("type", INT)
+				ret.Set("logicalType", DATE)
+				return rusty.Ok[any](ret)
+			case eg.TIME:
+				ret.Set("type", INT)
+				ret.Set("logicalType", TIME_MILLIS)
+				return rusty.Ok[any](ret)
+			}
+		}
+		return rusty.Ok[any](STRING)
+	case eg.INTEGER:
+		pi := prop.(eg.PropertyInteger)
+		if pi.Format().IsSome() && pi.Format().Value() == "int32" {
+			return rusty.Ok[any](INT)
+		}
+		return rusty.Ok[any](LONG)
+	case eg.NUMBER:
+		pn := prop.(eg.PropertyNumber)
+		if pn.Format().IsSome() && pn.Format().Value() == "float32" {
+			return rusty.Ok[any](FLOAT)
+		}
+		return rusty.Ok[any](DOUBLE)
+	case eg.BOOLEAN:
+		return rusty.Ok[any](BOOLEAN)
+	case eg.ARRAY:
+		items := g.toAvroType(prop.(eg.PropertyArray).Items(), fieldName)
+		if items.IsErr() {
+			return items
+		}
+		ret := eg.NewJSONDict()
+		ret.Set("type", ARRAY)
+		ret.Set("items", items.Ok())
+		return rusty.Ok[any](ret)
+	default:
+		return rusty.Err[any](fmt.Errorf("avro unknown type %s", prop.Type()))
+	}
+}
+
+func (g *avroGenerator) toRecord(po eg.PropertyObject, fieldName string) rusty.Result[any] {
+	name, ns := g.recordName(po, fieldName)
+	fname := fullName(name, ns)
+	if id, found := g.defined[fname]; found {
+		if id != po.Id() {
+			return rusty.Err[any](fmt.Errorf("avro record %s is defined by %s and %s", fname, id, po.Id()))
+		}
+		return rusty.Ok[any](fname)
+	}
+	g.defined[fname] = po.Id()
+	ret := eg.NewJSONDict()
+	ret.Set("type", RECORD)
+	ret.Set("name", name)
+	if ns != "" {
+		ret.Set("namespace", ns)
+	}
+	if po.Description().IsSome() {
+		ret.Set("doc", po.Description().Value())
+	}
+	fields := []any{}
+	for _, pi := range po.Items() {
+		rField := g.toField(pi)
+		if rField.IsErr() {
+			return rusty.Err[any](rField.Err())
+		}
+		fields = append(fields, rField.Ok())
+	}
+	ret.Set("fields", fields)
+	applyExtensions(po, ret, true)
+	return rusty.Ok[any](ret)
+}
+
+func (g *avroGenerator) toField(pi eg.PropertyItem) rusty.Result[eg.JSONDict] {
+	field := eg.NewJSONDict()
+	name := avroName(pi.Name())
+	field.Set("name", name)
+	if name != pi.Name() {
+		field.Set(JSON_NAME, pi.Name())
+	}
+	if pi.Property().Description().IsSome() {
+		field.Set("doc", pi.Property().Description().Value())
+	}
+	rTyp := g.toAvroType(pi.Property(), pi.Name())
+	if rTyp.IsErr() {
+		return rusty.Err[eg.JSONDict](rTyp.Err())
+	}
+	rDef := defaultValue(pi.Property())
+	if rDef.IsErr() {
+		return rusty.Err[eg.JSONDict](fmt.Errorf("field %s: %w", pi.Name(), rDef.Err()))
+	}
+	def := rDef.Ok()
+	switch {
+	case pi.Optional() && def.IsSome():
+		// a union default has to match the first branch
+		field.Set("type", []any{rTyp.Ok(), NULL})
+		field.Set("default", def.Value())
+	case pi.Optional():
+		field.Set("type", []any{NULL, rTyp.Ok()})
+		field.Set("default", nil)
+	default:
+		field.Set("type", rTyp.Ok())
+		if def.IsSome() {
+			field.Set("default", def.Value())
+		}
+	}
+	applyExtensions(pi.Property(), field, false)
+	return rusty.Ok(field)
+}
+
+func defaultValue(prop eg.Property) rusty.Result[rusty.Optional[any]] {
+	switch prop.Type() {
+	case eg.STRING:
+		ps := prop.(eg.PropertyString)
+		if ps.Default().IsNone() {
+			break
+		}
+		if ps.Format().IsSome() && ps.Format().Value() == eg.DATE_TIME {
+			t, err := time.Parse(time.RFC3339, ps.Default().Value())
+			if err != nil {
+				return rusty.Err[rusty.Optional[any]](err)
+			}
+			return rusty.Ok(rusty.Some[any](t.UnixMilli()))
+		}
+		return rusty.Ok(rusty.Some[any](ps.Default().Value()))
+	case eg.INTEGER:
+		if d := prop.(eg.PropertyInteger).Default(); d.IsSome() {
+			return rusty.Ok(rusty.Some[any](d.Value()))
+		}
+	case eg.NUMBER:
+		if d := prop.(eg.PropertyNumber).Default(); d.IsSome() {
+			return rusty.Ok(rusty.Some[any](d.Value()))
+		}
+	case eg.BOOLEAN:
+		if d := prop.(eg.PropertyBoolean).Default(); d.IsSome() {
+			return rusty.Ok(rusty.Some[any](d.Value()))
+		}
+	}
+	return rusty.Ok(rusty.None[any]())
+}
+
+// ToAvroSchema converts a PropertyObject tree into an avro record schema.
+// Nested named types are defined inline on first use and referenced by
+// their fullname afterwards.
+func ToAvroSchema(prop eg.Property, namespace string) rusty.Result[eg.JSONDict] {
+	po, ok := prop.(eg.PropertyObject)
+	if !ok {
+		return rusty.Err[eg.JSONDict](fmt.Errorf("avro schema needs an object got %s", prop.Type()))
+	}
+	g := &avroGenerator{
+		namespace: namespace,
+		defined:   map[string]string{},
+	}
+	rec := g.toRecord(po, po.Id())
+	if rec.IsErr() {
+		return rusty.Err[eg.JSONDict](rec.Err())
+	}
+	return rusty.Ok(rec.Ok().(eg.JSONDict))
+}
+
+func getAvroFileName(prop eg.Property) string {
+	names := objectNames(prop, prop.Id())
+	return strings.ToLower(strings.Join(names, "$")) + ".avsc"
+}
+
+func AvroGenerator(cfg *eg.GeneratorConfig, prop eg.Property, sl eg.PropertyCtx) {
+	fname := filepath.Join(cfg.OutputDir, getAvroFileName(prop))
+	rClaim := sl.Registry.ClaimOutput(fname, eg.SchemaIdentity(prop))
+	if rClaim.IsErr() {
+		panic(rClaim.Err())
+	}
+	if !rClaim.Ok() {
+		return
+	}
+	rSchema := ToAvroSchema(prop, cfg.EntityCfg.PackageName)
+	if rSchema.IsErr() {
+		panic(rSchema.Err())
+	}
+	bytes, err := json.MarshalIndent(rSchema.Ok(), "", cfg.EntityCfg.Indent)
+	if err != nil {
+		panic(err)
+	}
+	cfg.Logf("Generate: %s -> %s\n", prop.Meta().FileName().Value(), fname)
+	err = cfg.WriteFile(fname, append(bytes, '\n'))
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,194 @@
+package avro
+
+import (
+	"encoding/json"
+	"testing"
+	"testing/fstest"
+
+	eg "github.com/mabels/wueste/entity-generator"
+	"github.com/stretchr/testify/assert"
+)
+
+func fieldByName(t *testing.T, rec eg.JSONDict, name string) eg.JSONDict {
+	for _, _f := range rec.Get("fields").([]any) {
+		f := _f.(eg.JSONDict)
+		if f.Get("name") == name {
+			return f
+		}
+	}
+	t.Fatalf("field not found: %s", name)
+	return nil
+}
+
+func TestAvroFlatSchema(t *testing.T) {
+	prop := eg.TestFlatSchema(eg.NewTestContext()).Ok()
+	rec := ToAvroSchema(prop, "wueste").Ok()
+	assert.Equal(t, RECORD, rec.Get("type"))
+	assert.Equal(t, "SimpleType", rec.Get("name"))
+	assert.Equal(t, "wueste", rec.Get("namespace"))
+	assert.Equal(t, "Jojo SimpleType", rec.Get("doc"))
+
+	str := fieldByName(t, rec, "string")
+	assert.Equal(t, STRING, str.Get("type"))
+	_, found := str.Lookup("default")
+	assert.False(t, found)
+
+	opt := fieldByName(t, rec, "optional_string")
+	assert.Equal(t, "optional-string", opt.Get(JSON_NAME))
+	assert.Equal(t, []any{NULL, STRING}, opt.Get("type"))
+	def, found := opt.Lookup("default")
+	assert.True(t, found)
+	assert.Nil(t, def)
+
+	optDef := fieldByName(t, rec, "optional_default_string")
+	assert.Equal(t, []any{STRING, NULL}, optDef.Get("type"))
+	assert.Equal(t, "hallo", optDef.Get("default"))
+
+	createdAt := fieldByName(t, rec, "default_createdAt").Get("type").(eg.JSONDict)
+	assert.Equal(t, LONG, createdAt.Get("type"))
+	assert.Equal(t, TIMESTAMP_MILLIS, createdAt.Get("logicalType"))
+	assert.Equal(t, int64(1704067199000), fieldByName(t, rec, "default_createdAt").Get("default"))
+
+	assert.Equal(t, INT, fieldByName(t, rec, "optional_int32").Get("type").([]any)[1])
+	assert.Equal(t, FLOAT, fieldByName(t, rec, "optional_float32").Get("type").([]any)[1])
+
+	sub := fieldByName(t, rec, "sub").Get("type").(eg.JSONDict)
+	assert.Equal(t, "IPayload", sub.Get("name"))
+	assert.Equal(t, "wueste.simpletype", sub.Get("namespace"))
+	// the second usage references the already defined record
+	assert.Equal(t, []any{NULL, "wueste.simpletype.IPayload"}, fieldByName(t, rec, "opt_sub").Get("type"))
+}
+
+func TestAvroAnonymousNested(t *testing.T) {
+	js := eg.NewJSONDict()
+	err := json.Unmarshal([]byte(`{
+		"$id": "https://Event",
+		"title": "Event",
+		"type": "object",
+		"properties": {
+			"collection": {
+				"$id": "https://Event/collection",
+				"type": "object",
+				"properties": {
+					"slug": { "type": "string" }
+				}
+			}
+		}
+	}`), js)
+	assert.NoError(t, err)
+	po := eg.NewPropertiesBuilder(eg.NewTestContext()).FromJson(js).Build().Ok()
+	rec := ToAvroSchema(po, "").Ok()
+	collection := fieldByName(t, rec, "collection").Get("type").([]any)[1].(eg.JSONDict)
+	assert.Equal(t, "collection", collection.Get("name"))
+	assert.Equal(t, "event", collection.Get("namespace"))
+}
+
+func TestAvroRecursive(t *testing.T) {
+	fsys := fstest.MapFS{
+		"tree.schema.json": {Data: []byte(`{
+	"$id": "https://Tree", "title": "Tree", "type": "object",
+	"properties": {
+		"children": { "type": "array", "items": { "$ref": "file://tree.schema.json" } }
+	},
+	"required": ["children"]
+}`)},
+	}
+	ctx := eg.PropertyCtx{Registry: eg.NewSchemaRegistry(eg.NewFSSchemaLoader(fsys))}
+	rec := ToAvroSchema(eg.LoadSchemaFile(ctx, "tree.schema.json").Ok(), "wueste").Ok()
+	children := fieldByName(t, rec, "children").Get("type").(eg.JSONDict)
+	// the recursion refers to the record by its fullname
+	assert.Equal(t, "wueste.Tree", children.Get("items"))
+}
+
+func TestAvroRoundTrip(t *testing.T) {
+	prop := eg.TestFlatSchema(eg.NewTestContext()).Ok()
+	rec := ToAvroSchema(prop, "wueste").Ok()
+	bytes, err := json.Marshal(rec)
+	assert.NoError(t, err)
+
+	rImported := FromAvroBytes(eg.NewTestContext(), bytes)
+	assert.True(t, rImported.IsOk())
+	imported := rImported.Ok().(eg.PropertyObject)
+	assert.Equal(t, "wueste.SimpleType", imported.Id())
+	assert.Equal(t, "SimpleType", imported.Title())
+
+	orig := prop.(eg.PropertyObject)
+	assert.Equal(t, len(orig.Items()), len(imported.Items()))
+	for _, pi := range orig.Items() {
+		ipi := imported.PropertyByName(pi.Name())
+		assert.True(t, ipi.IsOk(), pi.Name())
+		assert.Equal(t, pi.Optional(), ipi.Ok().Optional(), pi.Name())
+		assert.Equal(t, pi.Property().Type(), ipi.Ok().Property().Type(), pi.Name())
+	}
+	createdAt := imported.PropertyByName("default-createdAt").Ok().Property().(eg.PropertyString)
+	assert.Equal(t, eg.DATE_TIME, createdAt.Format().Value())
+	assert.Equal(t, "2023-12-31T23:59:59Z", createdAt.Default().Value())
+}
+
+func TestAvroExtensionHooks(t *testing.T) {
+	fsys := fstest.MapFS{
+		"user.schema.json": {Data: []byte(`{
+	"$id": "https://User", "title": "User", "type": "object", "x-sensitive": true,
+	"properties": {
+		"password": { "type": "string", "x-sensitive": true }
+	},
+	"required": ["password"]
+}`)},
+	}
+	ctx := eg.PropertyCtx{Registry: eg.NewSchemaRegistry(eg.NewFSSchemaLoader(fsys))}
+	assert.NoError(t, ctx.Registry.Extensions.Register(eg.Extension{
+		Name: "x-sensitive",
+		Hooks: map[string]any{
+			"avro": ExtensionHook{
+				Field: func(field eg.JSONDict, value any) {
+					field.Set("sensitive", value)
+				},
+				Record: func(record eg.JSONDict, value any) {
+					record.Set("doc", "contains sensitive fields")
+				},
+			},
+		},
+	}))
+	rec := ToAvroSchema(eg.LoadSchemaFile(ctx, "user.schema.json").Ok(), "wueste").Ok()
+	assert.Equal(t, "contains sensitive fields", rec.Get("doc"))
+	assert.Equal(t, true, fieldByName(t, rec, "password").Get("sensitive"))
+}
+
+func TestAvroImportRecursive(t *testing.T) {
+	rProp := FromAvroBytes(eg.NewTestContext(), []byte(`{"type":"record","name":"Node","fields":[{"name":"v","type":"int"},{"name":"next","type":["null","Node"],"default":null}]}`))
+	assert.True(t, rProp.IsErr())
+	assert.Contains(t, rProp.Err().Error(), "avro recursive record Node is not supported")
+}
+
+func TestAvroImportEnum(t *testing.T) {
+	rProp := FromAvroBytes(eg.NewTestContext(), []byte(`{"type":"record","name":"Light","fields":[
+		{"name":"color","type":{"type":"enum","name":"Color","symbols":["RED","GREEN"]},"default":"RED"},
+		{"name":"fallback","type":"Color"}
+	]}`))
+	assert.True(t, rProp.IsOk())
+	light := rProp.Ok().(eg.PropertyObject)
+	color := light.PropertyByName("color").Ok().Property().(eg.PropertyString)
+	assert.Equal(t, []string{"RED", "GREEN"}, color.Enum())
+	assert.Equal(t, "RED", color.Default().Value())
+	fallback := light.PropertyByName("fallback").Ok().Property().(eg.PropertyString)
+	assert.Equal(t, []string{"RED", "GREEN"}, fallback.Enum())
+	assert.True(t, fallback.Default().IsNone())
+}
+
+func TestAvroRecordCollision(t *testing.T) {
+	js := eg.NewJSONDict()
+	err := json.Unmarshal([]byte(`{
+		"$id": "https://Order", "title": "Order", "type": "object",
+		"properties": {
+			"bill": { "$id": "https://a/address", "title": "Address", "type": "object",
+				"properties": { "street": { "type": "string" } } },
+			"ship": { "$id": "https://b/address", "title": "Address", "type": "object",
+				"properties": { "zip": { "type": "string" } } }
+		}
+	}`), js)
+	assert.NoError(t, err)
+	po := eg.NewPropertiesBuilder(eg.NewTestContext()).FromJson(js).Build().Ok()
+	rec := ToAvroSchema(po, "")
+	assert.True(t, rec.IsErr())
+	assert.Contains(t, rec.Err().Error(), "avro record order.Address is defined by https://a/address and https://b/address")
+}
@@ -0,0 +1,294 @@
+package avro
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	eg "github.com/mabels/wueste/entity-generator"
+	"github.com/mabels/wueste/entity-generator/rusty"
+)
+
+type avroImporter struct {
+	// named types by fullname, converted to json schema
+	named map[string]eg.JSONDict
+	// fullnames of the records whose fields are being converted
+	building map[string]bool
+}
+
+func lookupString(js eg.JSONDict, key string) string {
+	v, found := js.Lookup(key)
+	if !found {
+		return ""
+	}
+	s, ok := v.(string)
+	if !ok {
+		return ""
+	}
+	return s
+}
+
+func isNullable(union []any) (any, bool) {
+	var typ any
+	nullable := false
+	others := 0
+	for _, u := range union {
+		if u == NULL {
+			nullable = true
+			continue
+		}
+		typ = u
+		others++
+	}
+	if others != 1 {
+		return nil, false
+	}
+	return typ, nullable
+}
+
+func (imp *avroImporter) toJsonSchema(avsc any, namespace string) rusty.Result[eg.JSONDict] {
+	switch typ := avsc.(type) {
+	case string:
+		js := eg.NewJSONDict()
+		switch typ {
+		case BOOLEAN:
+			js.Set("type", eg.BOOLEAN)
+		case INT:
+			js.Set("type", eg.INTEGER)
+			js.Set("format", "int32")
+		case LONG:
+			js.Set("type", eg.INTEGER)
+			js.Set("format", "int64")
+		case FLOAT:
+			js.Set("type", eg.NUMBER)
+			js.Set("format", "float32")
+		case DOUBLE:
+			js.Set("type", eg.NUMBER)
+		case STRING:
+			js.Set("type", eg.STRING)
+		default:
+			named, found := imp.named[typ]
+			if !found {
+				named, found = imp.named[fullName(typ, namespace)]
+			}
+			if !found {
+				return rusty.Err[eg.JSONDict](fmt.Errorf("avro unknown type: %s", typ))
+			}
+			if imp.building[lookupString(named, "$id")] {
+				// json schema has no local refs, the record would contain itself
+				return rusty.Err[eg.JSONDict](fmt.Errorf("avro recursive record %s is not supported", typ))
+			}
+			if lookupString(named, "type") != eg.OBJECT {
+				// enums get the field attributes, so every use gets its own copy
+				for _, k := range named.Keys() {
+					js.Set(k, named.Get(k))
+				}
+				return rusty.Ok(js)
+			}
+			return rusty.Ok(named)
+		}
+		return rusty.Ok(js)
+	case []any:
+		inner, nullable := isNullable(typ)
+		if !nullable {
+			return rusty.Err[eg.JSONDict](fmt.Errorf("avro only [\"null\", T] unions are supported: %v", typ))
+		}
+		return imp.toJsonSchema(inner, namespace)
+	default:
+		js, ok := eg.AsJSONDict(avsc)
+		if !ok {
+			return rusty.Err[eg.JSONDict](fmt.Errorf("avro unknown schema: %v", avsc))
+		}
+		return imp.complexToJsonSchema(js, namespace)
+	}
+}
+
+func (imp *avroImporter) complexToJsonSchema(avsc eg.JSONDict, namespace string) rusty.Result[eg.JSONDict] {
+	typ, found := avsc.Lookup("type")
+	if !found {
+		return rusty.Err[eg.JSONDict](fmt.Errorf("avro schema without type"))
+	}
+	js := eg.NewJSONDict()
+	switch lookupString(avsc, "logicalType") {
+	case TIMESTAMP_MILLIS:
+		js.Set("type", eg.STRING)
+		js.Set("format", eg.DATE_TIME)
+		return rusty.Ok(js)
+	case DATE:
+		js.Set("type", eg.STRING)
+		js.Set("format", eg.DATE)
+		return rusty.Ok(js)
+	case TIME_MILLIS:
+		js.Set("type", eg.STRING)
+		js.Set("format", eg.TIME)
+		return rusty.Ok(js)
+	}
+	switch typ {
+	case RECORD:
+		return imp.recordToJsonSchema(avsc, namespace)
+	case ARRAY:
+		items, found := avsc.Lookup("items")
+		if !found {
+			return rusty.Err[eg.JSONDict](fmt.Errorf("avro array without items"))
+		}
+		rItems := imp.toJsonSchema(items, namespace)
+		if rItems.IsErr() {
+			return rItems
+		}
+		js.Set("type", eg.ARRAY)
+		js.Set("items", rItems.Ok())
+		return rusty.Ok(js)
+	case MAP:
+		js.Set("type", eg.OBJECT)
+		return rusty.Ok(js)
+	case ENUM:
+		return imp.enumToJsonSchema(avsc, namespace)
+	default:
+		return imp.toJsonSchema(typ, namespace)
+	}
+}
+
+func (imp *avroImporter) recordToJsonSchema(avsc eg.JSONDict, namespace string) rusty.Result[eg.JSONDict] {
+	name := lookupString(avsc, "name")
+	if name == "" {
+		return rusty.Err[eg.JSONDict](fmt.Errorf("avro record without name"))
+	}
+	if ns := lookupString(avsc, "namespace"); ns != "" {
+		namespace = ns
+	}
+	js := eg.NewJSONDict()
+	js.Set("$id", fullName(name, namespace))
+	js.Set("title", name)
+	js.Set("type", eg.OBJECT)
+	if doc := lookupString(avsc, "doc"); doc != "" {
+		js.Set("description", doc)
+	}
+	// registered before the fields to report references to the record itself
+	imp.named[fullName(name, namespace)] = js
+	imp.building[fullName(name, namespace)] = true
+	defer delete(imp.building, fullName(name, namespace))
+	_fields, _ := avsc.Lookup("fields")
+	fields, ok := _fields.([]any)
+	if !ok {
+		return rusty.Err[eg.JSONDict](fmt.Errorf("avro record %s fields is not an array", name))
+	}
+	props := eg.NewJSONDict()
+	required := []any{}
+	for _, _field := range fields {
+		field, ok := eg.AsJSONDict(_field)
+		if !ok {
+			return rusty.Err[eg.JSONDict](fmt.Errorf("avro record %s field is not an object", name))
+		}
+		fieldName := lookupString(field, JSON_NAME)
+		if fieldName == "" {
+			fieldName = lookupString(field, "name")
+		}
+		ftyp, _ := field.Lookup("type")
+		rProp := imp.toJsonSchema(ftyp, namespace)
+		if rProp.IsErr() {
+			return rusty.Err[eg.JSONDict](fmt.Errorf("avro record %s field %s: %w", name, fieldName, rProp.Err()))
+		}
+		prop := rProp.Ok()
+		if lookupString(prop, "type") != eg.OBJECT {
+			// records are shared between references, so only scalars and arrays get the field attributes
+			if doc := lookupString(field, "doc"); doc != "" {
+				prop.Set("description", doc)
+			}
+			if def, found := field.Lookup("default"); found && def != nil {
+				prop.Set("default", importDefault(prop, def))
+			}
+		}
+		props.Set(fieldName, prop)
+		if _, nullable := isNullable(asUnion(ftyp)); !nullable {
+			required = append(required, fieldName)
+		}
+	}
+	if props.Len() > 0 {
+		js.Set("properties", props)
+	}
+	if len(required) > 0 {
+		js.Set("required", required)
+	}
+	return rusty.Ok(js)
+}
+
+func (imp *avroImporter) enumToJsonSchema(avsc eg.JSONDict, namespace string) rusty.Result[eg.JSONDict] {
+	name := lookupString(avsc, "name")
+	if name == "" {
+		return rusty.Err[eg.JSONDict](fmt.Errorf("avro enum without name"))
+	}
+	if ns := lookupString(avsc, "namespace"); ns != "" {
+		namespace = ns
+	}
+	_symbols, _ := avsc.Lookup("symbols")
+	symbols, ok := _symbols.([]any)
+	if !ok {
+		return rusty.Err[eg.JSONDict](fmt.Errorf("avro enum %s symbols is not an array", name))
+	}
+	js := eg.NewJSONDict()
+	js.Set("type", eg.STRING)
+	js.Set("enum", symbols)
+	if doc := lookupString(avsc, "doc"); doc != "" {
+		js.Set("description", doc)
+	}
+	imp.named[fullName(name, namespace)] = js
+	return imp.toJsonSchema(fullName(name, namespace), namespace)
+}
+
+func asUnion(typ any) []any {
+	union, ok := typ.([]any)
+	if !ok {
+		return []any{typ}
+	}
+	return union
+}
+
+func importDefault(prop eg.JSONDict, def any) any {
+	if lookupString(prop, "format") == eg.DATE_TIME {
+		if millis, ok := def.(float64); ok {
+			return time.UnixMilli(int64(millis)).UTC().Format(time.RFC3339)
+		}
+	}
+	return def
+}
+
+// FromAvro converts an avro schema (.avsc) into a json schema which is
+// then loaded by the PropertiesBuilder.
+func FromAvro(ctx eg.PropertyCtx, avsc eg.JSONDict) rusty.Result[eg.Property] {
+	imp := &avroImporter{
+		named:    map[string]eg.JSONDict{},
+		building: map[string]bool{},
+	}
+	rJs := imp.toJsonSchema(avsc, "")
+	if rJs.IsErr() {
+		return rusty.Err[eg.Property](rJs.Err())
+	}
+	return eg.NewPropertiesBuilder(ctx).FromJson(rJs.Ok()).Build()
+}
+
+func FromAvroBytes(ctx eg.PropertyCtx, bytes []byte) rusty.Result[eg.Property] {
+	avsc := eg.NewJSONDict()
+	err := json.Unmarshal(bytes, avsc)
+	if err != nil {
+		return rusty.Err[eg.Property](fmt.Errorf("error parsing avro schema: %w", err))
+	}
+	return FromAvro(ctx, avsc)
+}
+
+func FromAvroFile(ctx eg.PropertyCtx, fname string) rusty.Result[eg.Property] {
+	bytes, err := os.ReadFile(fname)
+	if err != nil {
+		return rusty.Err[eg.Property](err)
+	}
+	rProp := FromAvroBytes(ctx, bytes)
+	if rProp.IsOk() {
+		absFname, err := filepath.Abs(fname)
+		if err != nil {
+			absFname = fname
+		}
+		rProp.Ok().Meta().SetFileName(absFname)
+	}
+	return rProp
+}
@@ -0,0 +1,119 @@
+package entity_generator
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Words splits name at the non alpha numeric characters and at the
+// changes from lower to upper case: "HTTPServer-name" is HTTP Server name.
+func Words(name string) []string {
+	out := []string{}
+	runes := []rune(name)
+	start := -1
+	flush := func(end int) {
+		if start >= 0 {
+			out = append(out, string(runes[start:end]))
+		}
+		start = -1
+	}
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush(i)
+			continue
+		}
+		if start >= 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				flush(i)
+			}
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	flush(len(runes))
+	return out
+}
+
+func capitalize(word string) string {
+	runes := []rune(strings.ToLower(word))
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+// PascalCase joins the capitalized words of name.
+func PascalCase(name string) string {
+	out := ""
+	for _, w := range Words(name) {
+		out += capitalize(w)
+	}
+	return out
+}
+
+// CamelCase is the PascalCase with a lower case first word.
+func CamelCase(name string) string {
+	ws := Words(name)
+	if len(ws) == 0 {
+		return ""
+	}
+	out := strings.ToLower(ws[0])
+	for _, w := range ws[1:] {
+		out += capitalize(w)
+	}
+	return out
+}
+
+// joinWords joins the lower case words of name with sep. The $ which
+// separates the nested entities is kept, A$B and A-B are different files.
+func joinWords(name, sep string) string {
+	parts := strings.Split(name, "$")
+	for i, part := range parts {
+		ws := Words(part)
+		for j, w := range ws {
+			ws[j] = strings.ToLower(w)
+		}
+		parts[i] = strings.Join(ws, sep)
+	}
+	return strings.Join(parts, "$")
+}
+
+// SnakeCase joins the lower case words of name with _, the $ of nested
+// entities is kept.
+func SnakeCase(name string) string {
+	return joinWords(name, "_")
+}
+
+// KebabCase joins the lower case words of name with -, the $ of nested
+// entities is kept.
+func KebabCase(name string) string {
+	return joinWords(name, "-")
+}
+
+var reNonIdentifier = regexp.MustCompile("[^a-zA-Z0-9_$]+")
+
+// Identifier replaces the characters of name which are not allowed in an
+// identifier with _, the leading _ are removed.
+func Identifier(name string) string {
+	return strings.TrimLeft(reNonIdentifier.ReplaceAllString(name, "_"), "_")
+}
+
+// PayloadNames returns the title, the names of the payloads and the varname
+// of the entity prop, the generators name the entities alike.
+func PayloadNames(prop PropertyObject) (string, []string, string) {
+	title := prop.Title()
+	if title == "" {
+		title = prop.Id()
+	}
+	names := []string{prop.Id()}
+	if prop.Id() != prop.Title() {
+		names = append(names, prop.Title())
+	}
+	varname := Identifier(title)
+	if varname != prop.Id() && varname != title {
+		names = append(names, varname)
+	}
+	return title, names, varname
+}
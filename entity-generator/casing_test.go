@@ -0,0 +1,27 @@
+package entity_generator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWords(t *testing.T) {
+	assert.Equal(t, []string{"HTTP", "Server", "name"}, Words("HTTPServer-name"))
+	assert.Equal(t, []string{"simple", "Type", "2"}, Words("simple_Type 2"))
+	assert.Equal(t, []string{"v2", "Api"}, Words("v2Api"))
+	assert.Equal(t, []string{"Simple", "Type", "I", "Payload"}, Words("SimpleType$IPayload"))
+	assert.Equal(t, []string{}, Words("--"))
+}
+
+func TestCasing(t *testing.T) {
+	assert.Equal(t, "HttpServerName", PascalCase("HTTPServer-name"))
+	assert.Equal(t, "httpServerName", CamelCase("HTTPServer-name"))
+	assert.Equal(t, "", CamelCase(""))
+	assert.Equal(t, "http_server_name", SnakeCase("HTTPServer-name"))
+	assert.Equal(t, "simple-type$i-payload", KebabCase("SimpleType$IPayload"))
+	assert.Equal(t, "simple_type$i_payload", SnakeCase("SimpleType$IPayload"))
+	// the nesting separator does not collide with the word separators
+	assert.NotEqual(t, KebabCase("A$B"), KebabCase("A-B"))
+	assert.NotEqual(t, SnakeCase("A$B"), SnakeCase("A_B"))
+}
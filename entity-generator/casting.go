@@ -122,7 +122,7 @@ func coerceString(v interface{}) rusty.Optional[string] {
 		case int, int8, int16, int32, int64, float32, float64:
 			return rusty.Some[string](fmt.Sprintf("%v", v))
 		default:
-			panic(fmt.Errorf("unknown type %T", v))
+			return rusty.None[string]()
 		}
 	}
 	return rusty.None[string]()
@@ -147,7 +147,7 @@ func coerceBool(v interface{}) rusty.Optional[bool] {
 			}
 			return rusty.Some[bool](val.Value() != 0)
 		default:
-			panic(fmt.Errorf("unknown type %T", v))
+			return rusty.None[bool]()
 		}
 
 	}
@@ -178,7 +178,7 @@ func coerceNumber[T int | int8 | int16 | int32 | int64 | float32 | float64](v in
 		case float64:
 			return rusty.Some[T](T(v.(float64)))
 		default:
-			panic(fmt.Errorf("unknown type %T", v))
+			return rusty.None[T]()
 		}
 	}
 	return rusty.None[T]()
@@ -207,8 +207,8 @@ func coerceFloat64(v interface{}) rusty.Optional[float64] {
 
 func getFromAttributeString(js JSONDict, attr string) string {
 	defVal, found := js.Lookup(attr)
-	if found {
-		return coerceString(defVal).Value()
+	if str := coerceString(defVal); found && str.IsSome() {
+		return str.Value()
 	}
 	return ""
 	// panic("no " + attr + " found")
@@ -243,6 +243,32 @@ func getFromAttributeOptionalString(js JSONDict, attr string) rusty.Optional[str
 	return format
 }
 
+// getFromAttributeArray returns the values of the array attr, it is nil if
+// attr is missing or not an array.
+func getFromAttributeArray(js JSONDict, attr string) []interface{} {
+	val, found := js.Lookup(attr)
+	if !found {
+		return nil
+	}
+	arr, ok := val.([]interface{})
+	if !ok {
+		return nil
+	}
+	return arr
+}
+
+// getFromAttributeEnum returns the values of the enum array attr which
+// coerce to T, it is nil if attr is missing.
+func getFromAttributeEnum[T any](js JSONDict, attr string, coerce func(v interface{}) rusty.Optional[T]) []T {
+	var values []T
+	for _, v := range getFromAttributeArray(js, attr) {
+		if val := coerce(v); val.IsSome() {
+			values = append(values, val.Value())
+		}
+	}
+	return values
+}
+
 func getFromAttributeOptionalBoolean(js JSONDict, attr string) rusty.Optional[bool] {
 	format := rusty.None[bool]()
 	formatVal, found := js.Lookup(attr)
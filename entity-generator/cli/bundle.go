@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	eg "github.com/mabels/wueste/entity-generator"
+)
+
+func init() {
+	registerCommand(command{
+		name:    "bundle",
+		args:    "schema...",
+		summary: "write the schemas with all $refs inlined",
+		run:     bundleAction,
+	})
+}
+
+func bundleAction(cmd command, args []string, vi versionInfo) int {
+	var includeDirs []string
+	var outputDir string
+	var indent string
+	fs := newFlagSet(cmd)
+	fs.StringArrayVar(&includeDirs, "include-dir", []string{}, "include directories")
+	fs.StringVar(&outputDir, "output-dir", "", "output directory, stdout if not set")
+	fs.StringVar(&indent, "indent", "  ", "one indent level")
+	df := addDiagnosticsFlags(fs)
+	if exit := parseFlags(fs, args); exit.IsSome() {
+		return exit.Value()
+	}
+	if !df.valid() {
+		return ExitUsage
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return ExitUsage
+	}
+	if outputDir != "" {
+		err := os.MkdirAll(outputDir, 0755)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return ExitFailure
+		}
+	}
+	sl := eg.PropertyCtx{
+		Registry: eg.NewSchemaRegistry(eg.NewSchemaLoaderImpl(includeDirs...)),
+	}
+	for _, file := range fs.Args() {
+		schema := eg.LoadSchemaFile(sl, file)
+		if schema.IsErr() {
+			df.report(locateDiagnostics(eg.AsDiagnostics(schema.Err())))
+			return ExitFailure
+		}
+		bytes, err := json.MarshalIndent(eg.PropertyToJson(schema.Ok()), "", indent)
+		if err != nil {
+			fmt.Fprintf(stderr, "File:%s with %v\n", file, err)
+			return ExitFailure
+		}
+		bytes = append(bytes, '\n')
+		if outputDir == "" {
+			stdout.Write(bytes)
+			continue
+		}
+		fname := filepath.Join(outputDir, filepath.Base(file))
+		err = os.WriteFile(fname, bytes, 0644)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return ExitFailure
+		}
+		fmt.Fprintf(stdout, "Bundle: %s -> %s\n", file, fname)
+	}
+	return ExitOk
+}
@@ -0,0 +1,220 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/pflag"
+
+	eg "github.com/mabels/wueste/entity-generator"
+)
+
+var diagnosticsFormats = []string{"text", "json", "sarif"}
+
+type diagnosticsFlags struct {
+	format string
+	output string
+}
+
+func addDiagnosticsFlags(fs *pflag.FlagSet) *diagnosticsFlags {
+	df := &diagnosticsFlags{}
+	fs.StringVar(&df.format, "diagnostics-format", "text", "format of the schema diagnostics: text, json or sarif")
+	fs.StringVar(&df.output, "diagnostics-output", "", "write the diagnostics to this file")
+	return df
+}
+
+func (df *diagnosticsFlags) valid() bool {
+	for _, f := range diagnosticsFormats {
+		if f == df.format {
+			return true
+		}
+	}
+	fmt.Fprintf(stderr, "unknown diagnostics format: %s (supported: %v)\n", df.format, diagnosticsFormats)
+	return false
+}
+
+// write calls fn with the diagnostics output file or with w if there is
+// none, errors are printed to stderr.
+func (df *diagnosticsFlags) write(w io.Writer, fn func(w io.Writer) error) bool {
+	if df.output != "" {
+		f, err := os.Create(df.output)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return false
+		}
+		defer f.Close()
+		w = f
+	}
+	err := fn(w)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return false
+	}
+	return true
+}
+
+// report writes the diagnostics to stderr, json and sarif are also written
+// if there are no diagnostics.
+func (df *diagnosticsFlags) report(ds eg.Diagnostics) {
+	if len(ds) == 0 && df.format == "text" {
+		return
+	}
+	df.write(stderr, func(w io.Writer) error {
+		return writeDiagnostics(w, df.format, ds)
+	})
+}
+
+// locateDiagnostics adds line and column from the schema files.
+func locateDiagnostics(ds eg.Diagnostics) eg.Diagnostics {
+	return ds.Locate(os.ReadFile)
+}
+
+func writeDiagnostics(w io.Writer, format string, ds eg.Diagnostics) error {
+	switch format {
+	case "json":
+		if ds == nil {
+			ds = eg.Diagnostics{}
+		}
+		bytes, err := json.MarshalIndent(ds, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(bytes))
+		return err
+	case "sarif":
+		bytes, err := json.MarshalIndent(toSarif(ds), "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(bytes))
+		return err
+	default:
+		for _, d := range ds {
+			_, err := fmt.Fprintln(w, d.Error())
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	Id string `json:"id"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleId    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	Uri string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+}
+
+// sarifUri returns the file relative to the working directory, which is
+// usually the root of the repository the results are annotated to.
+func sarifUri(fname string) string {
+	if wd, err := os.Getwd(); err == nil && filepath.IsAbs(fname) {
+		if rel, err := filepath.Rel(wd, fname); err == nil && !strings.HasPrefix(rel, "..") {
+			fname = rel
+		}
+	}
+	return filepath.ToSlash(fname)
+}
+
+func toSarif(ds eg.Diagnostics) sarifLog {
+	rules := map[string]bool{}
+	results := []sarifResult{}
+	for _, d := range ds {
+		rules[d.Code] = true
+		result := sarifResult{
+			RuleId:  d.Code,
+			Level:   string(d.Severity),
+			Message: sarifMessage{Text: d.Message},
+		}
+		loc := sarifLocation{}
+		if d.File != "" {
+			loc.PhysicalLocation = &sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{Uri: sarifUri(d.File)},
+			}
+			if d.Line > 0 {
+				loc.PhysicalLocation.Region = &sarifRegion{StartLine: d.Line, StartColumn: d.Column}
+			}
+		}
+		if d.Pointer != "" {
+			loc.LogicalLocations = []sarifLogicalLocation{{FullyQualifiedName: d.Pointer}}
+		}
+		if loc.PhysicalLocation != nil || loc.LogicalLocations != nil {
+			result.Locations = []sarifLocation{loc}
+		}
+		results = append(results, result)
+	}
+	ruleIds := make([]string, 0, len(rules))
+	for id := range rules {
+		ruleIds = append(ruleIds, id)
+	}
+	sort.Strings(ruleIds)
+	sarifRules := make([]sarifRule, 0, len(ruleIds))
+	for _, id := range ruleIds {
+		sarifRules = append(sarifRules, sarifRule{Id: id})
+	}
+	return sarifLog{
+		Version: "2.1.0",
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Runs: []sarifRun{{
+			Tool:    sarifTool{Driver: sarifDriver{Name: "wueste-generator", Rules: sarifRules}},
+			Results: results,
+		}},
+	}
+}
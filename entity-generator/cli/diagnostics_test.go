@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	eg "github.com/mabels/wueste/entity-generator"
+)
+
+func TestGenerateDiagnostics(t *testing.T) {
+	_, errOut := captureOutput(t)
+	dir := t.TempDir()
+	schema := filepath.Join(dir, "base.schema.json")
+	assert.NoError(t, os.WriteFile(schema, []byte(`{
+	"$id": "https://Base", "title": "Base", "type": "object",
+	"properties": {
+		"sub": { "$ref": "file://missing.schema.json" }
+	}
+}`), 0644))
+	args := []string{"generate", "--output-dir", filepath.Join(dir, "out"), "--input-file", schema}
+
+	assert.Equal(t, ExitFailure, MainAction(args, "", ""))
+	assert.Contains(t, errOut.String(), schema+":4:12#/properties/sub/$ref: error[ref-not-found]: ")
+
+	sarifFile := filepath.Join(dir, "diagnostics.sarif")
+	assert.Equal(t, ExitFailure, MainAction(append(args, "--diagnostics-format", "sarif", "--diagnostics-output", sarifFile), "", ""))
+	bytes, err := os.ReadFile(sarifFile)
+	assert.NoError(t, err)
+	sarif := sarifLog{}
+	assert.NoError(t, json.Unmarshal(bytes, &sarif))
+	assert.Equal(t, "2.1.0", sarif.Version)
+	result := sarif.Runs[0].Results[0]
+	assert.Equal(t, eg.DiagRefNotFound, result.RuleId)
+	assert.Equal(t, "error", result.Level)
+	assert.Equal(t, filepath.ToSlash(schema), result.Locations[0].PhysicalLocation.ArtifactLocation.Uri)
+	assert.Equal(t, 4, result.Locations[0].PhysicalLocation.Region.StartLine)
+	assert.Equal(t, "/properties/sub/$ref", result.Locations[0].LogicalLocations[0].FullyQualifiedName)
+
+	errOut.Reset()
+	assert.Equal(t, ExitFailure, MainAction(append(args, "--diagnostics-format", "json"), "", ""))
+	ds := eg.Diagnostics{}
+	assert.NoError(t, json.Unmarshal(errOut.Bytes(), &ds))
+	assert.Equal(t, 1, len(ds))
+	assert.Equal(t, schema, ds[0].File)
+	assert.Equal(t, 12, ds[0].Column)
+
+	assert.Equal(t, ExitUsage, MainAction(append(args, "--diagnostics-format", "xml"), "", ""))
+}
+
+func TestGenerateMissingInput(t *testing.T) {
+	_, errOut := captureOutput(t)
+	dir := t.TempDir()
+	exit := MainAction([]string{"generate", "--output-dir", dir, "--input-file", filepath.Join(dir, "none.schema.json")}, "", "")
+	assert.Equal(t, ExitFailure, exit)
+	assert.Contains(t, errOut.String(), filepath.Join(dir, "none.schema.json")+": error[ref-not-found]")
+}
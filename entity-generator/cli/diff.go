@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	eg "github.com/mabels/wueste/entity-generator"
+)
+
+func init() {
+	registerCommand(command{
+		name:    "diff",
+		args:    "old-schema new-schema",
+		summary: "report breaking changes between two schema versions",
+		run:     diffAction,
+	})
+}
+
+func diffAction(cmd command, args []string, vi versionInfo) int {
+	var includeDirs []string
+	var side string
+	fs := newFlagSet(cmd)
+	fs.StringArrayVar(&includeDirs, "include-dir", []string{}, "include directories")
+	df := addDiagnosticsFlags(fs)
+	fs.StringVar(&side, "side", "both", "fail on breaking changes of: reader, writer or both")
+	if exit := parseFlags(fs, args); exit.IsSome() {
+		return exit.Value()
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return ExitUsage
+	}
+	if !df.valid() {
+		return ExitUsage
+	}
+	reader, writer := side == "reader" || side == "both", side == "writer" || side == "both"
+	if !reader && !writer {
+		fmt.Fprintf(stderr, "unknown side: %s\n", side)
+		return ExitUsage
+	}
+
+	schemas := []eg.Property{}
+	for _, file := range fs.Args() {
+		// each version gets its own registry, both could have the same files
+		sl := eg.PropertyCtx{
+			Registry: eg.NewSchemaRegistry(eg.NewSchemaLoaderImpl(includeDirs...)),
+		}
+		schema := eg.LoadSchemaFile(sl, file)
+		if schema.IsErr() {
+			df.report(locateDiagnostics(eg.AsDiagnostics(schema.Err())))
+			return ExitFailure
+		}
+		schemas = append(schemas, schema.Ok())
+	}
+
+	changes := eg.DiffSchemas(schemas[0], schemas[1])
+	exit := ExitOk
+	if eg.HasBreakingChanges(changes, reader, writer) {
+		exit = ExitFailure
+	}
+	// the changes are the output of diff, they go to stdout
+	if !df.write(stdout, func(w io.Writer) error {
+		return writeSchemaChanges(w, df.format, fs.Arg(0), changes, reader, writer)
+	}) {
+		return ExitFailure
+	}
+	return exit
+}
+
+// writeSchemaChanges writes the changes, as sarif they are diagnostics of
+// the old schema file which are errors if they break the checked side.
+func writeSchemaChanges(w io.Writer, format string, file string, changes []eg.SchemaChange, reader, writer bool) error {
+	switch format {
+	case "sarif":
+		ds := eg.Diagnostics{}
+		for _, c := range changes {
+			d := eg.NewDiagnostic(eg.DiagSchemaChange, "%s (reader: %s, writer: %s)", c.Message, c.Reader, c.Writer)
+			if !eg.HasBreakingChanges([]eg.SchemaChange{c}, reader, writer) {
+				d.Severity = eg.SeverityNote
+			}
+			d.File = file
+			d.Pointer = c.Path
+			ds = append(ds, d)
+		}
+		return writeDiagnostics(w, format, locateDiagnostics(ds))
+	case "json":
+		bytes, err := json.MarshalIndent(changes, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(bytes))
+		return err
+	default:
+		if len(changes) == 0 {
+			_, err := fmt.Fprintln(w, "no changes")
+			return err
+		}
+		fmt.Fprintf(w, "%-10s %-10s %s\n", "READER", "WRITER", "CHANGE")
+		for _, c := range changes {
+			fmt.Fprintf(w, "%-10s %-10s #%s: %s\n", c.Reader, c.Writer, c.Path, c.Message)
+		}
+		return nil
+	}
+}
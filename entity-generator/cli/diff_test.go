@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	eg "github.com/mabels/wueste/entity-generator"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	out, errOut := captureOutput(t)
+	dir := t.TempDir()
+	old := filepath.Join(dir, "old.schema.json")
+	assert.NoError(t, os.WriteFile(old, []byte(`{
+		"$id": "https://Base", "title": "Base", "type": "object",
+		"properties": { "name": { "type": "string" }, "legacy": { "type": "string" } },
+		"required": ["name", "legacy"]
+	}`), 0644))
+	removed := filepath.Join(dir, "removed.schema.json")
+	assert.NoError(t, os.WriteFile(removed, []byte(`{
+		"$id": "https://Base", "title": "Base", "type": "object",
+		"properties": { "name": { "type": "string" } },
+		"required": ["name"]
+	}`), 0644))
+
+	assert.Equal(t, ExitOk, MainAction([]string{"diff", old, old}, "", ""), errOut.String())
+	assert.Equal(t, "no changes\n", out.String())
+
+	out.Reset()
+	assert.Equal(t, ExitOk, MainAction([]string{"diff", "--side", "reader", old, removed}, "", ""), errOut.String())
+	assert.Equal(t, "READER     WRITER     CHANGE\n"+
+		"compatible breaking   #/properties/legacy: required property legacy removed\n", out.String())
+
+	out.Reset()
+	assert.Equal(t, ExitFailure, MainAction([]string{"diff", "--side", "writer", old, removed}, "", ""))
+	assert.Equal(t, ExitFailure, MainAction([]string{"diff", old, removed}, "", ""))
+
+	out.Reset()
+	assert.Equal(t, ExitOk, MainAction([]string{"diff", "--diagnostics-format", "json", "--side", "writer", removed, old}, "", ""))
+	changes := []eg.SchemaChange{}
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &changes))
+	assert.Equal(t, []eg.SchemaChange{{
+		Path: "/properties/legacy", Message: "required property legacy added",
+		Reader: eg.BREAKING, Writer: eg.COMPATIBLE,
+	}}, changes)
+
+	sarifFile := filepath.Join(dir, "diff.sarif")
+	assert.Equal(t, ExitOk, MainAction([]string{"diff", "--diagnostics-format", "sarif", "--diagnostics-output", sarifFile, "--side", "reader", old, removed}, "", ""))
+	bytes, err := os.ReadFile(sarifFile)
+	assert.NoError(t, err)
+	sarif := sarifLog{}
+	assert.NoError(t, json.Unmarshal(bytes, &sarif))
+	assert.Equal(t, 1, len(sarif.Runs[0].Results))
+	assert.Equal(t, eg.DiagSchemaChange, sarif.Runs[0].Results[0].RuleId)
+	assert.Equal(t, "note", sarif.Runs[0].Results[0].Level)
+	assert.Equal(t, sarifUri(old), sarif.Runs[0].Results[0].Locations[0].PhysicalLocation.ArtifactLocation.Uri)
+	assert.Equal(t, 3, sarif.Runs[0].Results[0].Locations[0].PhysicalLocation.Region.StartLine)
+
+	assert.Equal(t, ExitUsage, MainAction([]string{"diff", old}, "", ""))
+	assert.Equal(t, ExitUsage, MainAction([]string{"diff", "--side", "none", old, removed}, "", ""))
+	assert.Equal(t, ExitUsage, MainAction([]string{"diff", "--diagnostics-format", "xml", old, removed}, "", ""))
+}
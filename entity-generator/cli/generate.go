@@ -0,0 +1,326 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	eg "github.com/mabels/wueste/entity-generator"
+	"github.com/mabels/wueste/entity-generator/avro"
+	"github.com/mabels/wueste/entity-generator/plugin"
+	"github.com/mabels/wueste/entity-generator/rusty"
+	"github.com/mabels/wueste/entity-generator/template"
+	"github.com/mabels/wueste/entity-generator/ts"
+)
+
+type generatorFn func(cfg *eg.GeneratorConfig, prop eg.Property, sl eg.PropertyCtx)
+
+var generators = map[string]generatorFn{
+	"ts":       ts.TsGenerator,
+	"avro":     avro.AvroGenerator,
+	"template": template.TemplateGenerator,
+}
+
+// projectGeneratorFn runs once for all input schemas of a target after the
+// generator of each schema.
+type projectGeneratorFn func(cfg *eg.GeneratorConfig, props []eg.Property, sl eg.PropertyCtx)
+
+var projectGenerators = map[string]projectGeneratorFn{
+	"ts":       ts.TsProjectGenerator,
+	"template": template.ProjectGenerator,
+}
+
+// targetGenerators prepare the generators of a language once per target,
+// e.g. the templates are parsed once instead of once per schema.
+var targetGenerators = map[string]func(cfg *eg.GeneratorConfig) rusty.Result[generatorPair]{
+	"template": func(cfg *eg.GeneratorConfig) rusty.Result[generatorPair] {
+		rGenerators := template.NewGenerators(cfg)
+		if rGenerators.IsErr() {
+			return rusty.Err[generatorPair](rGenerators.Err())
+		}
+		g := rGenerators.Ok()
+		return rusty.Ok(generatorPair{generator: g.Entity, project: g.Project})
+	},
+}
+
+type generatorPair struct {
+	generator generatorFn
+	project   projectGeneratorFn
+}
+
+func languages() []string {
+	langs := make([]string, 0, len(generators))
+	for lang := range generators {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+func init() {
+	registerCommand(command{
+		name:    "generate",
+		args:    "[--input-file] schema...",
+		summary: "generate entities from json schemas",
+		run:     generateAction,
+	})
+}
+
+// generateDiagnostics are the diagnostics of the panic r of a generator,
+// the other panics are reported as DiagGenerateFailed.
+func generateDiagnostics(r any) eg.Diagnostics {
+	switch v := r.(type) {
+	case eg.Diagnostic:
+		return eg.Diagnostics{v}
+	case eg.Diagnostics:
+		return v
+	}
+	return eg.Diagnostics{eg.NewDiagnostic(eg.DiagGenerateFailed, "generate failed: %v", r)}
+}
+
+// generateFile loads a schema and runs the generator on it, the generators
+// panic on unsupported schemas which is reported as error.
+func generateFile(cfg *eg.GeneratorConfig, generator generatorFn, sl eg.PropertyCtx, file string) (ret rusty.Result[eg.Property]) {
+	schema := eg.LoadSchemaFile(sl, file)
+	if schema.IsErr() {
+		return schema
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			ds := generateDiagnostics(r)
+			for i := range ds {
+				if ds[i].File == "" {
+					ds[i].File = file
+				}
+			}
+			ret = rusty.Err[eg.Property](ds)
+		}
+	}()
+	generator(cfg, schema.Ok(), sl)
+	return schema
+}
+
+// generateProject runs the project generator on the generated schemas, a
+// panic is reported like the ones of generateFile.
+func generateProject(cfg *eg.GeneratorConfig, project projectGeneratorFn, sl eg.PropertyCtx, props []eg.Property) (ds eg.Diagnostics) {
+	defer func() {
+		if r := recover(); r != nil {
+			ds = generateDiagnostics(r)
+		}
+	}()
+	project(cfg, props, sl)
+	return nil
+}
+
+func generateAction(cmd command, args []string, vi versionInfo) int {
+	var cfg eg.GeneratorConfig
+	var watch bool
+	var check bool
+	var watchInterval time.Duration
+	var configFile string
+	var targetNames []string
+	var jobs int
+	fs := newFlagSet(cmd)
+	fs.StringArrayVar(&cfg.IncludeDirs, "include-dir", []string{}, "include directories")
+	fs.StringVar(&cfg.OutputDir, "output-dir", "./", "output directory")
+	fs.StringArrayVar(&cfg.InputFiles, "input-file", []string{}, "input files, the arguments are input files as well")
+	fs.BoolVar(&cfg.WriteTestSchema, "write-test-schema", false, "write test schema")
+	fs.StringArrayVar(&cfg.Plugins, "plugin", []string{}, "go file which is interpreted once for each schema after the generators of the target")
+	fs.BoolVar(&cfg.Version, "version", false, "write version")
+	fs.BoolVar(&watch, "watch", false, "regenerate when the schemas change")
+	fs.BoolVar(&check, "check", false, "compare the generated files with the output-dir, print a diff and fail if they differ")
+	fs.DurationVar(&watchInterval, "watch-interval", 500*time.Millisecond, "poll interval of --watch")
+	fs.StringVar(&configFile, "config", "", fmt.Sprintf("project config file (default: %s in the working directory)",
+		strings.Join(eg.ProjectConfigFileNames, ", ")))
+	fs.StringArrayVar(&targetNames, "target", []string{}, "generate only these targets of the project config")
+	fs.IntVarP(&jobs, "jobs", "j", runtime.GOMAXPROCS(0), "number of schemas generated in parallel")
+	df := addDiagnosticsFlags(fs)
+	eg.FromFlagSet(fs, "eg-", &cfg.EntityCfg)
+	if exit := parseFlags(fs, args); exit.IsSome() {
+		return exit.Value()
+	}
+	if cfg.Version {
+		return vi.print()
+	}
+	cfg.InputFiles = append(cfg.InputFiles, fs.Args()...)
+	if err := cfg.EntityCfg.Validate(); err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+	if !df.valid() {
+		return ExitUsage
+	}
+
+	if jobs < 1 {
+		fmt.Fprintf(stderr, "--jobs must be at least 1: %d\n", jobs)
+		return ExitUsage
+	}
+
+	if check && watch {
+		fmt.Fprintln(stderr, "--check and --watch are exclusive")
+		return ExitUsage
+	}
+
+	rTargets := projectTargets(fs, &cfg, configFile, targetNames)
+	if rTargets.IsErr() {
+		fmt.Fprintln(stderr, rTargets.Err())
+		return ExitUsage
+	}
+	targets := rTargets.Ok()
+
+	diagnostics := eg.Diagnostics{}
+	checkOutput := &eg.CheckOutput{}
+	var output eg.Output = eg.DiskOutput{}
+	if check {
+		output = checkOutput
+	}
+	manifest := eg.NewManifestOutput(output)
+	outputDirs := []string{}
+	plugins := map[string]*plugin.Plugin{}
+	watchers := []*watcher{}
+	for i := range targets {
+		target := &targets[i]
+		tcfg := &target.Config
+		generator, found := generators[tcfg.EntityCfg.Language]
+		if !found {
+			fmt.Fprintf(stderr, "unknown language: %s (supported: %v)\n", tcfg.EntityCfg.Language, languages())
+			return ExitUsage
+		}
+		project := projectGenerators[tcfg.EntityCfg.Language]
+		if prepare, found := targetGenerators[tcfg.EntityCfg.Language]; found {
+			rPair := prepare(tcfg)
+			if rPair.IsErr() {
+				fmt.Fprintln(stderr, rPair.Err())
+				return ExitFailure
+			}
+			generator = rPair.Ok().generator
+			project = rPair.Ok().project
+		}
+		rPlugins := loadPlugins(plugins, tcfg.Plugins)
+		if rPlugins.IsErr() {
+			fmt.Fprintln(stderr, rPlugins.Err())
+			return ExitFailure
+		}
+		var tp *targetPlugins
+		if rPlugins.Ok().IsSome() {
+			tp = rPlugins.Ok().Value()
+			generator = tp.generator(generator)
+			project = tp.project(project)
+		}
+		if len(targets) > 1 {
+			fmt.Fprintf(stdout, "Target: %s\n", target.Name)
+		}
+
+		rInputs := eg.ExpandInputFiles(tcfg.InputFiles)
+		if rInputs.IsErr() {
+			fmt.Fprintln(stderr, rInputs.Err())
+			return ExitUsage
+		}
+		tcfg.InputFiles = rInputs.Ok()
+
+		if watch {
+			tcfg.Output = output
+		} else {
+			tcfg.Output = manifest
+			outputDirs = append(outputDirs, tcfg.OutputDir)
+		}
+		if !check {
+			err := os.MkdirAll(tcfg.OutputDir, 0755)
+			if err != nil {
+				fmt.Fprintln(stderr, err)
+				return ExitFailure
+			}
+		}
+
+		if tcfg.WriteTestSchema {
+			eg.WriteTestSchema(tcfg)
+		}
+
+		if watch {
+			tcfg.Log = stdout
+			w := newWatcher(tcfg, generator)
+			w.project = project
+			w.plugins = tp
+			w.generateAll()
+			watchers = append(watchers, w)
+			continue
+		}
+
+		sl := eg.PropertyCtx{
+			Registry: eg.NewSchemaRegistry(eg.NewSchemaLoaderImpl(tcfg.IncludeDirs...)),
+		}
+		log := &sortedLog{}
+		tcfg.Log = log
+		schemas := make([]rusty.Result[eg.Property], len(tcfg.InputFiles))
+		forEachParallel(len(tcfg.InputFiles), jobs, func(i int) {
+			schemas[i] = generateFile(tcfg, generator, sl, tcfg.InputFiles[i])
+		})
+		log.flush(stdout)
+		props := []eg.Property{}
+		failed := false
+		for _, schema := range schemas {
+			if schema.IsErr() {
+				diagnostics = append(diagnostics, eg.AsDiagnostics(schema.Err())...)
+				failed = true
+				continue
+			}
+			props = append(props, schema.Ok())
+		}
+		if project != nil && !failed {
+			tcfg.Log = stdout
+			ds := generateProject(tcfg, project, sl, props)
+			failed = len(ds) > 0
+			diagnostics = append(diagnostics, ds...)
+		}
+		if tp != nil {
+			diagnostics = append(diagnostics, eg.AsDiagnostics(tp.flush(tcfg, props, !failed))...)
+		}
+		diagnostics = append(diagnostics, sl.Registry.Warnings()...)
+	}
+	if diagnostics.HasErrors() {
+		// without the complete output the manifest would remove valid files
+		df.report(locateDiagnostics(diagnostics))
+		return ExitFailure
+	}
+	if watch {
+		return runWatchers(watchers, watchInterval, interrupted())
+	}
+	committed := map[string]bool{}
+	for _, dir := range outputDirs {
+		if committed[dir] {
+			continue
+		}
+		committed[dir] = true
+		rRemoved := manifest.Commit(dir)
+		if rRemoved.IsErr() {
+			fmt.Fprintln(stderr, rRemoved.Err())
+			return ExitFailure
+		}
+		if !check {
+			for _, fname := range rRemoved.Ok() {
+				fmt.Fprintf(stdout, "Removed: %s\n", fname)
+			}
+		}
+	}
+	df.report(locateDiagnostics(diagnostics))
+	if check {
+		return reportStale(checkOutput.Stale())
+	}
+	return ExitOk
+}
+
+func reportStale(stale []eg.StaleFile) int {
+	if len(stale) == 0 {
+		return ExitOk
+	}
+	for _, s := range stale {
+		fmt.Fprint(stdout, s.Diff)
+	}
+	for _, s := range stale {
+		fmt.Fprintf(stderr, "stale: %s\n", s.FileName)
+	}
+	return ExitFailure
+}
@@ -0,0 +1,364 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateCheck(t *testing.T) {
+	out, errOut := captureOutput(t)
+	dir := t.TempDir()
+	schema := filepath.Join(dir, "base.schema.json")
+	assert.NoError(t, os.WriteFile(schema, []byte(`{
+		"$id": "https://Base", "title": "Base", "type": "object",
+		"properties": { "name": { "type": "string" } }
+	}`), 0644))
+	outDir := filepath.Join(dir, "out")
+	args := []string{"generate", "--output-dir", outDir, "--input-file", schema}
+
+	assert.Equal(t, ExitFailure, MainAction(append(args, "--check"), "", ""))
+	assert.Contains(t, out.String(), "--- /dev/null\n")
+	assert.Contains(t, errOut.String(), "stale: "+filepath.Join(outDir, "base.ts"))
+	_, err := os.Stat(outDir)
+	assert.True(t, os.IsNotExist(err))
+
+	assert.Equal(t, ExitOk, MainAction(args, "", ""))
+	out.Reset()
+	assert.Equal(t, ExitOk, MainAction(append(args, "--check"), "", ""))
+	assert.NotContains(t, out.String(), "@@")
+
+	fname := filepath.Join(outDir, "base.ts")
+	bytes, err := os.ReadFile(fname)
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(fname, append(bytes, []byte("// edited\n")...), 0644))
+	out.Reset()
+	assert.Equal(t, ExitFailure, MainAction(append(args, "--check"), "", ""))
+	assert.Contains(t, out.String(), "-// edited\n")
+
+	assert.Equal(t, ExitUsage, MainAction(append(args, "--check", "--watch"), "", ""))
+}
+
+func TestGenerateProjectConfig(t *testing.T) {
+	out, errOut := captureOutput(t)
+	dir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "schemas"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "schemas", "base.schema.json"), []byte(`{
+		"$id": "https://Base", "title": "Base", "type": "object",
+		"properties": { "name": { "type": "string" } }
+	}`), 0644))
+	config := filepath.Join(dir, "wueste.yaml")
+	assert.NoError(t, os.WriteFile(config, []byte(`
+inputFiles: [schemas/*.schema.json]
+targets:
+  - name: web
+    outputDir: web
+  - language: avro
+    outputDir: avro
+`), 0644))
+
+	assert.Equal(t, ExitOk, MainAction([]string{"generate", "--config", config}, "", ""), errOut.String())
+	assert.Contains(t, out.String(), "Target: web\n")
+	assert.FileExists(t, filepath.Join(dir, "web", "base.ts"))
+	assert.FileExists(t, filepath.Join(dir, "avro", "base.avsc"))
+
+	override := filepath.Join(dir, "override")
+	exit := MainAction([]string{"generate", "--config", config, "--target", "avro", "--output-dir", override}, "", "")
+	assert.Equal(t, ExitOk, exit, errOut.String())
+	assert.FileExists(t, filepath.Join(override, "base.avsc"))
+	assert.NoFileExists(t, filepath.Join(override, "base.ts"))
+
+	assert.Equal(t, ExitUsage, MainAction([]string{"generate", "--config", config, "--target", "go"}, "", ""))
+	assert.Contains(t, errOut.String(), "unknown target go")
+
+	assert.NoError(t, os.WriteFile(config, []byte("outputdir: web\n"), 0644))
+	errOut.Reset()
+	assert.Equal(t, ExitUsage, MainAction([]string{"generate", "--config", config}, "", ""))
+	assert.Equal(t, config+":1:1: unknown key outputdir\n", errOut.String())
+}
+
+func TestGenerateRemovesOrphans(t *testing.T) {
+	out, errOut := captureOutput(t)
+	dir := t.TempDir()
+	schemas := filepath.Join(dir, "schemas")
+	assert.NoError(t, os.MkdirAll(filepath.Join(schemas, "nested"), 0755))
+	writeBase := func(title string) {
+		assert.NoError(t, os.WriteFile(filepath.Join(schemas, "nested", "base.schema.json"), []byte(`{
+			"$id": "https://`+title+`", "title": "`+title+`", "type": "object",
+			"properties": { "name": { "type": "string" } }
+		}`), 0644))
+	}
+	writeBase("Base")
+	outDir := filepath.Join(dir, "out")
+	args := []string{"generate", "--output-dir", outDir, "--input-file", schemas}
+
+	assert.Equal(t, ExitOk, MainAction(args, "", ""), errOut.String())
+	assert.FileExists(t, filepath.Join(outDir, "base.ts"))
+	assert.FileExists(t, filepath.Join(outDir, ".wueste-manifest.json"))
+
+	writeBase("Renamed")
+	out.Reset()
+	assert.Equal(t, ExitOk, MainAction(args, "", ""), errOut.String())
+	assert.FileExists(t, filepath.Join(outDir, "renamed.ts"))
+	assert.NoFileExists(t, filepath.Join(outDir, "base.ts"))
+	assert.Contains(t, out.String(), "Removed: "+filepath.Join(outDir, "base.ts"))
+
+	assert.Equal(t, ExitUsage, MainAction([]string{"generate", "--output-dir", outDir, "--input-file", filepath.Join(dir, "*.yaml")}, "", ""))
+}
+
+func TestGenerateParallel(t *testing.T) {
+	out, errOut := captureOutput(t)
+	dir := t.TempDir()
+	schemas := filepath.Join(dir, "schemas")
+	assert.NoError(t, os.MkdirAll(schemas, 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(schemas, "shared.schema.json"), []byte(`{
+		"$id": "https://Shared", "title": "Shared", "type": "object",
+		"properties": { "name": { "type": "string" } }
+	}`), 0644))
+	for _, name := range []string{"a", "b", "c", "d", "e", "f"} {
+		assert.NoError(t, os.WriteFile(filepath.Join(schemas, name+".schema.json"), []byte(`{
+			"$id": "https://`+name+`", "title": "`+name+`", "type": "object",
+			"properties": { "shared": { "$ref": "file://shared.schema.json" } }
+		}`), 0644))
+	}
+	generate := func(jobs string) (string, map[string]string) {
+		outDir := filepath.Join(dir, "out"+jobs)
+		out.Reset()
+		assert.Equal(t, ExitOk, MainAction([]string{"generate", "-j", jobs, "--output-dir", outDir, "--input-file", schemas}, "", ""), errOut.String())
+		files := map[string]string{}
+		entries, err := os.ReadDir(outDir)
+		assert.NoError(t, err)
+		for _, entry := range entries {
+			bytes, err := os.ReadFile(filepath.Join(outDir, entry.Name()))
+			assert.NoError(t, err)
+			files[entry.Name()] = string(bytes)
+		}
+		return strings.ReplaceAll(out.String(), outDir, "OUT"), files
+	}
+	serialLog, serialFiles := generate("1")
+	parallelLog, parallelFiles := generate("8")
+	assert.Equal(t, serialLog, parallelLog)
+	// the shared schema is generated once
+	assert.Equal(t, 1, strings.Count(parallelLog, "OUT/shared.ts"))
+	assert.Equal(t, len(serialFiles), len(parallelFiles))
+	for name, content := range serialFiles {
+		if name == ".wueste-manifest.json" {
+			continue
+		}
+		assert.Equal(t, content, parallelFiles[name], name)
+	}
+
+	assert.Equal(t, ExitUsage, MainAction([]string{"generate", "-j", "0", "--input-file", schemas}, "", ""))
+}
+
+func TestGenerateOutputCollision(t *testing.T) {
+	_, errOut := captureOutput(t)
+	dir := t.TempDir()
+	for _, name := range []string{"a", "b"} {
+		assert.NoError(t, os.MkdirAll(filepath.Join(dir, name), 0755))
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name, "address.schema.json"), []byte(`{
+			"$id": "https://`+name+`/address", "title": "Address", "type": "object",
+			"properties": { "`+name+`": { "type": "string" } }
+		}`), 0644))
+	}
+	order := filepath.Join(dir, "order.schema.json")
+	assert.NoError(t, os.WriteFile(order, []byte(`{
+		"$id": "https://Order", "title": "Order", "type": "object",
+		"properties": {
+			"bill": { "$ref": "file://a/address.schema.json" },
+			"ship": { "$ref": "file://b/address.schema.json" }
+		}
+	}`), 0644))
+	outDir := filepath.Join(dir, "out")
+	assert.Equal(t, ExitFailure, MainAction([]string{"generate", "--output-dir", outDir, order}, "", ""))
+	assert.Contains(t, errOut.String(), "error[output-collision]: output "+filepath.Join(outDir, "order$address.ts")+
+		" of "+filepath.Join(dir, "b", "address.schema.json")+"#https://b/address is generated for "+
+		filepath.Join(dir, "a", "address.schema.json")+"#https://a/address already")
+}
+
+func TestGenerateDialectWarnings(t *testing.T) {
+	_, errOut := captureOutput(t)
+	dir := t.TempDir()
+	schema := filepath.Join(dir, "old.schema.json")
+	assert.NoError(t, os.WriteFile(schema, []byte(`{
+  "$schema": "http://json-schema.org/draft-04/schema#",
+  "id": "https://Old", "title": "Old", "type": "object",
+  "properties": { "name": { "type": "string", "const": "x" } }
+}`), 0644))
+	outDir := filepath.Join(dir, "out")
+	// warnings do not fail the generation
+	assert.Equal(t, ExitOk, MainAction([]string{"generate", "--output-dir", outDir, "--input-file", schema}, "", ""))
+	assert.Equal(t, schema+":4:47#/properties/name/const: warning[dialect-keyword]: const is not a keyword of draft-04\n", errOut.String())
+	bytes, err := os.ReadFile(filepath.Join(outDir, "old.ts"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(bytes), `id: "https://Old"`)
+}
+
+func TestGenerateArgs(t *testing.T) {
+	captureOutput(t)
+	dir := t.TempDir()
+	schema := filepath.Join(dir, "base.schema.json")
+	assert.NoError(t, os.WriteFile(schema, []byte(`{
+		"$id": "https://Base", "title": "Base", "type": "object",
+		"properties": { "name": { "type": "string" } }
+	}`), 0644))
+	outDir := filepath.Join(dir, "out")
+	assert.Equal(t, ExitOk, MainAction([]string{"generate", "--output-dir", outDir, schema}, "", ""))
+	_, err := os.Stat(filepath.Join(outDir, "base.ts"))
+	assert.NoError(t, err)
+
+	for _, flag := range []string{"--eg-file-case=camel", "--eg-import-extension=.mjs", "--eg-layout=tree"} {
+		assert.Equal(t, ExitUsage, MainAction([]string{"generate", "--output-dir", outDir, flag, schema}, "", ""), flag)
+	}
+}
+
+func TestGeneratePlugin(t *testing.T) {
+	out, errOut := captureOutput(t)
+	dir := t.TempDir()
+	schema := filepath.Join(dir, "base.schema.json")
+	assert.NoError(t, os.WriteFile(schema, []byte(`{
+		"$id": "https://Base", "title": "Base", "type": "object",
+		"properties": { "name": { "type": "string" } }
+	}`), 0644))
+	plugin := filepath.Join(dir, "plugin.go")
+	assert.NoError(t, os.WriteFile(plugin, []byte(`package helpers
+
+import (
+	"path/filepath"
+
+	"github.com/mabels/wueste/entity-generator/plugin"
+)
+
+func Generate(ctx *plugin.Context) error {
+	fname := filepath.Join(ctx.Config.OutputDir, "base.ts")
+	wr := ctx.Writer()
+	wr.WriteLine("export const helper = 42;")
+	ctx.Files.AppendLines(fname, wr)
+	ctx.Files.Write(filepath.Join(ctx.Config.OutputDir, "helpers.ts"), "export * from \"./base\";\n")
+	return nil
+}
+`), 0644))
+	outDir := filepath.Join(dir, "out")
+	args := []string{"generate", "--output-dir", outDir, "--input-file", schema, "--plugin", plugin}
+	assert.Equal(t, ExitOk, MainAction(args, "", ""))
+	bytes, err := os.ReadFile(filepath.Join(outDir, "base.ts"))
+	assert.NoError(t, err)
+	assert.True(t, strings.HasSuffix(string(bytes), "\nexport const helper = 42;\n"))
+	bytes, err = os.ReadFile(filepath.Join(outDir, "helpers.ts"))
+	assert.NoError(t, err)
+	assert.Equal(t, "export * from \"./base\";\n", string(bytes))
+
+	// the plugin files are part of the manifest
+	out.Reset()
+	assert.Equal(t, ExitOk, MainAction(append(args, "--check"), "", ""))
+	assert.NotContains(t, out.String(), "@@")
+
+	assert.NoError(t, os.WriteFile(plugin, []byte("package helpers\n\nfunc Generate() {}\n"), 0644))
+	assert.Equal(t, ExitFailure, MainAction(args, "", ""))
+	assert.Contains(t, errOut.String(), "expected func(ctx *plugin.Context) error")
+}
+
+func TestGeneratePluginFails(t *testing.T) {
+	_, errOut := captureOutput(t)
+	dir := t.TempDir()
+	schema := filepath.Join(dir, "base.schema.json")
+	assert.NoError(t, os.WriteFile(schema, []byte(`{
+		"$id": "https://Base", "title": "Base", "type": "object",
+		"properties": { "name": { "type": "string" } }
+	}`), 0644))
+	plugin := filepath.Join(dir, "plugin.go")
+	assert.NoError(t, os.WriteFile(plugin, []byte(`package failing
+
+import (
+	"errors"
+
+	"github.com/mabels/wueste/entity-generator/plugin"
+)
+
+func Generate(ctx *plugin.Context) error {
+	return errors.New("no registry")
+}
+`), 0644))
+	outDir := filepath.Join(dir, "out")
+	args := []string{"generate", "--output-dir", outDir, "--input-file", schema, "--plugin", plugin}
+	assert.Equal(t, ExitFailure, MainAction(args, "", ""))
+	assert.Contains(t, errOut.String(), "plugin "+plugin+": no registry")
+	// the files of the generators are written without the plugins
+	_, err := os.Stat(filepath.Join(outDir, "base.ts"))
+	assert.NoError(t, err)
+}
+
+func TestGeneratePluginSharedFiles(t *testing.T) {
+	_, errOut := captureOutput(t)
+	dir := t.TempDir()
+	args := []string{"generate", "--output-dir", filepath.Join(dir, "out"), "--jobs", "2"}
+	for _, name := range []string{"b", "a", "c"} {
+		schema := filepath.Join(dir, name+".schema.json")
+		assert.NoError(t, os.WriteFile(schema, []byte(fmt.Sprintf(`{
+			"$id": "https://%s", "title": "%s", "type": "object",
+			"properties": { "name": { "type": "string" } }
+		}`, name, name)), 0644))
+		args = append(args, "--input-file", schema)
+	}
+	plugin := filepath.Join(dir, "plugin.go")
+	assert.NoError(t, os.WriteFile(plugin, []byte(`package registry
+
+import (
+	"path/filepath"
+
+	"github.com/mabels/wueste/entity-generator/plugin"
+)
+
+func Generate(ctx *plugin.Context) error {
+	ctx.Files.Append(filepath.Join(ctx.Config.OutputDir, "registry.txt"), ctx.Entity.Id()+"\n")
+	return nil
+}
+`), 0644))
+	args = append(args, "--plugin", plugin)
+	assert.Equal(t, ExitOk, MainAction(args, "", ""), errOut.String())
+	bytes, err := os.ReadFile(filepath.Join(dir, "out", "registry.txt"))
+	assert.NoError(t, err)
+	assert.Equal(t, "https://b\nhttps://a\nhttps://c\n", string(bytes))
+
+	// a second run does not append to the registry of the first
+	assert.Equal(t, ExitOk, MainAction(args, "", ""))
+	bytes, err = os.ReadFile(filepath.Join(dir, "out", "registry.txt"))
+	assert.NoError(t, err)
+	assert.Equal(t, "https://b\nhttps://a\nhttps://c\n", string(bytes))
+}
+
+func TestGenerateTemplate(t *testing.T) {
+	out, errOut := captureOutput(t)
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"a.schema.json": `{ "$id": "https://A", "title": "A", "type": "object", "properties": { "b": { "$ref": "file://b.schema.json" } } }`,
+		"b.schema.json": `{ "$id": "https://B", "title": "B", "type": "object", "properties": { "name": { "type": "string" } } }`,
+		"templates/entity/{{.Entity.FileName}}.txt.tmpl": "{{.Entity.Name}}:{{range .Entity.Properties}} {{.Name}}{{end}}\n",
+		"templates/project/all.txt.tmpl":                 "{{range .Entities}}{{.Name}}\n{{end}}",
+	} {
+		fname := filepath.Join(dir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(fname), 0755))
+		assert.NoError(t, os.WriteFile(fname, []byte(content), 0644))
+	}
+	outDir := filepath.Join(dir, "out")
+	args := []string{"generate", "--eg-language", "template", "--eg-template-dir", filepath.Join(dir, "templates"),
+		"--output-dir", outDir, "--input-file", filepath.Join(dir, "a.schema.json")}
+	assert.Equal(t, ExitOk, MainAction(args, "", ""))
+	assert.Contains(t, out.String(), "-> "+filepath.Join(outDir, "all.txt"))
+	for fname, content := range map[string]string{"a.txt": "A: b\n", "b.txt": "B: name\n", "all.txt": "A\nB\n"} {
+		bytes, err := os.ReadFile(filepath.Join(outDir, fname))
+		assert.NoError(t, err)
+		assert.Equal(t, content, string(bytes))
+	}
+	out.Reset()
+	assert.Equal(t, ExitOk, MainAction(append(args, "--check"), "", ""))
+	assert.NotContains(t, out.String(), "@@")
+
+	assert.Equal(t, ExitFailure, MainAction([]string{"generate", "--eg-language", "template",
+		"--output-dir", outDir, "--input-file", filepath.Join(dir, "a.schema.json")}, "", ""))
+	assert.Contains(t, errOut.String(), "the language template needs a template dir")
+}
@@ -0,0 +1,165 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	eg "github.com/mabels/wueste/entity-generator"
+	"github.com/mabels/wueste/entity-generator/ts"
+)
+
+// languageLintRules are the rules of the generators in addition to
+// eg.LintRules.
+var languageLintRules = map[string]func() []eg.LintRule{
+	"ts": ts.LintRules,
+}
+
+var lintSeverities = []eg.Severity{eg.SeverityError, eg.SeverityWarning, eg.SeverityNote, eg.LintOff}
+
+func init() {
+	registerCommand(command{
+		name:    "lint",
+		args:    "schema...",
+		summary: "check schemas for problems of the generators",
+		run:     lintAction,
+	})
+}
+
+// parseLintRuleFlags parses the name=severity flags of --rule.
+func parseLintRuleFlags(rules []eg.LintRule, flags []string) (map[string]eg.Severity, error) {
+	known := map[string]bool{}
+	for _, r := range rules {
+		known[r.Name] = true
+	}
+	severities := map[string]eg.Severity{}
+	for _, flag := range flags {
+		name, severity, found := strings.Cut(flag, "=")
+		if !found {
+			return nil, fmt.Errorf("rule %s: expected name=severity", flag)
+		}
+		if !known[name] {
+			return nil, fmt.Errorf("unknown rule: %s (supported: %v)", name, eg.LintRuleNames(rules))
+		}
+		valid := false
+		for _, s := range lintSeverities {
+			valid = valid || string(s) == severity
+		}
+		if !valid {
+			return nil, fmt.Errorf("rule %s: unknown severity %s (supported: %v)", name, severity, lintSeverities)
+		}
+		severities[name] = eg.Severity(severity)
+	}
+	return severities, nil
+}
+
+// readSchemaFile decodes a schema file in the format of its extension, the
+// format is sniffed for unknown extensions.
+func readSchemaFile(file string) (eg.JSONDict, eg.SchemaFormat, eg.Diagnostics, error) {
+	bytes, err := os.ReadFile(file)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	format, found := eg.SchemaFormatByExt(file)
+	if !found {
+		format = eg.SniffSchemaFormat(bytes)
+	}
+	rjs := eg.DecodeSchemaFile(file, bytes)
+	if rjs.IsErr() {
+		return nil, format, eg.AsDiagnostics(rjs.Err()).InFile(file), nil
+	}
+	return rjs.Ok(), format, nil, nil
+}
+
+// writeSchemaFile writes js back to file in the format it is read from.
+func writeSchemaFile(file string, format eg.SchemaFormat, js eg.JSONDict, indent string) error {
+	bytes, err := eg.MarshalSchema(format, js, indent)
+	if err != nil {
+		return err
+	}
+	return eg.DiskOutput{}.WriteFile(file, bytes)
+}
+
+// lintFile lints a schema file and writes it back if findings are fixed.
+func lintFile(file string, opts eg.LintOptions, indent string) (eg.LintResult, error) {
+	js, format, ds, err := readSchemaFile(file)
+	if err != nil || len(ds) > 0 {
+		return eg.LintResult{Diagnostics: ds}, err
+	}
+	res := eg.Lint(file, js, opts)
+	if res.Fixed == 0 {
+		return res, nil
+	}
+	return res, writeSchemaFile(file, format, js, indent)
+}
+
+func lintAction(cmd command, args []string, vi versionInfo) int {
+	var language string
+	var ruleFlags []string
+	var fix bool
+	var listRules bool
+	var indent string
+	fs := newFlagSet(cmd)
+	fs.StringVar(&language, "language", "ts", "check the rules of the generator of this language")
+	fs.StringArrayVar(&ruleFlags, "rule", []string{}, "set the severity of a rule: name=error|warning|note|off")
+	fs.BoolVar(&fix, "fix", false, "fix the findings which have an autofix in place")
+	fs.BoolVar(&listRules, "list-rules", false, "list the rules and their default severity")
+	fs.StringVar(&indent, "indent", "  ", "one indent level of fixed files")
+	df := addDiagnosticsFlags(fs)
+	if exit := parseFlags(fs, args); exit.IsSome() {
+		return exit.Value()
+	}
+	if !df.valid() {
+		return ExitUsage
+	}
+	if _, found := generators[language]; !found {
+		fmt.Fprintf(stderr, "unknown language: %s (supported: %v)\n", language, languages())
+		return ExitUsage
+	}
+	rules := eg.LintRules()
+	if langRules, found := languageLintRules[language]; found {
+		rules = append(rules, langRules()...)
+	}
+	if listRules {
+		for _, r := range rules {
+			fmt.Fprintf(stdout, "%-22s %-8s %s\n", r.Name, r.Severity, r.Description)
+		}
+		return ExitOk
+	}
+	severities, err := parseLintRuleFlags(rules, ruleFlags)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return ExitUsage
+	}
+	rFiles := eg.ExpandInputFiles(fs.Args())
+	if rFiles.IsErr() {
+		fmt.Fprintln(stderr, rFiles.Err())
+		return ExitFailure
+	}
+	opts := eg.LintOptions{
+		Rules:      rules,
+		Severities: severities,
+		Fix:        fix,
+	}
+	ds := eg.Diagnostics{}
+	for _, file := range rFiles.Ok() {
+		res, err := lintFile(file, opts, indent)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return ExitFailure
+		}
+		if res.Fixed > 0 {
+			fmt.Fprintf(stdout, "Fixed: %s (%d)\n", file, res.Fixed)
+		}
+		ds = append(ds, res.Diagnostics...)
+	}
+	df.report(locateDiagnostics(ds))
+	if ds.HasErrors() {
+		return ExitFailure
+	}
+	return ExitOk
+}
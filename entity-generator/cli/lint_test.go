@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	eg "github.com/mabels/wueste/entity-generator"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLint(t *testing.T) {
+	out, errOut := captureOutput(t)
+	dir := t.TempDir()
+	schema := filepath.Join(dir, "base.schema.json")
+	assert.NoError(t, os.WriteFile(schema, []byte(`{
+  "type": "object",
+  "properties": {
+    "name": { "type": "string" }
+  },
+  "required": ["name", "gone"]
+}
+`), 0644))
+
+	assert.Equal(t, ExitFailure, MainAction([]string{"lint", schema}, "", ""))
+	assert.Equal(t, schema+":1:1: error[missing-id]: object with properties has no $id\n"+
+		schema+":1:1: warning[missing-title]: object with properties has no title and is generated as anonymous type\n"+
+		schema+":6:24#/required/1: warning[unknown-required]: required property gone is not in properties\n", errOut.String())
+
+	errOut.Reset()
+	exit := MainAction([]string{"lint", "--rule", "missing-id=off", "--diagnostics-format", "json", schema}, "", "")
+	assert.Equal(t, ExitOk, exit, errOut.String())
+	ds := eg.Diagnostics{}
+	assert.NoError(t, json.Unmarshal(errOut.Bytes(), &ds))
+	assert.Equal(t, 2, len(ds))
+
+	errOut.Reset()
+	assert.Equal(t, ExitOk, MainAction([]string{"lint", "--fix", schema}, "", ""), errOut.String())
+	assert.Equal(t, "Fixed: "+schema+" (3)\n", out.String())
+	assert.Equal(t, "", errOut.String())
+	bytes, err := os.ReadFile(schema)
+	assert.NoError(t, err)
+	assert.Equal(t, `{
+  "type": "object",
+  "properties": {
+    "name": {
+      "type": "string"
+    }
+  },
+  "required": [
+    "name"
+  ],
+  "$id": "base",
+  "title": "base"
+}
+`, string(bytes))
+
+	out.Reset()
+	assert.Equal(t, ExitOk, MainAction([]string{"lint", "--list-rules"}, "", ""))
+	assert.Contains(t, out.String(), "ts-name-collision")
+
+	assert.Equal(t, ExitUsage, MainAction([]string{"lint", "--rule", "unknown=off", schema}, "", ""))
+	assert.Equal(t, ExitUsage, MainAction([]string{"lint", "--rule", "missing-id=fatal", schema}, "", ""))
+	assert.Equal(t, ExitUsage, MainAction([]string{"lint", "--language", "cobol", schema}, "", ""))
+}
+
+func TestLintFixYaml(t *testing.T) {
+	out, errOut := captureOutput(t)
+	dir := t.TempDir()
+	schema := filepath.Join(dir, "base.schema.yaml")
+	assert.NoError(t, os.WriteFile(schema, []byte(`type: object
+description: a <b> & c
+properties:
+  name:
+    type: string
+required: [name, gone]
+`), 0644))
+	assert.Equal(t, ExitOk, MainAction([]string{"lint", "--fix", schema}, "", ""), errOut.String())
+	assert.Equal(t, "Fixed: "+schema+" (3)\n", out.String())
+	bytes, err := os.ReadFile(schema)
+	assert.NoError(t, err)
+	assert.Equal(t, `type: object
+description: a <b> & c
+properties:
+  name:
+    type: string
+required:
+  - name
+$id: base
+title: base
+`, string(bytes))
+
+	errOut.Reset()
+	assert.NoError(t, os.WriteFile(schema, []byte("type: [object\n"), 0644))
+	assert.Equal(t, ExitFailure, MainAction([]string{"lint", schema}, "", ""))
+	assert.Contains(t, errOut.String(), schema+":")
+	assert.Contains(t, errOut.String(), "error parsing schema")
+}
@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/pflag"
+
+	"github.com/mabels/wueste/entity-generator/rusty"
+)
+
+const (
+	ExitOk      = 0
+	ExitFailure = 1
+	ExitUsage   = 2
+)
+
+var stdout io.Writer = os.Stdout
+var stderr io.Writer = os.Stderr
+
+type versionInfo struct {
+	version   string
+	gitCommit string
+}
+
+func (v versionInfo) print() int {
+	version := v.version
+	if version == "" {
+		version = "dev"
+	}
+	gitCommit := v.gitCommit
+	if gitCommit == "" {
+		gitCommit = "dev"
+	}
+	fmt.Fprintf(stdout, "Version: %s:%s\n", version, gitCommit)
+	return ExitOk
+}
+
+type command struct {
+	name    string
+	args    string
+	summary string
+	run     func(cmd command, args []string, vi versionInfo) int
+}
+
+var commands = map[string]command{}
+
+func registerCommand(cmd command) {
+	commands[cmd.name] = cmd
+}
+
+func commandNames() []string {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: wueste-generator <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, name := range commandNames() {
+		fmt.Fprintf(w, "  %-10s %s\n", name, commands[name].summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run 'wueste-generator <command> --help' for the flags of a command.")
+}
+
+// newFlagSet returns a FlagSet which reports errors instead of exiting
+// and prints the usage of the command on --help.
+func newFlagSet(cmd command) *pflag.FlagSet {
+	fs := pflag.NewFlagSet(cmd.name, pflag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: wueste-generator %s [flags] %s\n\n%s\n\nFlags:\n", cmd.name, cmd.args, cmd.summary)
+		fmt.Fprint(stderr, fs.FlagUsages())
+	}
+	return fs
+}
+
+// parseFlags maps the parse result to an exit code, rusty.None means
+// the command should continue.
+func parseFlags(fs *pflag.FlagSet, args []string) rusty.Optional[int] {
+	err := fs.Parse(args)
+	if err == pflag.ErrHelp {
+		return rusty.Some(ExitOk)
+	}
+	if err != nil {
+		return rusty.Some(ExitUsage)
+	}
+	return rusty.None[int]()
+}
+
+func MainAction(args []string, version string, gitCommit string) int {
+	vi := versionInfo{version: version, gitCommit: gitCommit}
+	if len(args) == 0 {
+		usage(stderr)
+		return ExitUsage
+	}
+	switch args[0] {
+	case "--version", "version":
+		return vi.print()
+	case "-h", "--help", "help":
+		usage(stdout)
+		return ExitOk
+	}
+	name := args[0]
+	if strings.HasPrefix(name, "-") {
+		// the flat flags of the former generator are still generate
+		name = "generate"
+	} else {
+		args = args[1:]
+	}
+	cmd, found := commands[name]
+	if !found {
+		fmt.Fprintf(stderr, "unknown command: %s\n\n", name)
+		usage(stderr)
+		return ExitUsage
+	}
+	return cmd.run(cmd, args, vi)
+}
@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func captureOutput(t *testing.T) (*bytes.Buffer, *bytes.Buffer) {
+	out := &bytes.Buffer{}
+	err := &bytes.Buffer{}
+	stdout = out
+	stderr = err
+	t.Cleanup(func() {
+		stdout = os.Stdout
+		stderr = os.Stderr
+	})
+	return out, err
+}
+
+func TestMainAction(t *testing.T) {
+	exit := MainAction([]string{
+		"--write-test-schema=true",
+		"--include-dir", "../../src/generated",
+		"--input-file", "go/base.schema.json",
+		"--input-file", "../../src/generated/go/simple_type.schema.json",
+		"--input-file", "../generated/go/nested_type.schema.json",
+		"--eg-from-wueste", "../../wueste",
+		"--output-dir", "../../src/generated/go",
+	}, "test", "test")
+	assert.Equal(t, ExitOk, exit)
+}
+
+func TestVersion(t *testing.T) {
+	out, _ := captureOutput(t)
+	assert.Equal(t, ExitOk, MainAction([]string{"--version"}, "1.0", "abc"))
+	assert.Equal(t, "Version: 1.0:abc\n", out.String())
+
+	out.Reset()
+	outputDir := t.TempDir()
+	exit := MainAction([]string{"generate", "--version", "--write-test-schema", "--output-dir", outputDir}, "", "")
+	assert.Equal(t, ExitOk, exit)
+	assert.Equal(t, "Version: dev:dev\n", out.String())
+	entries, _ := os.ReadDir(outputDir)
+	assert.Empty(t, entries)
+}
+
+func TestUsage(t *testing.T) {
+	_, errOut := captureOutput(t)
+	assert.Equal(t, ExitUsage, MainAction([]string{}, "", ""))
+	assert.Equal(t, ExitUsage, MainAction([]string{"unknown"}, "", ""))
+	assert.Contains(t, errOut.String(), "unknown command: unknown")
+	assert.Equal(t, ExitUsage, MainAction([]string{"generate", "--no-such-flag"}, "", ""))
+	assert.Equal(t, ExitUsage, MainAction([]string{"generate", "--eg-language", "cobol"}, "", ""))
+
+	errOut.Reset()
+	assert.Equal(t, ExitOk, MainAction([]string{"diff", "--help"}, "", ""))
+	assert.Contains(t, errOut.String(), "Usage: wueste-generator diff [flags] old-schema new-schema")
+}
+
+func TestBundle(t *testing.T) {
+	_, errOut := captureOutput(t)
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "sub.schema.json"), []byte(`{
+		"$id": "https://Sub", "title": "Sub", "type": "object",
+		"properties": { "name": { "type": "string" } }
+	}`), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "base.schema.json"), []byte(`{
+		"$id": "https://Base", "title": "Base", "type": "object",
+		"properties": { "sub": { "$ref": "file://sub.schema.json" } }
+	}`), 0644))
+	outDir := filepath.Join(dir, "out")
+	exit := MainAction([]string{"bundle", "--output-dir", outDir, filepath.Join(dir, "base.schema.json")}, "", "")
+	assert.Equal(t, ExitOk, exit, errOut.String())
+	bytes, err := os.ReadFile(filepath.Join(outDir, "base.schema.json"))
+	assert.NoError(t, err)
+	assert.NotContains(t, string(bytes), "$ref")
+	assert.Contains(t, string(bytes), `"title": "Sub"`)
+}
@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"fmt"
+
+	eg "github.com/mabels/wueste/entity-generator"
+)
+
+func init() {
+	registerCommand(command{
+		name:    "migrate",
+		args:    "schema...",
+		summary: "rewrite schemas of older drafts to 2020-12",
+		run:     migrateAction,
+	})
+}
+
+// migrateFile migrates a schema file and writes it back if keywords are
+// rewritten, with check the file is not written.
+func migrateFile(file string, check bool, indent string) (int, eg.Diagnostics, error) {
+	js, format, ds, err := readSchemaFile(file)
+	if err != nil || len(ds) > 0 {
+		return 0, ds, err
+	}
+	changes := eg.MigrateSchema(js)
+	if changes == 0 || check {
+		return changes, nil, nil
+	}
+	return changes, nil, writeSchemaFile(file, format, js, indent)
+}
+
+func migrateAction(cmd command, args []string, vi versionInfo) int {
+	var check bool
+	var indent string
+	fs := newFlagSet(cmd)
+	fs.BoolVar(&check, "check", false, "list the schemas which need a migration and fail if there are any")
+	fs.StringVar(&indent, "indent", "  ", "one indent level of migrated files")
+	df := addDiagnosticsFlags(fs)
+	if exit := parseFlags(fs, args); exit.IsSome() {
+		return exit.Value()
+	}
+	if !df.valid() {
+		return ExitUsage
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return ExitUsage
+	}
+	rFiles := eg.ExpandInputFiles(fs.Args())
+	if rFiles.IsErr() {
+		fmt.Fprintln(stderr, rFiles.Err())
+		return ExitFailure
+	}
+	ds := eg.Diagnostics{}
+	outdated := 0
+	for _, file := range rFiles.Ok() {
+		changes, fileDs, err := migrateFile(file, check, indent)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return ExitFailure
+		}
+		ds = append(ds, fileDs...)
+		if changes == 0 {
+			continue
+		}
+		outdated++
+		if check {
+			fmt.Fprintf(stdout, "Outdated: %s (%d)\n", file, changes)
+		} else {
+			fmt.Fprintf(stdout, "Migrated: %s (%d)\n", file, changes)
+		}
+	}
+	df.report(locateDiagnostics(ds))
+	if ds.HasErrors() || (check && outdated > 0) {
+		return ExitFailure
+	}
+	return ExitOk
+}
@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrate(t *testing.T) {
+	out, errOut := captureOutput(t)
+	dir := t.TempDir()
+	schema := filepath.Join(dir, "old.schema.json")
+	assert.NoError(t, os.WriteFile(schema, []byte(`{
+  "$schema": "http://json-schema.org/draft-04/schema#",
+  "id": "https://Old",
+  "type": "object",
+  "properties": {
+    "count": { "type": "integer", "minimum": 0, "exclusiveMinimum": true }
+  }
+}
+`), 0644))
+
+	assert.Equal(t, ExitFailure, MainAction([]string{"migrate", "--check", schema}, "", ""))
+	assert.Equal(t, "Outdated: "+schema+" (3)\n", out.String())
+
+	out.Reset()
+	assert.Equal(t, ExitOk, MainAction([]string{"migrate", schema}, "", ""), errOut.String())
+	assert.Equal(t, "Migrated: "+schema+" (3)\n", out.String())
+	bytes, err := os.ReadFile(schema)
+	assert.NoError(t, err)
+	assert.Equal(t, `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "$id": "https://Old",
+  "type": "object",
+  "properties": {
+    "count": {
+      "type": "integer",
+      "exclusiveMinimum": 0
+    }
+  }
+}
+`, string(bytes))
+
+	out.Reset()
+	assert.Equal(t, ExitOk, MainAction([]string{"migrate", "--check", schema}, "", ""))
+	assert.Equal(t, "", out.String())
+	assert.Equal(t, "", errOut.String())
+}
+
+func TestMigrateYaml(t *testing.T) {
+	out, errOut := captureOutput(t)
+	dir := t.TempDir()
+	schema := filepath.Join(dir, "old.schema.yaml")
+	assert.NoError(t, os.WriteFile(schema, []byte(`$schema: http://json-schema.org/draft-04/schema#
+id: https://Old
+description: a <b> & c
+type: object
+`), 0644))
+	assert.Equal(t, ExitOk, MainAction([]string{"migrate", schema}, "", ""), errOut.String())
+	assert.Equal(t, "Migrated: "+schema+" (2)\n", out.String())
+	bytes, err := os.ReadFile(schema)
+	assert.NoError(t, err)
+	assert.Equal(t, `$schema: https://json-schema.org/draft/2020-12/schema
+$id: https://Old
+description: a <b> & c
+type: object
+`, string(bytes))
+}
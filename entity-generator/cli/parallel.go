@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"sync"
+)
+
+// forEachParallel calls fn for the indexes 0..n-1 on up to jobs workers.
+func forEachParallel(n int, jobs int, fn func(i int)) {
+	if jobs < 1 {
+		jobs = 1
+	}
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < jobs && w < n; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				fn(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+}
+
+// sortedLog collects the log lines of concurrent generators and writes
+// them sorted, so the output does not depend on the scheduling.
+type sortedLog struct {
+	mutex sync.Mutex
+	buf   bytes.Buffer
+}
+
+func (l *sortedLog) Write(p []byte) (int, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.buf.Write(p)
+}
+
+func (l *sortedLog) flush(w io.Writer) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	lines := bytes.SplitAfter(l.buf.Bytes(), []byte("\n"))
+	sort.SliceStable(lines, func(i, j int) bool {
+		return bytes.Compare(lines[i], lines[j]) < 0
+	})
+	for _, line := range lines {
+		w.Write(line)
+	}
+	l.buf.Reset()
+}
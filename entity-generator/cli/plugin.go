@@ -0,0 +1,95 @@
+package cli
+
+import (
+	eg "github.com/mabels/wueste/entity-generator"
+	"github.com/mabels/wueste/entity-generator/plugin"
+	"github.com/mabels/wueste/entity-generator/rusty"
+)
+
+// targetPlugins are the plugins of a target. The generators of a target
+// write into one Files, the plugins run after the project generator on
+// all entities in the order of the inputs, so a plugin can append the
+// entities to a shared file like a registration.
+type targetPlugins struct {
+	plugins []*plugin.Plugin
+	files   *plugin.Files
+}
+
+// loadPlugins returns the plugins of fnames, it is None without plugins.
+// loaded keeps the plugins which are shared by the targets.
+func loadPlugins(loaded map[string]*plugin.Plugin, fnames []string) rusty.Result[rusty.Optional[*targetPlugins]] {
+	if len(fnames) == 0 {
+		return rusty.Ok(rusty.None[*targetPlugins]())
+	}
+	tp := &targetPlugins{files: plugin.NewFiles()}
+	for _, fname := range fnames {
+		p, found := loaded[fname]
+		if !found {
+			rPlugin := plugin.Load(fname)
+			if rPlugin.IsErr() {
+				return rusty.Err[rusty.Optional[*targetPlugins]](rPlugin.Err())
+			}
+			p = rPlugin.Ok()
+			loaded[fname] = p
+		}
+		tp.plugins = append(tp.plugins, p)
+	}
+	return rusty.Ok(rusty.Some(tp))
+}
+
+// generator collects the files of generator until the project step.
+func (tp *targetPlugins) generator(generator generatorFn) generatorFn {
+	return func(cfg *eg.GeneratorConfig, prop eg.Property, sl eg.PropertyCtx) {
+		pcfg := *cfg
+		pcfg.Output = tp.files
+		generator(&pcfg, prop, sl)
+	}
+}
+
+// project writes the files of project, which could be nil, into the
+// collected files.
+func (tp *targetPlugins) project(project projectGeneratorFn) projectGeneratorFn {
+	if project == nil {
+		return nil
+	}
+	return func(cfg *eg.GeneratorConfig, props []eg.Property, sl eg.PropertyCtx) {
+		pcfg := *cfg
+		pcfg.Output = tp.files
+		project(&pcfg, props, sl)
+	}
+}
+
+// flush runs the plugins on the collected files and writes them to the
+// output of cfg. Without complete props, e.g. an input or the project
+// failed, the plugins are skipped and the files of the generators are
+// written, as they are if a plugin fails.
+func (tp *targetPlugins) flush(cfg *eg.GeneratorConfig, props []eg.Property, complete bool) error {
+	var out eg.Output = eg.DiskOutput{}
+	if cfg.Output != nil {
+		out = cfg.Output
+	}
+	if !complete {
+		return tp.files.Flush(out)
+	}
+	// the plugins change a copy, a watcher runs them again on the files
+	// of the generators without the appends of the last run
+	files := tp.files.Clone()
+	pcfg := *cfg
+	pcfg.Output = files
+	for _, prop := range props {
+		ctx := &plugin.Context{Config: &pcfg, Entity: prop, Files: files}
+		for _, p := range tp.plugins {
+			if err := p.Generate(ctx); err != nil {
+				if ferr := tp.files.Flush(out); ferr != nil {
+					return ferr
+				}
+				d := eg.NewDiagnostic(eg.DiagGenerateFailed, "plugin %s: %v", p.FileName, err)
+				if prop.Meta().FileName().IsSome() {
+					d.File = prop.Meta().FileName().Value()
+				}
+				return d
+			}
+		}
+	}
+	return files.Flush(out)
+}
@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/pflag"
+
+	eg "github.com/mabels/wueste/entity-generator"
+	"github.com/mabels/wueste/entity-generator/rusty"
+)
+
+// overrideFlags copies the flags which are set on the command line over
+// the values of the project config, the arguments are input files.
+func overrideFlags(fs *pflag.FlagSet, flags *eg.GeneratorConfig, cfg *eg.GeneratorConfig) {
+	overrides := map[string]func(){
+		"include-dir":         func() { cfg.IncludeDirs = flags.IncludeDirs },
+		"output-dir":          func() { cfg.OutputDir = flags.OutputDir },
+		"input-file":          func() { cfg.InputFiles = flags.InputFiles },
+		"write-test-schema":   func() { cfg.WriteTestSchema = flags.WriteTestSchema },
+		"plugin":              func() { cfg.Plugins = flags.Plugins },
+		"eg-language":         func() { cfg.EntityCfg.Language = flags.EntityCfg.Language },
+		"eg-indent":           func() { cfg.EntityCfg.Indent = flags.EntityCfg.Indent },
+		"eg-package":          func() { cfg.EntityCfg.PackageName = flags.EntityCfg.PackageName },
+		"eg-from-wueste":      func() { cfg.EntityCfg.FromWueste = flags.EntityCfg.FromWueste },
+		"eg-template-dir":     func() { cfg.EntityCfg.TemplateDir = flags.EntityCfg.TemplateDir },
+		"eg-layout":           func() { cfg.EntityCfg.Layout = flags.EntityCfg.Layout },
+		"eg-index":            func() { cfg.EntityCfg.Index = flags.EntityCfg.Index },
+		"eg-file-case":        func() { cfg.EntityCfg.FileCase = flags.EntityCfg.FileCase },
+		"eg-import-extension": func() { cfg.EntityCfg.ImportExtension = flags.EntityCfg.ImportExtension },
+		"eg-type-imports":     func() { cfg.EntityCfg.TypeImports = flags.EntityCfg.TypeImports },
+	}
+	fs.Visit(func(f *pflag.Flag) {
+		if override, found := overrides[f.Name]; found {
+			override()
+		}
+	})
+	if fs.NArg() > 0 {
+		cfg.InputFiles = flags.InputFiles
+	}
+}
+
+// projectTargets returns the targets of the project config with the
+// command line flags applied. Without a project config the flags are the
+// only target.
+func projectTargets(fs *pflag.FlagSet, flags *eg.GeneratorConfig, configFile string, names []string) rusty.Result[[]eg.ProjectTarget] {
+	if configFile == "" {
+		wd, err := os.Getwd()
+		if err == nil {
+			if found := eg.FindProjectConfig(wd); found.IsSome() {
+				configFile = found.Value()
+			}
+		}
+	}
+	if configFile == "" {
+		if len(names) > 0 {
+			return rusty.Err[[]eg.ProjectTarget](fmt.Errorf("--target needs a project config"))
+		}
+		return rusty.Ok([]eg.ProjectTarget{{Name: flags.EntityCfg.Language, Config: *flags}})
+	}
+	rProject := eg.LoadProjectConfig(configFile, *flags)
+	if rProject.IsErr() {
+		return rusty.Err[[]eg.ProjectTarget](rProject.Err())
+	}
+	selected := map[string]bool{}
+	for _, name := range names {
+		selected[name] = false
+	}
+	targets := []eg.ProjectTarget{}
+	for _, target := range rProject.Ok().Targets {
+		if _, found := selected[target.Name]; len(names) > 0 && !found {
+			continue
+		}
+		selected[target.Name] = true
+		overrideFlags(fs, flags, &target.Config)
+		targets = append(targets, target)
+	}
+	for _, name := range names {
+		if !selected[name] {
+			return rusty.Err[[]eg.ProjectTarget](fmt.Errorf("%s: unknown target %s", configFile, name))
+		}
+	}
+	return rusty.Ok(targets)
+}
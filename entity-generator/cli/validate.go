@@ -0,0 +1,139 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	eg "github.com/mabels/wueste/entity-generator"
+	"github.com/mabels/wueste/entity-generator/rusty"
+)
+
+func init() {
+	registerCommand(command{
+		name:    "validate",
+		args:    "--schema schema data...",
+		summary: "validate json documents against a schema",
+		run:     validateAction,
+	})
+}
+
+type validateResult struct {
+	File   string               `json:"file"`
+	Valid  bool                 `json:"valid"`
+	Errors []eg.ValidationError `json:"errors"`
+}
+
+// loadDocument reads a json or, by the file extension, a yaml document.
+func loadDocument(fname string) rusty.Result[any] {
+	bytes, err := os.ReadFile(fname)
+	if err != nil {
+		return rusty.Err[any](err)
+	}
+	var doc any
+	switch strings.ToLower(filepath.Ext(fname)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(bytes, &doc)
+	default:
+		err = json.Unmarshal(bytes, &doc)
+	}
+	if err != nil {
+		return rusty.Err[any](err)
+	}
+	return rusty.Ok(doc)
+}
+
+func validateAction(cmd command, args []string, vi versionInfo) int {
+	var schema string
+	var includeDirs []string
+	fs := newFlagSet(cmd)
+	fs.StringVar(&schema, "schema", "", "schema to validate against")
+	fs.StringArrayVar(&includeDirs, "include-dir", []string{}, "include directories")
+	df := addDiagnosticsFlags(fs)
+	if exit := parseFlags(fs, args); exit.IsSome() {
+		return exit.Value()
+	}
+	if schema == "" || fs.NArg() == 0 {
+		fs.Usage()
+		return ExitUsage
+	}
+	if !df.valid() {
+		return ExitUsage
+	}
+	rFiles := eg.ExpandInputFiles(fs.Args())
+	if rFiles.IsErr() {
+		fmt.Fprintln(stderr, rFiles.Err())
+		return ExitUsage
+	}
+
+	sl := eg.PropertyCtx{
+		Registry: eg.NewSchemaRegistry(eg.NewSchemaLoaderImpl(includeDirs...)),
+	}
+	prop := eg.LoadSchemaFile(sl, schema)
+	if prop.IsErr() {
+		df.report(locateDiagnostics(eg.AsDiagnostics(prop.Err())))
+		return ExitFailure
+	}
+
+	exit := ExitOk
+	results := []validateResult{}
+	for _, file := range rFiles.Ok() {
+		result := validateResult{File: file}
+		doc := loadDocument(file)
+		if doc.IsErr() {
+			result.Errors = []eg.ValidationError{{Path: "", Message: doc.Err().Error()}}
+		} else {
+			result.Errors = eg.Validate(prop.Ok(), doc.Ok())
+		}
+		result.Valid = len(result.Errors) == 0
+		if !result.Valid {
+			exit = ExitFailure
+		}
+		results = append(results, result)
+	}
+
+	// the results are the output of validate, they go to stdout
+	if !df.write(stdout, func(w io.Writer) error { return writeValidateResults(w, df.format, results) }) {
+		return ExitFailure
+	}
+	return exit
+}
+
+func writeValidateResults(w io.Writer, format string, results []validateResult) error {
+	switch format {
+	case "sarif":
+		ds := eg.Diagnostics{}
+		for _, result := range results {
+			for _, err := range result.Errors {
+				d := eg.NewDiagnostic(eg.DiagInvalidValue, "%s", err.Message)
+				d.File = result.File
+				d.Pointer = err.Path
+				ds = append(ds, d)
+			}
+		}
+		return writeDiagnostics(w, format, locateDiagnostics(ds))
+	case "json":
+		bytes, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(bytes))
+		return err
+	default:
+		for _, result := range results {
+			if result.Valid {
+				fmt.Fprintf(w, "%s: ok\n", result.File)
+				continue
+			}
+			for _, err := range result.Errors {
+				fmt.Fprintf(w, "%s%s\n", result.File, err.Error())
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	out, errOut := captureOutput(t)
+	dir := t.TempDir()
+	schema := filepath.Join(dir, "base.schema.json")
+	assert.NoError(t, os.WriteFile(schema, []byte(`{
+		"$id": "https://Base", "title": "Base", "type": "object",
+		"properties": { "name": { "type": "string" }, "count": { "type": "integer", "maximum": 3 } },
+		"required": ["name"]
+	}`), 0644))
+	data := filepath.Join(dir, "data")
+	assert.NoError(t, os.MkdirAll(data, 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(data, "a.json"), []byte(`{ "name": "a", "count": 1 }`), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(data, "b.yaml"), []byte("count: 4\n"), 0644))
+
+	assert.Equal(t, ExitOk, MainAction([]string{"validate", "--schema", schema, filepath.Join(data, "*.json")}, "", ""), errOut.String())
+	assert.Equal(t, filepath.Join(data, "a.json")+": ok\n", out.String())
+
+	out.Reset()
+	exit := MainAction([]string{"validate", "--schema", schema, filepath.Join(data, "a.json"), filepath.Join(data, "b.yaml")}, "", "")
+	assert.Equal(t, ExitFailure, exit)
+	b := filepath.Join(data, "b.yaml")
+	assert.Equal(t, filepath.Join(data, "a.json")+": ok\n"+
+		b+"#: missing required property name\n"+
+		b+"#/count: 4 is greater than the maximum 3\n", out.String())
+
+	out.Reset()
+	exit = MainAction([]string{"validate", "--diagnostics-format", "json", "--schema", schema, b}, "", "")
+	assert.Equal(t, ExitFailure, exit)
+	results := []validateResult{}
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &results))
+	assert.Equal(t, 1, len(results))
+	assert.False(t, results[0].Valid)
+	assert.Equal(t, "/count", results[0].Errors[1].Path)
+
+	assert.Equal(t, ExitUsage, MainAction([]string{"validate", filepath.Join(data, "a.json")}, "", ""))
+	assert.Equal(t, ExitUsage, MainAction([]string{"validate", "--diagnostics-format", "xml", "--schema", schema, b}, "", ""))
+}
@@ -0,0 +1,206 @@
+package cli
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"time"
+
+	eg "github.com/mabels/wueste/entity-generator"
+)
+
+type watchedInput struct {
+	file string
+	// absolute names of all files the schema was built from
+	deps map[string]bool
+	ok   bool
+	// schema is the last one which was generated
+	schema eg.Property
+}
+
+type watcher struct {
+	cfg       *eg.GeneratorConfig
+	generator generatorFn
+	project   projectGeneratorFn
+	plugins   *targetPlugins
+	sl        eg.PropertyCtx
+	inputs    []*watchedInput
+	mtimes    map[string]time.Time
+}
+
+func newWatcher(cfg *eg.GeneratorConfig, generator generatorFn) *watcher {
+	w := &watcher{
+		cfg:       cfg,
+		generator: generator,
+		sl: eg.PropertyCtx{
+			Registry: eg.NewSchemaRegistry(eg.NewSchemaLoaderImpl(cfg.IncludeDirs...)),
+		},
+		mtimes: map[string]time.Time{},
+	}
+	for _, file := range cfg.InputFiles {
+		deps := map[string]bool{}
+		abs, err := filepath.Abs(file)
+		if err == nil {
+			deps[abs] = true
+		}
+		w.inputs = append(w.inputs, &watchedInput{file: file, deps: deps})
+	}
+	return w
+}
+
+func interrupted() <-chan struct{} {
+	stop := make(chan struct{})
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt)
+	go func() {
+		<-sig
+		close(stop)
+	}()
+	return stop
+}
+
+func (w *watcher) generate(in *watchedInput) {
+	schema := generateFile(w.cfg, w.generator, w.sl, in.file)
+	if schema.IsErr() {
+		// keep the known dependencies, the schema is retried on their next change
+		in.ok = false
+		fmt.Fprintln(stderr, locateDiagnostics(eg.AsDiagnostics(schema.Err())).Error())
+		return
+	}
+	in.ok = true
+	in.schema = schema.Ok()
+	for _, fname := range eg.PropertyFileNames(schema.Ok()) {
+		in.deps[fname] = true
+	}
+}
+
+func (w *watcher) generateAll() {
+	w.sl.Registry.ResetOutputs()
+	for _, in := range w.inputs {
+		w.generate(in)
+	}
+	w.generateProject()
+	w.changedFiles()
+}
+
+// generateProject runs the project generator if all inputs are generated,
+// the files collected for the plugins are written in any case.
+func (w *watcher) generateProject() {
+	props := make([]eg.Property, 0, len(w.inputs))
+	complete := true
+	for _, in := range w.inputs {
+		if !in.ok {
+			complete = false
+			continue
+		}
+		props = append(props, in.schema)
+	}
+	if w.project != nil && complete {
+		if ds := generateProject(w.cfg, w.project, w.sl, props); len(ds) > 0 {
+			fmt.Fprintln(stderr, ds.Error())
+			complete = false
+		}
+	}
+	if w.plugins == nil {
+		return
+	}
+	if err := w.plugins.flush(w.cfg, props, complete); err != nil {
+		fmt.Fprintln(stderr, locateDiagnostics(eg.AsDiagnostics(err)).Error())
+	}
+}
+
+// watchedFiles are the dependencies of all inputs and the schemas in the
+// include directories, which could satisfy a ref which failed before.
+func (w *watcher) watchedFiles() []string {
+	files := map[string]bool{}
+	for _, in := range w.inputs {
+		for dep := range in.deps {
+			files[dep] = true
+		}
+	}
+	for _, dir := range w.cfg.IncludeDirs {
+		filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || !eg.IsSchemaFileName(path) {
+				return nil
+			}
+			abs, err := filepath.Abs(path)
+			if err == nil {
+				files[abs] = true
+			}
+			return nil
+		})
+	}
+	out := make([]string, 0, len(files))
+	for file := range files {
+		out = append(out, file)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func (w *watcher) changedFiles() []string {
+	changed := []string{}
+	for _, file := range w.watchedFiles() {
+		var mtime time.Time
+		stat, err := os.Stat(file)
+		if err == nil {
+			mtime = stat.ModTime()
+		}
+		prev, found := w.mtimes[file]
+		if found && !prev.Equal(mtime) {
+			changed = append(changed, file)
+		}
+		w.mtimes[file] = mtime
+	}
+	return changed
+}
+
+// step regenerates the inputs affected by the files changed since the
+// last step and returns them.
+func (w *watcher) step() []string {
+	changed := w.changedFiles()
+	if len(changed) == 0 {
+		return nil
+	}
+	for _, file := range changed {
+		fmt.Fprintf(stdout, "Changed: %s\n", file)
+		w.sl.Registry.Invalidate(file)
+	}
+	regenerated := []string{}
+	w.sl.Registry.ResetOutputs()
+	for _, in := range w.inputs {
+		affected := !in.ok
+		for _, file := range changed {
+			affected = affected || in.deps[file]
+		}
+		if affected {
+			w.generate(in)
+			regenerated = append(regenerated, in.file)
+		}
+	}
+	w.generateProject()
+	return regenerated
+}
+
+func runWatchers(ws []*watcher, interval time.Duration, stop <-chan struct{}) int {
+	inputs := 0
+	for _, w := range ws {
+		inputs += len(w.inputs)
+	}
+	fmt.Fprintf(stdout, "Watching %d schemas\n", inputs)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return ExitOk
+		case <-ticker.C:
+			for _, w := range ws {
+				w.step()
+			}
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	eg "github.com/mabels/wueste/entity-generator"
+	"github.com/mabels/wueste/entity-generator/plugin"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeSchema(t *testing.T, fname string, content string, mtime time.Time) {
+	assert.NoError(t, os.WriteFile(fname, []byte(content), 0644))
+	assert.NoError(t, os.Chtimes(fname, mtime, mtime))
+}
+
+func TestWatchRegeneratesAffected(t *testing.T) {
+	captureOutput(t)
+	dir := t.TempDir()
+	mtime := time.Now().Add(-time.Hour)
+	writeSchema(t, filepath.Join(dir, "sub.schema.json"), `{
+		"$id": "https://Sub", "title": "Sub", "type": "object",
+		"properties": { "name": { "type": "string" } }
+	}`, mtime)
+	writeSchema(t, filepath.Join(dir, "base.schema.json"), `{
+		"$id": "https://Base", "title": "Base", "type": "object",
+		"properties": { "sub": { "$ref": "file://sub.schema.json" } }
+	}`, mtime)
+	writeSchema(t, filepath.Join(dir, "other.schema.json"), `{
+		"$id": "https://Other", "title": "Other", "type": "object",
+		"properties": { "name": { "type": "string" } }
+	}`, mtime)
+
+	cfg := &eg.GeneratorConfig{
+		OutputDir:  filepath.Join(dir, "out"),
+		InputFiles: []string{filepath.Join(dir, "base.schema.json"), filepath.Join(dir, "other.schema.json")},
+		EntityCfg:  eg.Config{Indent: "  ", FromWueste: "wueste"},
+	}
+	w := newWatcher(cfg, generators["ts"])
+	w.generateAll()
+	assert.Empty(t, w.step())
+
+	writeSchema(t, filepath.Join(dir, "sub.schema.json"), `{
+		"$id": "https://Sub", "title": "Sub", "type": "object",
+		"properties": { "name": { "type": "string" }, "more": { "type": "string" } }
+	}`, mtime.Add(time.Minute))
+	assert.Equal(t, []string{filepath.Join(dir, "base.schema.json")}, w.step())
+	bytes, err := os.ReadFile(filepath.Join(dir, "out", "base$sub.ts"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(bytes), "readonly more?: string")
+
+	// a broken schema does not stop the watcher and is retried
+	writeSchema(t, filepath.Join(dir, "other.schema.json"), `{ "type": `, mtime.Add(2*time.Minute))
+	assert.Equal(t, []string{filepath.Join(dir, "other.schema.json")}, w.step())
+	assert.False(t, w.inputs[1].ok)
+	writeSchema(t, filepath.Join(dir, "other.schema.json"), `{
+		"$id": "https://Other", "title": "Other", "type": "object",
+		"properties": { "fixed": { "type": "string" } }
+	}`, mtime.Add(3*time.Minute))
+	assert.Equal(t, []string{filepath.Join(dir, "other.schema.json")}, w.step())
+	assert.True(t, w.inputs[1].ok)
+}
+
+func TestWatchPluginsFlushWithInvalidInput(t *testing.T) {
+	captureOutput(t)
+	dir := t.TempDir()
+	mtime := time.Now().Add(-time.Hour)
+	writeSchema(t, filepath.Join(dir, "base.schema.json"), `{
+		"$id": "https://Base", "title": "Base", "type": "object",
+		"properties": { "name": { "type": "string" } }
+	}`, mtime)
+	writeSchema(t, filepath.Join(dir, "other.schema.json"), `{ "type": `, mtime)
+	pluginFile := filepath.Join(dir, "plugin.go")
+	assert.NoError(t, os.WriteFile(pluginFile, []byte(`package registry
+
+import (
+	"path/filepath"
+
+	"github.com/mabels/wueste/entity-generator/plugin"
+)
+
+func Generate(ctx *plugin.Context) error {
+	ctx.Files.Append(filepath.Join(ctx.Config.OutputDir, "registry.txt"), ctx.Entity.Id()+"\n")
+	return nil
+}
+`), 0644))
+
+	cfg := &eg.GeneratorConfig{
+		OutputDir:  filepath.Join(dir, "out"),
+		InputFiles: []string{filepath.Join(dir, "base.schema.json"), filepath.Join(dir, "other.schema.json")},
+		EntityCfg:  eg.Config{Indent: "  ", FromWueste: "wueste"},
+	}
+	rPlugins := loadPlugins(map[string]*plugin.Plugin{}, []string{pluginFile})
+	assert.False(t, rPlugins.IsErr())
+	tp := rPlugins.Ok().Value()
+	w := newWatcher(cfg, tp.generator(generators["ts"]))
+	w.project = tp.project(projectGenerators["ts"])
+	w.plugins = tp
+	assert.NoError(t, os.MkdirAll(cfg.OutputDir, 0755))
+	w.generateAll()
+
+	// the valid input is written, the plugins wait for all inputs
+	_, err := os.Stat(filepath.Join(dir, "out", "base.ts"))
+	assert.NoError(t, err)
+	_, err = os.Stat(filepath.Join(dir, "out", "registry.txt"))
+	assert.True(t, os.IsNotExist(err))
+
+	writeSchema(t, filepath.Join(dir, "other.schema.json"), `{
+		"$id": "https://Other", "title": "Other", "type": "object",
+		"properties": { "name": { "type": "string" } }
+	}`, mtime.Add(time.Minute))
+	assert.Equal(t, []string{filepath.Join(dir, "other.schema.json")}, w.step())
+	bytes, err := os.ReadFile(filepath.Join(dir, "out", "registry.txt"))
+	assert.NoError(t, err)
+	assert.Equal(t, "https://Base\nhttps://Other\n", string(bytes))
+}
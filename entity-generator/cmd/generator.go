@@ -3,7 +3,7 @@ package main
 import (
 	"os"
 
-	ts "github.com/mabels/wueste/entity-generator/ts"
+	"github.com/mabels/wueste/entity-generator/cli"
 )
 
 // GitCommit is injected during compile time
@@ -13,5 +13,5 @@ var GitCommit string
 var Version string
 
 func main() {
-	ts.MainAction(os.Args[1:], Version, GitCommit)
+	os.Exit(cli.MainAction(os.Args[1:], Version, GitCommit))
 }
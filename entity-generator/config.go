@@ -1,15 +1,66 @@
 package entity_generator
 
-import "github.com/spf13/pflag"
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
 
 type Config struct {
 	Language    string
 	Indent      string
 	PackageName string
 	FromWueste  string
+	// TemplateDir are the templates of the language template
+	TemplateDir string
+	// Layout of the output files, flat or mirror of the schema directories
+	Layout string
+	// Index writes an index file which exports all entities
+	Index bool
+	// FileCase of the output file names: lower, kebab, snake or as-is
+	FileCase string
+	// ImportExtension of the imports between the entities: none, .js or .ts
+	ImportExtension string
+	// TypeImports writes import type for the imports which are only types
+	TypeImports bool
 	// FromResult  string
 }
 
+// the values of the Layout, FileCase and ImportExtension options
+var (
+	Layouts          = []string{"flat", "mirror"}
+	FileCases        = []string{"lower", "kebab", "snake", "as-is"}
+	ImportExtensions = []string{"none", ".js", ".ts"}
+)
+
+// checkChoice returns an error if value is not one of choices, empty is
+// the default.
+func checkChoice(name string, value string, choices []string) error {
+	if value == "" {
+		return nil
+	}
+	for _, choice := range choices {
+		if value == choice {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown %s %s, expected one of %s", name, value, strings.Join(choices, ", "))
+}
+
+// Validate checks the options which have a fixed set of values.
+func (cfg *Config) Validate() error {
+	if err := checkChoice("layout", cfg.Layout, Layouts); err != nil {
+		return err
+	}
+	if err := checkChoice("file case", cfg.FileCase, FileCases); err != nil {
+		return err
+	}
+	return checkChoice("import extension", cfg.ImportExtension, ImportExtensions)
+}
+
 type GeneratorConfig struct {
 	IncludeDirs     []string
 	OutputDir       string
@@ -17,13 +68,44 @@ type GeneratorConfig struct {
 	EntityCfg       Config
 	WriteTestSchema bool
 	Version         bool
+	// Plugins are go files which are run after the generator
+	Plugins []string
+	// Output defaults to DiskOutput
+	Output Output
+	// Log receives the progress of the generators, defaults to stdout
+	Log io.Writer
+}
+
+func (cfg *GeneratorConfig) Logf(format string, args ...any) {
+	var w io.Writer = os.Stdout
+	if cfg.Log != nil {
+		w = cfg.Log
+	}
+	fmt.Fprintf(w, format, args...)
+}
+
+func (cfg *GeneratorConfig) WriteFile(fname string, content []byte) error {
+	if cfg.Output == nil {
+		return DiskOutput{}.WriteFile(fname, content)
+	}
+	return cfg.Output.WriteFile(fname, content)
 }
 
 func FromArgs(prefix string, cfg *Config) *Config {
-	pflag.StringVar(&cfg.Language, prefix+"language", "go", "Language to generate entity for")
-	pflag.StringVar(&cfg.Indent, prefix+"indent", "  ", "one indent level")
-	pflag.StringVar(&cfg.PackageName, prefix+"package", "please_set_this", "Package name")
-	pflag.StringVar(&cfg.FromWueste, prefix+"from-wueste", "wueste/wueste", "Path to wueste")
-	// pflag.StringVar(&cfg.FromResult, prefix+"from-result", "wueste/wueste", "Path to result")
+	return FromFlagSet(pflag.CommandLine, prefix, cfg)
+}
+
+func FromFlagSet(fs *pflag.FlagSet, prefix string, cfg *Config) *Config {
+	fs.StringVar(&cfg.Language, prefix+"language", "ts", "Language to generate entity for")
+	fs.StringVar(&cfg.Indent, prefix+"indent", "  ", "one indent level")
+	fs.StringVar(&cfg.PackageName, prefix+"package", "please_set_this", "Package name")
+	fs.StringVar(&cfg.FromWueste, prefix+"from-wueste", "wueste/wueste", "Path to wueste")
+	fs.StringVar(&cfg.TemplateDir, prefix+"template-dir", "", "directory of the templates of the language template")
+	fs.StringVar(&cfg.Layout, prefix+"layout", "flat", "layout of the output files: flat or mirror of the directories of the input files")
+	fs.BoolVar(&cfg.Index, prefix+"index", false, "write an index file which exports all entities")
+	fs.StringVar(&cfg.FileCase, prefix+"file-case", "lower", "case of the output file names: lower, kebab, snake or as-is")
+	fs.StringVar(&cfg.ImportExtension, prefix+"import-extension", "none", "extension of the imports between the entities: none, .js or .ts")
+	fs.BoolVar(&cfg.TypeImports, prefix+"type-imports", false, "use import type for the imports which are only types")
+	// fs.StringVar(&cfg.FromResult, prefix+"from-result", "wueste/wueste", "Path to result")
 	return cfg
 }
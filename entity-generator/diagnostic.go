@@ -0,0 +1,360 @@
+package entity_generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mabels/wueste/entity-generator/rusty"
+)
+
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityNote    Severity = "note"
+)
+
+// the codes of the diagnostics
+const (
+	DiagSchemaError       = "schema-error"
+	DiagInvalidJson       = "invalid-json"
+	DiagInvalidRef        = "invalid-ref"
+	DiagRefNotFound       = "ref-not-found"
+	DiagMissingType       = "missing-type"
+	DiagInvalidType       = "invalid-type"
+	DiagMissingId         = "missing-id"
+	DiagInvalidProperties = "invalid-properties"
+	DiagInvalidRequired   = "invalid-required"
+	DiagInvalidItems      = "invalid-items"
+	DiagDuplicateProperty = "duplicate-property"
+	DiagGenerateFailed    = "generate-failed"
+	DiagInvalidValue      = "invalid-value"
+	DiagRefCycle          = "ref-cycle"
+	DiagDialectKeyword    = "dialect-keyword"
+	DiagUnknownDialect    = "unknown-dialect"
+	DiagUnknownExtension  = "unknown-extension"
+	DiagInvalidExtension  = "invalid-extension"
+	DiagSchemaChange      = "schema-change"
+	DiagOutputCollision   = "output-collision"
+)
+
+// Diagnostic is a problem of a schema, the Pointer is the JSON pointer
+// into File. Line and Column are 1-based and 0 if unknown. Cause is the
+// error which lead to the problem if there is one.
+type Diagnostic struct {
+	Severity Severity `json:"severity"`
+	Code     string   `json:"code"`
+	Message  string   `json:"message"`
+	File     string   `json:"file,omitempty"`
+	Pointer  string   `json:"pointer"`
+	Line     int      `json:"line,omitempty"`
+	Column   int      `json:"column,omitempty"`
+	Cause    error    `json:"-"`
+}
+
+func NewDiagnostic(code string, format string, args ...any) Diagnostic {
+	return Diagnostic{
+		Severity: SeverityError,
+		Code:     code,
+		Message:  fmt.Sprintf(format, args...),
+	}
+}
+
+func (d Diagnostic) wrap(cause error) Diagnostic {
+	d.Cause = cause
+	return d
+}
+
+func (d Diagnostic) at(pointer string) Diagnostic {
+	d.Pointer = pointer
+	return d
+}
+
+// atSource sets line and column from the position of the member in the
+// schema file, the file itself is assigned by InFile.
+func (d Diagnostic) atSource(pos rusty.Optional[SourcePosition]) Diagnostic {
+	if pos.IsSome() {
+		d.Line, d.Column = pos.Value().Line, pos.Value().Column
+	}
+	return d
+}
+
+func (d Diagnostic) Location() string {
+	loc := d.File
+	if d.Line > 0 {
+		loc = fmt.Sprintf("%s:%d:%d", loc, d.Line, d.Column)
+	}
+	if d.Pointer != "" {
+		loc += "#" + d.Pointer
+	}
+	return loc
+}
+
+func (d Diagnostic) Error() string {
+	msg := fmt.Sprintf("%s[%s]: %s", d.Severity, d.Code, d.Message)
+	if loc := d.Location(); loc != "" {
+		msg = loc + ": " + msg
+	}
+	return msg
+}
+
+func (d Diagnostic) Unwrap() error {
+	return d.Cause
+}
+
+// Is matches a *SchemaError target by its Code, Pointer and File, empty
+// fields of target match everything.
+func (d Diagnostic) Is(target error) bool {
+	t, ok := target.(*SchemaError)
+	if !ok {
+		return false
+	}
+	return (t.Code == "" || t.Code == d.Code) &&
+		(t.Pointer == "" || t.Pointer == d.Pointer) &&
+		(t.File == "" || t.File == d.File)
+}
+
+// As assigns the diagnostic to a **SchemaError target, the Diagnostic is a
+// value so every call returns a new SchemaError.
+func (d Diagnostic) As(target any) bool {
+	se, ok := target.(**SchemaError)
+	if ok {
+		*se = &SchemaError{Diagnostic: d}
+	}
+	return ok
+}
+
+// Diagnostics is the error of the loaders and builders, errors.Is and
+// errors.As look into every diagnostic.
+type Diagnostics []Diagnostic
+
+func (ds Diagnostics) Error() string {
+	out := make([]string, 0, len(ds))
+	for _, d := range ds {
+		out = append(out, d.Error())
+	}
+	return strings.Join(out, "\n")
+}
+
+func (ds Diagnostics) Is(target error) bool {
+	for _, d := range ds {
+		if errors.Is(d, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As assigns the first diagnostic which matches target, so errors.As
+// reaches only the first of several diagnostics of the same type. All of
+// them are returned by AsDiagnostics, or errors.As with a *Diagnostics
+// target.
+func (ds Diagnostics) As(target any) bool {
+	for _, d := range ds {
+		if errors.As(d, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func (ds Diagnostics) HasErrors() bool {
+	for _, d := range ds {
+		if d.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// AsDiagnostics flattens the diagnostics of err, other errors are
+// reported as a schema-error.
+func AsDiagnostics(err error) Diagnostics {
+	if err == nil {
+		return Diagnostics{}
+	}
+	var ds Diagnostics
+	if errors.As(err, &ds) {
+		return ds
+	}
+	var d Diagnostic
+	if errors.As(err, &d) {
+		return Diagnostics{d}
+	}
+	var se *SchemaError
+	if errors.As(err, &se) {
+		// a SchemaError without severity is an error
+		d := se.Diagnostic
+		if d.Severity == "" {
+			d.Severity = SeverityError
+		}
+		return Diagnostics{d}
+	}
+	return Diagnostics{NewDiagnostic(DiagSchemaError, "%s", strings.TrimSpace(err.Error())).wrap(err)}
+}
+
+// joinDiagnostics is used by the builders to return their errors.
+func joinDiagnostics(errs []error) Diagnostics {
+	ds := Diagnostics{}
+	for _, err := range errs {
+		ds = append(ds, AsDiagnostics(err)...)
+	}
+	return ds
+}
+
+// inPointer prefixes the pointers of the diagnostics which have no file
+// yet, they are relative to the json the current builder reads.
+func (ds Diagnostics) inPointer(prefix string) Diagnostics {
+	out := make(Diagnostics, 0, len(ds))
+	for _, d := range ds {
+		if d.File == "" {
+			d.Pointer = prefix + d.Pointer
+		}
+		out = append(out, d)
+	}
+	return out
+}
+
+// atSource locates the diagnostics of the current json which have no
+// position yet at pos.
+func (ds Diagnostics) atSource(pos rusty.Optional[SourcePosition]) Diagnostics {
+	out := make(Diagnostics, 0, len(ds))
+	for _, d := range ds {
+		if d.File == "" && d.Line == 0 {
+			d = d.atSource(pos)
+		}
+		out = append(out, d)
+	}
+	return out
+}
+
+// InFile assigns the diagnostics without a file to fname.
+func (ds Diagnostics) InFile(fname string) Diagnostics {
+	out := make(Diagnostics, 0, len(ds))
+	for _, d := range ds {
+		if d.File == "" {
+			d.File = fname
+		}
+		out = append(out, d)
+	}
+	return out
+}
+
+// Locate fills line and column from the JSON pointers of the diagnostics.
+func (ds Diagnostics) Locate(readFile func(fname string) ([]byte, error)) Diagnostics {
+	files := map[string][]byte{}
+	out := make(Diagnostics, 0, len(ds))
+	for _, d := range ds {
+		if d.File != "" && d.Line == 0 {
+			data, found := files[d.File]
+			if !found {
+				data, _ = readFile(d.File)
+				files[d.File] = data
+			}
+			if line, column, ok := LocateJSONPointer(data, d.Pointer); ok {
+				d.Line, d.Column = line, column
+			}
+		}
+		out = append(out, d)
+	}
+	return out
+}
+
+func EscapeJSONPointer(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+func offsetToLineColumn(data []byte, offset int) (int, int) {
+	if offset > len(data) {
+		offset = len(data)
+	}
+	line := bytes.Count(data[:offset], []byte("\n")) + 1
+	column := offset - bytes.LastIndexByte(data[:offset], '\n')
+	return line, column
+}
+
+var errPointerFound = errors.New("pointer found")
+
+type pointerLocator struct {
+	data   []byte
+	dec    *json.Decoder
+	target string
+	offset int
+}
+
+// nextToken returns the offset of the next token after the delimiters the
+// decoder skips.
+func (l *pointerLocator) nextToken() int {
+	off := int(l.dec.InputOffset())
+	for off < len(l.data) {
+		switch l.data[off] {
+		case ' ', '\t', '\r', '\n', ',', ':':
+			off++
+		default:
+			return off
+		}
+	}
+	return off
+}
+
+func (l *pointerLocator) value(path string) error {
+	start := l.nextToken()
+	if path == l.target {
+		l.offset = start
+		return errPointerFound
+	}
+	tok, err := l.dec.Token()
+	if err != nil {
+		return err
+	}
+	switch tok {
+	case json.Delim('{'):
+		for l.dec.More() {
+			keyStart := l.nextToken()
+			key, err := l.dec.Token()
+			if err != nil {
+				return err
+			}
+			keyPath := path + "/" + EscapeJSONPointer(fmt.Sprint(key))
+			if keyPath == l.target {
+				// members are located at their key
+				l.offset = keyStart
+				return errPointerFound
+			}
+			if err := l.value(keyPath); err != nil {
+				return err
+			}
+		}
+		_, err = l.dec.Token()
+	case json.Delim('['):
+		for i := 0; l.dec.More(); i++ {
+			if err := l.value(fmt.Sprintf("%s/%d", path, i)); err != nil {
+				return err
+			}
+		}
+		_, err = l.dec.Token()
+	}
+	return err
+}
+
+// LocateJSONPointer returns the 1-based line and column of the value the
+// pointer refers to, members of objects are located at their key.
+func LocateJSONPointer(data []byte, pointer string) (int, int, bool) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return 0, 0, false
+	}
+	l := &pointerLocator{
+		data:   data,
+		dec:    json.NewDecoder(bytes.NewReader(data)),
+		target: pointer,
+	}
+	if l.value("") != errPointerFound {
+		return 0, 0, false
+	}
+	line, column := offsetToLineColumn(data, l.offset)
+	return line, column, true
+}
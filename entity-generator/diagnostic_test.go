@@ -0,0 +1,97 @@
+package entity_generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocateJSONPointer(t *testing.T) {
+	data := []byte(`{
+  "a": { "b/c": [1, { "d": true }] },
+  "e": "f"
+}`)
+	for pointer, pos := range map[string][2]int{
+		"":            {1, 1},
+		"/a":          {2, 3},
+		"/a/b~1c":     {2, 10},
+		"/a/b~1c/0":   {2, 18},
+		"/a/b~1c/1":   {2, 21},
+		"/a/b~1c/1/d": {2, 23},
+		"/e":          {3, 3},
+	} {
+		line, column, ok := LocateJSONPointer(data, pointer)
+		assert.True(t, ok, pointer)
+		assert.Equal(t, pos, [2]int{line, column}, pointer)
+	}
+	_, _, ok := LocateJSONPointer(data, "/x")
+	assert.False(t, ok)
+	_, _, ok = LocateJSONPointer([]byte("{ broken"), "/x")
+	assert.False(t, ok)
+}
+
+func TestBuilderDiagnostics(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "sub.schema.json"), []byte(`{
+	"$id": "https://Sub",
+	"type": "object",
+	"properties": {
+		"list": { "type": "array" }
+	},
+	"required": "list"
+}`), 0644))
+	base := filepath.Join(dir, "base.schema.json")
+	assert.NoError(t, os.WriteFile(base, []byte(`{
+	"$id": "https://Base",
+	"type": "object",
+	"properties": {
+		"sub": { "$ref": "file://sub.schema.json" },
+		"missing": { "$ref": "file://missing.schema.json" },
+		"untyped": { "description": "no type" }
+	}
+}`), 0644))
+	ctx := PropertyCtx{Registry: NewSchemaRegistry(NewSchemaLoaderImpl())}
+	js := NewJSONDict()
+	js.Set("$ref", "file://"+base)
+	err := NewPropertiesBuilder(ctx).FromJson(js).Build().Err()
+	// the positions are recorded by the loader
+	ds := AsDiagnostics(err)
+	assert.Equal(t, 4, len(ds), ds.Error())
+
+	sub := filepath.Join(dir, "sub.schema.json")
+	assert.Equal(t, DiagInvalidItems, ds[0].Code)
+	assert.Equal(t, sub, ds[0].File)
+	assert.Equal(t, "/properties/list/items", ds[0].Pointer)
+	// the missing items are located at the array property
+	assert.Equal(t, sub+":5:3#/properties/list/items: error[invalid-items]: Array needs items", ds[0].Error())
+
+	assert.Equal(t, DiagInvalidRequired, ds[1].Code)
+	assert.Equal(t, "/required", ds[1].Pointer)
+	assert.Equal(t, sub+":7:2#/required: error[invalid-required]: required[https://Sub] is not []string", ds[1].Error())
+
+	assert.Equal(t, DiagRefNotFound, ds[2].Code)
+	assert.Equal(t, base, ds[2].File)
+	assert.Equal(t, "/properties/missing/$ref", ds[2].Pointer)
+	assert.Equal(t, 6, ds[2].Line)
+
+	assert.Equal(t, DiagMissingType, ds[3].Code)
+	assert.Equal(t, "/properties/untyped", ds[3].Pointer)
+	assert.Equal(t, 7, ds[3].Line)
+	assert.True(t, ds.HasErrors())
+}
+
+func TestInvalidJsonDiagnostic(t *testing.T) {
+	dir := t.TempDir()
+	fname := filepath.Join(dir, "broken.schema.json")
+	assert.NoError(t, os.WriteFile(fname, []byte("{\n  \"type\": \"object\",\n  oops\n}"), 0644))
+	ctx := PropertyCtx{Registry: NewSchemaRegistry(NewSchemaLoaderImpl())}
+	js := NewJSONDict()
+	js.Set("$ref", "file://"+fname)
+	ds := AsDiagnostics(NewPropertiesBuilder(ctx).FromJson(js).Build().Err())
+	assert.Equal(t, 1, len(ds))
+	assert.Equal(t, DiagInvalidJson, ds[0].Code)
+	assert.Equal(t, fname, ds[0].File)
+	assert.Equal(t, 3, ds[0].Line)
+}
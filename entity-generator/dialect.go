@@ -0,0 +1,330 @@
+package entity_generator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Dialect is a draft of JSON schema, later drafts are greater.
+type Dialect int
+
+const (
+	Draft04 Dialect = iota
+	Draft06
+	Draft07
+	Draft2019_09
+	Draft2020_12
+)
+
+// DefaultDialect is the dialect of schemas without $schema.
+const DefaultDialect = Draft2020_12
+
+// dialectEnd is the until of the keywords which are valid in the latest draft.
+const dialectEnd = Draft2020_12 + 1
+
+var dialectURIs = []string{
+	Draft04:      "http://json-schema.org/draft-04/schema#",
+	Draft06:      "http://json-schema.org/draft-06/schema#",
+	Draft07:      "http://json-schema.org/draft-07/schema#",
+	Draft2019_09: "https://json-schema.org/draft/2019-09/schema",
+	Draft2020_12: "https://json-schema.org/draft/2020-12/schema",
+}
+
+var dialectNames = []string{
+	Draft04:      "draft-04",
+	Draft06:      "draft-06",
+	Draft07:      "draft-07",
+	Draft2019_09: "2019-09",
+	Draft2020_12: "2020-12",
+}
+
+func (d Dialect) URI() string {
+	return dialectURIs[d]
+}
+
+func (d Dialect) String() string {
+	return dialectNames[d]
+}
+
+func normalizeDialectURI(uri string) string {
+	uri = strings.TrimSpace(uri)
+	uri = strings.TrimPrefix(strings.TrimPrefix(uri, "https://"), "http://")
+	return strings.TrimSuffix(uri, "#")
+}
+
+// ParseDialect returns the dialect of a $schema, the scheme and an empty
+// fragment are ignored. Unknown schemas are the DefaultDialect.
+func ParseDialect(schema string) (Dialect, bool) {
+	norm := normalizeDialectURI(schema)
+	for d, uri := range dialectURIs {
+		if normalizeDialectURI(uri) == norm {
+			return Dialect(d), true
+		}
+	}
+	return DefaultDialect, false
+}
+
+// dialectKeyword is valid from the draft since until before the draft until.
+type dialectKeyword struct {
+	since Dialect
+	until Dialect
+	hint  string
+}
+
+// dialectKeywords are the keywords which are not part of every draft.
+var dialectKeywords = map[string]dialectKeyword{
+	"id":                    {Draft04, Draft06, "use $id"},
+	"$id":                   {Draft06, dialectEnd, "use id"},
+	"definitions":           {Draft04, Draft2019_09, "use $defs"},
+	"dependencies":          {Draft04, Draft2019_09, "use dependentRequired or dependentSchemas"},
+	"additionalItems":       {Draft04, Draft2020_12, "use prefixItems and items"},
+	"const":                 {Draft06, dialectEnd, ""},
+	"contains":              {Draft06, dialectEnd, ""},
+	"propertyNames":         {Draft06, dialectEnd, ""},
+	"examples":              {Draft06, dialectEnd, ""},
+	"if":                    {Draft07, dialectEnd, ""},
+	"then":                  {Draft07, dialectEnd, ""},
+	"else":                  {Draft07, dialectEnd, ""},
+	"$comment":              {Draft07, dialectEnd, ""},
+	"readOnly":              {Draft07, dialectEnd, ""},
+	"writeOnly":             {Draft07, dialectEnd, ""},
+	"contentMediaType":      {Draft07, dialectEnd, ""},
+	"contentEncoding":       {Draft07, dialectEnd, ""},
+	"$defs":                 {Draft2019_09, dialectEnd, "use definitions"},
+	"$anchor":               {Draft2019_09, dialectEnd, ""},
+	"$vocabulary":           {Draft2019_09, dialectEnd, ""},
+	"dependentRequired":     {Draft2019_09, dialectEnd, "use dependencies"},
+	"dependentSchemas":      {Draft2019_09, dialectEnd, "use dependencies"},
+	"unevaluatedItems":      {Draft2019_09, dialectEnd, ""},
+	"unevaluatedProperties": {Draft2019_09, dialectEnd, ""},
+	"minContains":           {Draft2019_09, dialectEnd, ""},
+	"maxContains":           {Draft2019_09, dialectEnd, ""},
+	"deprecated":            {Draft2019_09, dialectEnd, ""},
+	"contentSchema":         {Draft2019_09, dialectEnd, ""},
+	"$recursiveRef":         {Draft2019_09, Draft2020_12, "use $dynamicRef"},
+	"$recursiveAnchor":      {Draft2019_09, Draft2020_12, "use $dynamicAnchor"},
+	"prefixItems":           {Draft2020_12, dialectEnd, "use an array in items"},
+	"$dynamicRef":           {Draft2020_12, dialectEnd, "use $recursiveRef"},
+	"$dynamicAnchor":        {Draft2020_12, dialectEnd, "use $recursiveAnchor"},
+}
+
+// the keywords which contain a schema, a dict of schemas or a list of
+// schemas. items is a list before 2020-12.
+var (
+	subschemaKeywords = []string{
+		"items", "additionalItems", "additionalProperties", "not", "if", "then", "else",
+		"contains", "propertyNames", "unevaluatedItems", "unevaluatedProperties", "contentSchema",
+	}
+	subschemaDictKeywords = []string{
+		"properties", "patternProperties", "definitions", "$defs", "dependencies", "dependentSchemas",
+	}
+	subschemaListKeywords = []string{"allOf", "anyOf", "oneOf", "prefixItems", "items"}
+)
+
+// walkDialect calls fn for every schema in js with the dialect in effect,
+// a $schema in a subschema changes the dialect of the subschema.
+func walkDialect(js JSONDict, pointer string, d Dialect, fn func(node JSONDict, pointer string, d Dialect)) {
+	if schema, ok := js.Get("$schema").(string); ok {
+		if sd, known := ParseDialect(schema); known {
+			d = sd
+		}
+	}
+	fn(js, pointer, d)
+	for _, kw := range subschemaKeywords {
+		if child, ok := AsJSONDict(js.Get(kw)); ok {
+			walkDialect(child, pointer+"/"+kw, d, fn)
+			js.Set(kw, child)
+		}
+	}
+	for _, kw := range subschemaDictKeywords {
+		dict, ok := AsJSONDict(js.Get(kw))
+		if !ok {
+			continue
+		}
+		for _, k := range dict.Keys() {
+			if child, ok := AsJSONDict(dict.Get(k)); ok {
+				walkDialect(child, pointer+"/"+kw+"/"+EscapeJSONPointer(k), d, fn)
+				dict.Set(k, child)
+			}
+		}
+		js.Set(kw, dict)
+	}
+	for _, kw := range subschemaListKeywords {
+		list, ok := js.Get(kw).([]any)
+		if !ok {
+			continue
+		}
+		for i, item := range list {
+			if child, ok := AsJSONDict(item); ok {
+				walkDialect(child, fmt.Sprintf("%s/%s/%d", pointer, kw, i), d, fn)
+				list[i] = child
+			}
+		}
+	}
+}
+
+func dialectWarning(fname string, node JSONDict, pointer string, key string, format string, args ...any) Diagnostic {
+	d := NewDiagnostic(DiagDialectKeyword, format, args...).at(pointer + "/" + EscapeJSONPointer(key)).atSource(keyPosition(node, key))
+	d.Severity = SeverityWarning
+	d.File = fname
+	return d
+}
+
+// CheckDialect warns about the keywords in the schema js of the file fname
+// which are not valid in the dialect of its $schema.
+func CheckDialect(fname string, js JSONDict) Diagnostics {
+	ds := Diagnostics{}
+	walkDialect(js, "", DefaultDialect, func(node JSONDict, pointer string, d Dialect) {
+		if schema, ok := node.Get("$schema").(string); ok {
+			if _, known := ParseDialect(schema); !known {
+				w := dialectWarning(fname, node, pointer, "$schema", "unknown $schema %s, read as %s", schema, d)
+				w.Code = DiagUnknownDialect
+				ds = append(ds, w)
+			}
+		}
+		keys := append([]string{}, node.Keys()...)
+		sort.Strings(keys)
+		for _, k := range keys {
+			kw, found := dialectKeywords[k]
+			if !found || (d >= kw.since && d < kw.until) {
+				continue
+			}
+			msg := fmt.Sprintf("%s is not a keyword of %s", k, d)
+			if kw.hint != "" {
+				msg += ", " + kw.hint
+			}
+			ds = append(ds, dialectWarning(fname, node, pointer, k, "%s", msg))
+		}
+		for _, k := range []string{"exclusiveMinimum", "exclusiveMaximum"} {
+			v, found := node.Lookup(k)
+			if !found {
+				continue
+			}
+			if _, isBool := v.(bool); isBool && d > Draft04 {
+				ds = append(ds, dialectWarning(fname, node, pointer, k, "boolean %s is draft-04, use a number in %s", k, d))
+			} else if !isBool && d == Draft04 {
+				ds = append(ds, dialectWarning(fname, node, pointer, k, "%s is a boolean in draft-04", k))
+			}
+		}
+		if _, isList := node.Get("items").([]any); isList && d >= Draft2020_12 {
+			ds = append(ds, dialectWarning(fname, node, pointer, "items", "items is not a list in %s, use prefixItems", d))
+		}
+	})
+	return ds
+}
+
+type editableDict interface {
+	JSONDict
+	JSONDictEditor
+}
+
+// migrateKeywords rewrites the keywords of the schema node from the dialect
+// d to 2020-12 and returns the number of rewritten keywords.
+func migrateKeywords(node editableDict, d Dialect) int {
+	changes := 0
+	rename := func(from, to string) {
+		if _, found := node.Lookup(from); !found {
+			return
+		}
+		if _, found := node.Lookup(to); found {
+			return
+		}
+		node.Rename(from, to)
+		changes++
+	}
+	if d < Draft06 {
+		rename("id", "$id")
+		for _, bound := range [][2]string{{"exclusiveMinimum", "minimum"}, {"exclusiveMaximum", "maximum"}} {
+			exclusive, isBool := node.Get(bound[0]).(bool)
+			if !isBool {
+				continue
+			}
+			limit, found := node.Lookup(bound[1])
+			if exclusive && found {
+				node.Set(bound[0], limit)
+				node.Delete(bound[1])
+			} else {
+				node.Delete(bound[0])
+			}
+			changes++
+		}
+	}
+	if d < Draft2019_09 {
+		rename("definitions", "$defs")
+		if ref, ok := node.Get("$ref").(string); ok && strings.HasPrefix(ref, "#/definitions/") {
+			node.Set("$ref", "#/$defs/"+strings.TrimPrefix(ref, "#/definitions/"))
+			changes++
+		}
+		if deps, ok := AsJSONDict(node.Get("dependencies")); ok {
+			required := NewJSONDict()
+			schemas := NewJSONDict()
+			for _, k := range deps.Keys() {
+				if _, isList := deps.Get(k).([]any); isList {
+					required.Set(k, deps.Get(k))
+				} else {
+					schemas.Set(k, deps.Get(k))
+				}
+			}
+			node.Delete("dependencies")
+			if required.Len() > 0 {
+				node.Set("dependentRequired", required)
+			}
+			if schemas.Len() > 0 {
+				node.Set("dependentSchemas", schemas)
+			}
+			changes++
+		}
+	}
+	if d < Draft2020_12 {
+		if _, isList := node.Get("items").([]any); isList {
+			node.Rename("items", "prefixItems")
+			rename("additionalItems", "items")
+			changes++
+		} else if _, found := node.Lookup("additionalItems"); found {
+			// additionalItems is ignored without a list in items
+			node.Delete("additionalItems")
+			changes++
+		}
+		if ref, ok := node.Get("$recursiveRef").(string); ok {
+			node.Set("$recursiveRef", strings.TrimSuffix(ref, "#")+"#meta")
+			rename("$recursiveRef", "$dynamicRef")
+		}
+		if anchor, ok := node.Get("$recursiveAnchor").(bool); ok {
+			if anchor {
+				node.Set("$recursiveAnchor", "meta")
+				rename("$recursiveAnchor", "$dynamicAnchor")
+			} else {
+				node.Delete("$recursiveAnchor")
+				changes++
+			}
+		}
+	}
+	return changes
+}
+
+// readLatestVocabulary rewrites the loaded schema to 2020-12, the builders
+// only know the keywords of 2020-12. $schema is rewritten as well, the
+// bundled schemas are 2020-12.
+func readLatestVocabulary(js JSONDict) {
+	MigrateSchema(js)
+}
+
+// MigrateSchema rewrites the schema js of an older draft in place to
+// 2020-12 and returns the number of rewritten keywords. The dicts which
+// are no JSONDictEditor are left unchanged.
+func MigrateSchema(js JSONDict) int {
+	changes := 0
+	walkDialect(js, "", DefaultDialect, func(dict JSONDict, _ string, d Dialect) {
+		node, ok := dict.(editableDict)
+		if !ok {
+			return
+		}
+		changes += migrateKeywords(node, d)
+		if schema, ok := node.Get("$schema").(string); ok && d < Draft2020_12 && schema != Draft2020_12.URI() {
+			node.Set("$schema", Draft2020_12.URI())
+			changes++
+		}
+	})
+	return changes
+}
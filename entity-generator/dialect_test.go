@@ -0,0 +1,145 @@
+package entity_generator
+
+import (
+	"encoding/json"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDialect(t *testing.T) {
+	for schema, d := range map[string]Dialect{
+		"http://json-schema.org/draft-04/schema#":      Draft04,
+		"https://json-schema.org/draft-06/schema":      Draft06,
+		"http://json-schema.org/draft-07/schema":       Draft07,
+		"https://json-schema.org/draft/2019-09/schema": Draft2019_09,
+		"https://json-schema.org/draft/2020-12/schema": Draft2020_12,
+	} {
+		parsed, known := ParseDialect(schema)
+		assert.True(t, known, schema)
+		assert.Equal(t, d, parsed, schema)
+	}
+	parsed, known := ParseDialect("https://example.com/schema")
+	assert.False(t, known)
+	assert.Equal(t, DefaultDialect, parsed)
+	assert.Equal(t, "draft-07", Draft07.String())
+}
+
+const draft04Schema = `{
+  "$schema": "http://json-schema.org/draft-04/schema#",
+  "id": "https://Old",
+  "title": "Old",
+  "type": "object",
+  "definitions": {
+    "pos": { "type": "integer", "minimum": 0, "exclusiveMinimum": true }
+  },
+  "properties": {
+    "id": { "type": "string" },
+    "pos": { "$ref": "#/definitions/pos" },
+    "tuple": { "type": "array", "items": [{ "type": "string" }], "additionalItems": { "type": "number" } },
+    "rate": { "type": "number", "maximum": 1, "exclusiveMaximum": false, "const": 1 }
+  },
+  "dependencies": { "id": ["pos"] }
+}`
+
+func TestCheckDialect(t *testing.T) {
+	js := NewJSONDict()
+	assert.NoError(t, json.Unmarshal([]byte(draft04Schema), js))
+	ds := CheckDialect("old.schema.json", js)
+	pointers := []string{}
+	for _, d := range ds {
+		assert.Equal(t, SeverityWarning, d.Severity)
+		assert.Equal(t, DiagDialectKeyword, d.Code)
+		pointers = append(pointers, d.Pointer)
+	}
+	assert.Equal(t, []string{"/properties/rate/const"}, pointers)
+
+	js = NewJSONDict()
+	assert.NoError(t, json.Unmarshal([]byte(`{
+  "id": "x",
+  "definitions": {},
+  "items": [],
+  "properties": {
+    "legacy": { "$schema": "http://json-schema.org/draft-07/schema#", "definitions": {}, "$defs": {} },
+    "bound": { "exclusiveMinimum": true },
+    "unknown": { "$schema": "https://example.com/schema" }
+  }
+}`), js))
+	ds = CheckDialect("new.schema.json", js)
+	msgs := []string{}
+	for _, d := range ds {
+		msgs = append(msgs, d.Pointer+": "+d.Message)
+	}
+	assert.Equal(t, []string{
+		"/definitions: definitions is not a keyword of 2020-12, use $defs",
+		"/id: id is not a keyword of 2020-12, use $id",
+		"/items: items is not a list in 2020-12, use prefixItems",
+		"/properties/legacy/$defs: $defs is not a keyword of draft-07, use definitions",
+		"/properties/bound/exclusiveMinimum: boolean exclusiveMinimum is draft-04, use a number in 2020-12",
+		"/properties/unknown/$schema: unknown $schema https://example.com/schema, read as 2020-12",
+	}, msgs)
+	assert.Equal(t, DiagUnknownDialect, ds[len(ds)-1].Code)
+}
+
+func TestMigrateSchema(t *testing.T) {
+	js := NewJSONDict()
+	assert.NoError(t, json.Unmarshal([]byte(draft04Schema), js))
+	assert.Equal(t, 9, MigrateSchema(js))
+	out, err := json.MarshalIndent(js, "", "  ")
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "$id": "https://Old",
+  "title": "Old",
+  "type": "object",
+  "$defs": {
+    "pos": { "type": "integer", "exclusiveMinimum": 0 }
+  },
+  "properties": {
+    "id": { "type": "string" },
+    "pos": { "$ref": "#/$defs/pos" },
+    "tuple": { "type": "array", "prefixItems": [{ "type": "string" }], "items": { "type": "number" } },
+    "rate": { "type": "number", "maximum": 1, "const": 1 }
+  },
+  "dependentRequired": { "id": ["pos"] }
+}`, string(out))
+	assert.Empty(t, CheckDialect("old.schema.json", js))
+	assert.Equal(t, 0, MigrateSchema(js))
+}
+
+func TestRegistryDialect(t *testing.T) {
+	fsys := fstest.MapFS{
+		"old.schema.json": {Data: []byte(`{
+  "$schema": "http://json-schema.org/draft-04/schema#",
+  "id": "https://Old",
+  "title": "Old",
+  "type": "object",
+  "properties": {
+    "count": { "type": "integer", "minimum": 1, "const": 1 },
+    "pos": { "type": "integer", "minimum": 5, "exclusiveMinimum": true },
+    "rate": { "type": "number", "maximum": 1.5, "exclusiveMaximum": true }
+  }
+}`)},
+	}
+	ctx := PropertyCtx{Registry: NewSchemaRegistry(NewFSSchemaLoader(fsys))}
+	po := LoadSchemaFile(ctx, "old.schema.json").Ok().(PropertyObject)
+	// the draft-04 id is read as $id
+	assert.Equal(t, "https://Old", po.Id())
+	// the schema is migrated to 2020-12 on load
+	assert.Equal(t, Draft2020_12.URI(), po.Schema())
+
+	// the exclusive bounds of draft-04 survive the migration
+	pos := po.PropertyByName("pos").Ok().Property().(PropertyInteger)
+	assert.Equal(t, 5, pos.ExclusiveMinimum().Value())
+	assert.True(t, pos.Minimum().IsNone())
+	assert.Equal(t, 5, PropertyToJson(pos).Get("exclusiveMinimum"))
+	rate := po.PropertyByName("rate").Ok().Property().(PropertyNumber)
+	assert.Equal(t, 1.5, rate.ExclusiveMaximum().Value())
+	assert.Len(t, Validate(po, map[string]any{"count": 1, "pos": 5, "rate": 1.5}), 2)
+	assert.Empty(t, Validate(po, map[string]any{"count": 1, "pos": 6, "rate": 1.4}))
+
+	ws := ctx.Registry.Warnings()
+	assert.Len(t, ws, 1)
+	assert.Equal(t, "/old.schema.json:7:49#/properties/count/const: warning[dialect-keyword]: const is not a keyword of draft-04", ws[0].Error())
+}
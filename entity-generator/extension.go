@@ -0,0 +1,224 @@
+package entity_generator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/mabels/wueste/entity-generator/rusty"
+)
+
+// Extension handles a x- keyword of the schemas.
+type Extension struct {
+	Name        string
+	Description string
+	// Decode converts the json value of the keyword, the value is kept as
+	// it is without Decode.
+	Decode func(raw any) (any, error)
+	// Validate checks the decoded value on the property which has it.
+	Validate func(prop Property, value any) error
+	// Hooks are the behaviour of the extension in the generators by
+	// language, the type of a hook is defined by its generator.
+	Hooks map[string]any
+}
+
+// ExtensionValue is the decoded value of an extension on a property.
+type ExtensionValue struct {
+	Extension Extension
+	Value     any
+}
+
+// Hook returns the hook of the generator of the language.
+func (ev ExtensionValue) Hook(language string) (any, bool) {
+	hook, found := ev.Extension.Hooks[language]
+	return hook, found
+}
+
+// ExtensionOf returns the decoded value of the extension name of prop.
+func ExtensionOf[T any](prop Property, name string) rusty.Optional[T] {
+	ev := prop.Meta().Extension(name)
+	if ev.IsNone() {
+		return rusty.None[T]()
+	}
+	val, ok := ev.Value().Value.(T)
+	if !ok {
+		return rusty.None[T]()
+	}
+	return rusty.Some(val)
+}
+
+// ExtensionRegistry are the known extensions, it is safe for concurrent use.
+type ExtensionRegistry struct {
+	mutex      sync.RWMutex
+	extensions map[string]Extension
+}
+
+func NewExtensionRegistry() *ExtensionRegistry {
+	return &ExtensionRegistry{
+		extensions: map[string]Extension{},
+	}
+}
+
+// DefaultExtensions returns a registry with the extensions of wueste.
+func DefaultExtensions() *ExtensionRegistry {
+	r := NewExtensionRegistry()
+	for _, ext := range []Extension{groupsExtension} {
+		if err := r.Register(ext); err != nil {
+			panic(err)
+		}
+	}
+	return r
+}
+
+func (r *ExtensionRegistry) Register(ext Extension) error {
+	if !strings.HasPrefix(ext.Name, "x-") || len(ext.Name) == len("x-") {
+		return fmt.Errorf("extension name must start with x-: %q", ext.Name)
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if _, found := r.extensions[ext.Name]; found {
+		return fmt.Errorf("extension %s is already registered", ext.Name)
+	}
+	r.extensions[ext.Name] = ext
+	return nil
+}
+
+func (r *ExtensionRegistry) Lookup(name string) (Extension, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	ext, found := r.extensions[name]
+	return ext, found
+}
+
+// Names returns the sorted names of the extensions.
+func (r *ExtensionRegistry) Names() []string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	names := make([]string, 0, len(r.extensions))
+	for name := range r.extensions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// editDistance is the levenshtein distance of a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = cur[j-1] + 1
+			if prev[j]+1 < cur[j] {
+				cur[j] = prev[j] + 1
+			}
+			if prev[j-1]+cost < cur[j] {
+				cur[j] = prev[j-1] + cost
+			}
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+// suggest returns the registered name which is a likely typo of name.
+func (r *ExtensionRegistry) suggest(name string) rusty.Optional[string] {
+	best := rusty.None[string]()
+	bestDistance := 3
+	for _, known := range r.Names() {
+		if d := editDistance(strings.ToLower(name), known); d < bestDistance {
+			best = rusty.Some(known)
+			bestDistance = d
+		}
+	}
+	return best
+}
+
+// CheckExtensions warns about the x- keywords in the schema js of the file
+// fname which are not registered.
+func (r *ExtensionRegistry) CheckExtensions(fname string, js JSONDict) Diagnostics {
+	ds := Diagnostics{}
+	walkDialect(js, "", DefaultDialect, func(node JSONDict, pointer string, _ Dialect) {
+		for _, k := range node.Keys() {
+			if !strings.HasPrefix(k, "x-") {
+				continue
+			}
+			if _, found := r.Lookup(k); found {
+				continue
+			}
+			msg := fmt.Sprintf("unknown extension %s", k)
+			if s := r.suggest(k); s.IsSome() {
+				msg += fmt.Sprintf(", did you mean %s?", s.Value())
+			}
+			d := NewDiagnostic(DiagUnknownExtension, "%s", msg).at(pointer + "/" + EscapeJSONPointer(k)).atSource(keyPosition(node, k))
+			d.Severity = SeverityWarning
+			d.File = fname
+			ds = append(ds, d)
+		}
+	})
+	return ds
+}
+
+// decodeExtensions decodes and validates the registered extensions of js
+// into the meta of prop.
+func (r *ExtensionRegistry) decodeExtensions(prop Property, js JSONDict) Diagnostics {
+	ds := Diagnostics{}
+	for _, k := range js.Keys() {
+		ext, found := r.Lookup(k)
+		if !found {
+			continue
+		}
+		invalid := func(err error) {
+			ds = append(ds, NewDiagnostic(DiagInvalidExtension, "%s: %v", k, err).wrap(err).at("/"+EscapeJSONPointer(k)).atSource(keyPosition(js, k)))
+		}
+		value := js.Get(k)
+		if ext.Decode != nil {
+			decoded, err := ext.Decode(value)
+			if err != nil {
+				invalid(err)
+				continue
+			}
+			value = decoded
+		}
+		if ext.Validate != nil {
+			if err := ext.Validate(prop, value); err != nil {
+				invalid(err)
+				continue
+			}
+		}
+		prop.Meta().SetExtension(ExtensionValue{Extension: ext, Value: value})
+	}
+	return ds
+}
+
+// DecodeStringList is the Decode of extensions which are a list of strings.
+func DecodeStringList(raw any) (any, error) {
+	list, ok := raw.([]any)
+	if !ok {
+		return nil, fmt.Errorf("expected a list of strings")
+	}
+	out := make([]string, 0, len(list))
+	for _, item := range list {
+		str, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected a list of strings, got %v", item)
+		}
+		out = append(out, str)
+	}
+	return out, nil
+}
+
+var groupsExtension = Extension{
+	Name:        "x-groups",
+	Description: "the groups of a property for the group types",
+	Decode:      DecodeStringList,
+}
@@ -0,0 +1,91 @@
+package entity_generator
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func sensitiveExtension() Extension {
+	return Extension{
+		Name: "x-sensitive",
+		Decode: func(raw any) (any, error) {
+			b, ok := raw.(bool)
+			if !ok {
+				return nil, fmt.Errorf("expected a boolean")
+			}
+			return b, nil
+		},
+		Validate: func(prop Property, value any) error {
+			if prop.Type() == OBJECT {
+				return fmt.Errorf("objects can not be sensitive")
+			}
+			return nil
+		},
+	}
+}
+
+func TestExtensionRegistry(t *testing.T) {
+	r := DefaultExtensions()
+	assert.NoError(t, r.Register(sensitiveExtension()))
+	assert.Error(t, r.Register(sensitiveExtension()))
+	assert.Error(t, r.Register(Extension{Name: "sensitive"}))
+	assert.Equal(t, []string{"x-groups", "x-sensitive"}, r.Names())
+	assert.Equal(t, "x-groups", r.suggest("x-grups").Value())
+	assert.Equal(t, "x-sensitive", r.suggest("X-Sensitiv").Value())
+	assert.True(t, r.suggest("x-other").IsNone())
+}
+
+func TestExtensionDecode(t *testing.T) {
+	fsys := fstest.MapFS{
+		"user.schema.json": {Data: []byte(`{
+  "$id": "https://User", "title": "User", "type": "object",
+  "properties": {
+    "name": { "type": "string", "x-groups": ["key"], "x-sensitive": true },
+    "mail": { "type": "string", "x-sensitve": true, "x-team": "a" }
+  }
+}`)},
+		"invalid.schema.json": {Data: []byte(`{
+  "$id": "https://Invalid", "title": "Invalid", "type": "object",
+  "properties": {
+    "name": { "type": "string", "x-groups": "key" }
+  }
+}`)},
+		"object.schema.json": {Data: []byte(`{
+  "$id": "https://Object", "title": "Object", "type": "object",
+  "x-sensitive": true,
+  "properties": {}
+}`)},
+	}
+	ctx := PropertyCtx{Registry: NewSchemaRegistry(NewFSSchemaLoader(fsys))}
+	assert.NoError(t, ctx.Registry.Extensions.Register(sensitiveExtension()))
+
+	po := LoadSchemaFile(ctx, "user.schema.json").Ok().(PropertyObject)
+	name := po.PropertyByName("name").Ok().Property()
+	assert.Equal(t, []string{"key"}, ExtensionOf[[]string](name, "x-groups").Value())
+	assert.True(t, ExtensionOf[bool](name, "x-sensitive").Value())
+	assert.True(t, ExtensionOf[string](name, "x-groups").IsNone())
+	assert.Len(t, name.Meta().Extensions(), 2)
+	mail := po.PropertyByName("mail").Ok().Property()
+	assert.Empty(t, mail.Meta().Extensions())
+	// unknown extensions are kept as they are
+	assert.Equal(t, "a", mail.XProperties()["x-team"])
+
+	msgs := []string{}
+	for _, w := range ctx.Registry.Warnings() {
+		msgs = append(msgs, w.Error())
+	}
+	assert.Equal(t, []string{
+		"/user.schema.json:5:33#/properties/mail/x-sensitve: warning[unknown-extension]: unknown extension x-sensitve, did you mean x-sensitive?",
+		"/user.schema.json:5:53#/properties/mail/x-team: warning[unknown-extension]: unknown extension x-team",
+	}, msgs)
+
+	err := LoadSchemaFile(ctx, "invalid.schema.json").Err()
+	assert.True(t, errors.Is(err, ErrInvalidExtension))
+	assert.Equal(t, "/invalid.schema.json:4:33#/properties/name/x-groups: error[invalid-extension]: x-groups: expected a list of strings", err.Error())
+	err = LoadSchemaFile(ctx, "object.schema.json").Err()
+	assert.Equal(t, "/object.schema.json:3:3#/x-sensitive: error[invalid-extension]: x-sensitive: objects can not be sensitive", err.Error())
+}
@@ -0,0 +1,83 @@
+package entity_generator
+
+import (
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// FSSchemaLoader loads the schemas from a fs.FS like an embed.FS. The root
+// of the FS is "/", relative names are looked up in the root and in the
+// include directories.
+type FSSchemaLoader struct {
+	fsys        fs.FS
+	includeDirs []string
+}
+
+func NewFSSchemaLoader(fsys fs.FS, includeDirs ...string) *FSSchemaLoader {
+	return &FSSchemaLoader{
+		fsys:        fsys,
+		includeDirs: append(includeDirs, "/"),
+	}
+}
+
+// fsName is the name of the absolute path in the fs.FS.
+func fsName(fname string) string {
+	name := strings.TrimPrefix(path.Clean("/"+fname), "/")
+	if name == "" {
+		return "."
+	}
+	return name
+}
+
+func (sl *FSSchemaLoader) isFile(fname string) bool {
+	stat, err := fs.Stat(sl.fsys, fsName(fname))
+	if err != nil {
+		return false
+	}
+	return !stat.IsDir()
+}
+
+func (sl *FSSchemaLoader) Clone(prependIncDirs ...string) SchemaLoader {
+	return &FSSchemaLoader{
+		fsys:        sl.fsys,
+		includeDirs: append(append([]string{}, prependIncDirs...), sl.includeDirs...),
+	}
+}
+
+func (sl *FSSchemaLoader) IncludeDirs() []string {
+	return sl.includeDirs
+}
+
+// Abs implements SchemaLoader.
+func (sl *FSSchemaLoader) Abs(fname string) (string, error) {
+	if path.IsAbs(fname) && sl.isFile(fname) {
+		return path.Clean(fname), nil
+	}
+	for _, dir := range sl.includeDirs {
+		incFname := path.Join("/", dir, fname)
+		if sl.isFile(incFname) {
+			return incFname, nil
+		}
+	}
+	return fname, &fs.PathError{Op: "abs", Path: fname, Err: fs.ErrNotExist}
+}
+
+// ReadFile implements SchemaLoader.
+func (sl *FSSchemaLoader) ReadFile(fname string) ([]byte, error) {
+	return fs.ReadFile(sl.fsys, fsName(fname))
+}
+
+// Unmarshal implements SchemaLoader, the format is sniffed from the content.
+func (sl *FSSchemaLoader) Unmarshal(bytes []byte, v interface{}) error {
+	return UnmarshalSchema(SniffSchemaFormat(bytes), bytes, v)
+}
+
+// UnmarshalFile implements SchemaFileDecoder.
+func (sl *FSSchemaLoader) UnmarshalFile(fname string, bytes []byte, v interface{}) error {
+	format, found := SchemaFormatByExt(fname)
+	if !found {
+		return sl.Unmarshal(bytes, v)
+	}
+	return UnmarshalSchema(format, bytes, v)
+}
@@ -0,0 +1,42 @@
+package entity_generator
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFSSchemaLoader(t *testing.T) {
+	fsys := fstest.MapFS{
+		"schemas/main.schema.yaml": {Data: []byte(`$id: https://Main
+title: Main
+type: object
+properties:
+  sub: { $ref: "file://sub/sub.schema.json" }
+`)},
+		"schemas/sub/sub.schema.json": {Data: []byte(`{
+	"$id": "https://Sub", "title": "Sub", "type": "object",
+	"properties": { "name": { "type": "string" } }
+}`)},
+	}
+	sl := NewFSSchemaLoader(fsys, "/schemas")
+	abs, err := sl.Abs("main.schema.yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, "/schemas/main.schema.yaml", abs)
+	abs, err = sl.Abs("/schemas/sub/sub.schema.json")
+	assert.NoError(t, err)
+	assert.Equal(t, "/schemas/sub/sub.schema.json", abs)
+	_, err = sl.Abs("schemas")
+	assert.Error(t, err)
+
+	ctx := PropertyCtx{Registry: NewSchemaRegistry(sl)}
+	rProp := LoadSchemaFile(ctx, "main.schema.yaml")
+	assert.True(t, rProp.IsOk())
+	po := rProp.Ok().(PropertyObject)
+	assert.Equal(t, "Sub", po.PropertyByName("sub").Ok().Property().(PropertyObject).Title())
+
+	ds := AsDiagnostics(LoadSchemaFile(ctx, "missing.schema.json").Err())
+	assert.Equal(t, DiagRefNotFound, ds[0].Code)
+	assert.Equal(t, "missing.schema.json", ds[0].File)
+}
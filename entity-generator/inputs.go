@@ -0,0 +1,91 @@
+package entity_generator
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mabels/wueste/entity-generator/rusty"
+)
+
+// IsSchemaFileName reports if the file has a schema extension and is not a
+// project config.
+func IsSchemaFileName(fname string) bool {
+	if _, found := SchemaFormatByExt(fname); !found {
+		return false
+	}
+	for _, name := range ProjectConfigFileNames {
+		if filepath.Base(fname) == name {
+			return false
+		}
+	}
+	return true
+}
+
+func walkSchemaDir(dir string) rusty.Result[[]string] {
+	files := []string{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && path != dir && strings.HasPrefix(d.Name(), ".") {
+			return filepath.SkipDir
+		}
+		if !d.IsDir() && IsSchemaFileName(path) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return rusty.Err[[]string](err)
+	}
+	sort.Strings(files)
+	return rusty.Ok(files)
+}
+
+// ExpandInputFiles resolves the glob patterns and directories of the
+// inputs, directories are walked recursively for .json, .json5, .yaml and
+// .yml files. A pattern without a match is an error, plain file names are
+// passed as they are.
+func ExpandInputFiles(inputs []string) rusty.Result[[]string] {
+	files := []string{}
+	seen := map[string]bool{}
+	add := func(fname string) {
+		if !seen[fname] {
+			seen[fname] = true
+			files = append(files, fname)
+		}
+	}
+	for _, input := range inputs {
+		matches := []string{input}
+		if strings.ContainsAny(input, "*?[") {
+			var err error
+			matches, err = filepath.Glob(input)
+			if err != nil {
+				return rusty.Err[[]string](fmt.Errorf("invalid pattern %s: %w", input, err))
+			}
+			if len(matches) == 0 {
+				return rusty.Err[[]string](fmt.Errorf("no file matches %s", input))
+			}
+			sort.Strings(matches)
+		}
+		for _, match := range matches {
+			stat, err := os.Stat(match)
+			if err != nil || !stat.IsDir() {
+				add(match)
+				continue
+			}
+			rFiles := walkSchemaDir(match)
+			if rFiles.IsErr() {
+				return rFiles
+			}
+			for _, fname := range rFiles.Ok() {
+				add(fname)
+			}
+		}
+	}
+	return rusty.Ok(files)
+}
@@ -0,0 +1,32 @@
+package entity_generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpandInputFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, fname := range []string{"a.schema.json", "b.schema.json", "nested/deep/c.json", "nested/readme.md", "nested/.hidden/d.json"} {
+		assert.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, fname)), 0755))
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, fname), []byte("{}"), 0644))
+	}
+	files := ExpandInputFiles([]string{
+		filepath.Join(dir, "b.schema.json"),
+		filepath.Join(dir, "*.schema.json"),
+		filepath.Join(dir, "nested"),
+		"not/there.json",
+	}).Ok()
+	assert.Equal(t, []string{
+		filepath.Join(dir, "b.schema.json"),
+		filepath.Join(dir, "a.schema.json"),
+		filepath.Join(dir, "nested/deep/c.json"),
+		"not/there.json",
+	}, files)
+
+	assert.Error(t, ExpandInputFiles([]string{filepath.Join(dir, "*.yaml")}).Err())
+	assert.Error(t, ExpandInputFiles([]string{"[["}).Err())
+}
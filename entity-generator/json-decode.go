@@ -0,0 +1,109 @@
+package entity_generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// jsonDecoder reads the tokens of a json document, the objects are decoded
+// into JSONDict with the positions of their members in the same pass.
+type jsonDecoder struct {
+	data  []byte
+	lines lineIndex
+	dec   *json.Decoder
+}
+
+// nextToken returns the offset of the next token after the delimiters the
+// decoder skips.
+func (d *jsonDecoder) nextToken() int {
+	off := int(d.dec.InputOffset())
+	for off < len(d.data) {
+		switch d.data[off] {
+		case ' ', '\t', '\r', '\n', ',', ':':
+			off++
+		default:
+			return off
+		}
+	}
+	return off
+}
+
+func (d *jsonDecoder) value() (any, error) {
+	tok, err := d.dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch tok {
+	case json.Delim('{'):
+		js := NewJSONDict()
+		for d.dec.More() {
+			keyStart := d.nextToken()
+			key, err := d.dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			name, _ := key.(string)
+			valueStart := d.nextToken()
+			member, err := d.value()
+			if err != nil {
+				return nil, err
+			}
+			js.Set(name, member)
+			setMemberPosition(js, name, memberPosition{
+				key:   d.lines.position("", keyStart),
+				value: d.lines.position("", valueStart),
+			})
+		}
+		_, err = d.dec.Token()
+		return js, err
+	case json.Delim('['):
+		items := []any{}
+		for d.dec.More() {
+			item, err := d.value()
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		_, err = d.dec.Token()
+		return items, err
+	}
+	return tok, nil
+}
+
+// unmarshalJSON decodes the json data into v, a JSONDict gets the order
+// and the positions of the members without the file.
+func unmarshalJSON(data []byte, v interface{}) error {
+	js, ok := v.(JSONDict)
+	if !ok {
+		return json.Unmarshal(data, v)
+	}
+	d := &jsonDecoder{data: data, lines: newLineIndex(data), dec: json.NewDecoder(bytes.NewReader(data))}
+	val, err := d.value()
+	if err == nil {
+		if _, trailing := d.dec.Token(); trailing != io.EOF {
+			err = fmt.Errorf("invalid data after the top-level value")
+		}
+	}
+	if err != nil {
+		// the errors of json.Unmarshal have the offset of the syntax error
+		var probe any
+		if uerr := json.Unmarshal(data, &probe); uerr != nil {
+			return uerr
+		}
+		return err
+	}
+	dict, ok := val.(JSONDict)
+	if !ok {
+		return fmt.Errorf("expected an object got %s", jsonTypeOf(val))
+	}
+	for _, k := range dict.Keys() {
+		js.Set(k, dict.Get(k))
+		if pos, found := memberPositionOf(dict, k); found {
+			setMemberPosition(js, k, pos)
+		}
+	}
+	return nil
+}
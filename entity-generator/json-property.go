@@ -4,8 +4,26 @@ import (
 	"fmt"
 
 	"github.com/iancoleman/orderedmap"
+	"github.com/mabels/wueste/entity-generator/rusty"
 )
 
+// SourcePosition is the location of a key or value in a schema file, Line
+// and Column are 1-based.
+type SourcePosition struct {
+	File   string
+	Line   int
+	Column int
+}
+
+func (p SourcePosition) String() string {
+	return fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Column)
+}
+
+type memberPosition struct {
+	key   SourcePosition
+	value SourcePosition
+}
+
 // type JSONDict map[string]interface{}
 type JSONDict interface {
 	// orderedmap.OrderedMap
@@ -18,8 +36,66 @@ type JSONDict interface {
 	MarshalJSON() ([]byte, error)
 }
 
+// JSONDictPositions is implemented by the JSONDicts of the SchemaLoader,
+// KeyPosition and ValuePosition are the locations of a member in the
+// schema file.
+type JSONDictPositions interface {
+	KeyPosition(key string) rusty.Optional[SourcePosition]
+	ValuePosition(key string) rusty.Optional[SourcePosition]
+}
+
+// JSONDictEditor is implemented by the JSONDict of this package, the
+// migration of the schemas deletes and renames members with it.
+type JSONDictEditor interface {
+	Delete(key string)
+	// Rename keeps the position of the member in the keys.
+	Rename(from string, to string)
+}
+
+// positionedDict is implemented by the JSONDict of this package, the
+// loaders record the positions with it. Other implementations of JSONDict
+// have no positions.
+type positionedDict interface {
+	position(key string) (memberPosition, bool)
+	setPosition(key string, pos memberPosition)
+}
+
+// memberPositionOf returns the position of the member key of js.
+func memberPositionOf(js JSONDict, key string) (memberPosition, bool) {
+	if p, ok := js.(positionedDict); ok {
+		return p.position(key)
+	}
+	return memberPosition{}, false
+}
+
+// keyPosition returns the location of the key of the member key of js,
+// none if js has no positions.
+func keyPosition(js JSONDict, key string) rusty.Optional[SourcePosition] {
+	if p, ok := js.(JSONDictPositions); ok {
+		return p.KeyPosition(key)
+	}
+	return rusty.None[SourcePosition]()
+}
+
+// valuePosition returns the location of the value of the member key of js.
+func valuePosition(js JSONDict, key string) rusty.Optional[SourcePosition] {
+	if p, ok := js.(JSONDictPositions); ok {
+		return p.ValuePosition(key)
+	}
+	return rusty.None[SourcePosition]()
+}
+
+// setMemberPosition records the position of the member key of js, it is
+// dropped if js has no positions.
+func setMemberPosition(js JSONDict, key string, pos memberPosition) {
+	if p, ok := js.(positionedDict); ok {
+		p.setPosition(key, pos)
+	}
+}
+
 type jsonDict struct {
-	omap orderedmap.OrderedMap
+	omap      orderedmap.OrderedMap
+	positions map[string]memberPosition
 }
 
 // Len implements JSONProperty.
@@ -27,12 +103,9 @@ func (j *jsonDict) Len() int {
 	return len(j.omap.Keys())
 }
 
-// Get implements JSONProperty.
+// Get implements JSONProperty, a missing key is nil.
 func (j *jsonDict) Get(key string) any {
-	val, found := j.Lookup(key)
-	if !found {
-		panic(fmt.Sprintf("key[%s] not found", key))
-	}
+	val, _ := j.Lookup(key)
 	return val
 }
 
@@ -62,6 +135,37 @@ func (j *jsonDict) Set(key string, value any) {
 	j.omap.Set(key, value)
 }
 
+// Delete implements JSONDictEditor.
+func (j *jsonDict) Delete(key string) {
+	j.omap.Delete(key)
+	delete(j.positions, key)
+}
+
+// Rename implements JSONDictEditor, an existing member to is replaced.
+func (j *jsonDict) Rename(from string, to string) {
+	val, found := j.omap.Get(from)
+	if !found || from == to {
+		return
+	}
+	j.Delete(to)
+	order := append([]string{}, j.omap.Keys()...)
+	for i, k := range order {
+		if k == from {
+			order[i] = to
+		}
+	}
+	pos, hasPos := j.position(from)
+	j.omap.Delete(from)
+	j.omap.Set(to, val)
+	j.omap.SortKeys(func(keys []string) {
+		copy(keys, order)
+	})
+	if hasPos {
+		delete(j.positions, from)
+		j.setPosition(to, pos)
+	}
+}
+
 // UnmarshalJSON implements JSONProperty.
 func (j *jsonDict) UnmarshalJSON(b []byte) error {
 	return j.omap.UnmarshalJSON(b)
@@ -71,6 +175,49 @@ func (j *jsonDict) Keys() []string {
 	return j.omap.Keys()
 }
 
+// KeyPosition implements JSONDictPositions.
+func (j *jsonDict) KeyPosition(key string) rusty.Optional[SourcePosition] {
+	pos, found := j.position(key)
+	if !found {
+		return rusty.None[SourcePosition]()
+	}
+	return rusty.Some(pos.key)
+}
+
+// ValuePosition implements JSONDictPositions.
+func (j *jsonDict) ValuePosition(key string) rusty.Optional[SourcePosition] {
+	pos, found := j.position(key)
+	if !found {
+		return rusty.None[SourcePosition]()
+	}
+	return rusty.Some(pos.value)
+}
+
+func (j *jsonDict) position(key string) (memberPosition, bool) {
+	pos, found := j.positions[key]
+	return pos, found
+}
+
+func (j *jsonDict) setPosition(key string, pos memberPosition) {
+	if j.positions == nil {
+		j.positions = map[string]memberPosition{}
+	}
+	j.positions[key] = pos
+}
+
+// AsJSONDict returns the JSONDict of a value, objects nested in arrays are
+// still orderedmap.OrderedMap after unmarshal.
+func AsJSONDict(v any) (JSONDict, bool) {
+	switch d := v.(type) {
+	case JSONDict:
+		return d, true
+	case orderedmap.OrderedMap:
+		return &jsonDict{omap: d}, true
+	default:
+		return nil, false
+	}
+}
+
 func NewJSONDict() JSONDict {
 	return &jsonDict{
 		omap: orderedmap.New(),
@@ -126,3 +126,42 @@ func TestOrderedMapSetMutable(t *testing.T) {
 	assert.Equal(t, "v3", inProp.Keys()[2])
 
 }
+
+func TestJsonPropertyRename(t *testing.T) {
+	js := NewJSONDict()
+	assert.NoError(t, json.Unmarshal([]byte(`{"a": 1, "id": "x", "c": 3}`), js))
+	editor := js.(JSONDictEditor)
+	editor.Rename("id", "$id")
+	assert.Equal(t, []string{"a", "$id", "c"}, js.Keys())
+	assert.Equal(t, "x", js.Get("$id"))
+	editor.Rename("missing", "b")
+	editor.Delete("a")
+	out, err := json.Marshal(js)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"$id":"x","c":3}`, string(out))
+}
+
+// wrappedDict is a JSONDict of another package, it only has the exported
+// methods.
+type wrappedDict struct {
+	JSONDict
+}
+
+func TestJsonDictWithoutPositions(t *testing.T) {
+	var js JSONDict = wrappedDict{NewJSONDict()}
+	js.Set("a", 1)
+	setMemberPosition(js, "a", memberPosition{key: SourcePosition{Line: 1, Column: 1}})
+	_, found := memberPositionOf(js, "a")
+	assert.False(t, found)
+	assert.True(t, keyPosition(js, "a").IsNone())
+
+	// the migration can not rewrite the members of other dicts
+	js.Set("id", "https://Old")
+	js.Set("$schema", "http://json-schema.org/draft-04/schema#")
+	assert.Equal(t, 0, MigrateSchema(js))
+	assert.Equal(t, "https://Old", js.Get("id"))
+
+	inner := NewJSONDict()
+	setMemberPosition(inner, "a", memberPosition{key: SourcePosition{Line: 2, Column: 3}})
+	assert.Equal(t, SourcePosition{Line: 2, Column: 3}, keyPosition(inner, "a").Value())
+}
@@ -0,0 +1,410 @@
+package entity_generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// json5SyntaxError is the position of an error in a json5 document, the
+// column counts bytes like the one of json.SyntaxError.
+type json5SyntaxError struct {
+	Line   int
+	Column int
+	Msg    string
+}
+
+func (e *json5SyntaxError) Error() string {
+	return fmt.Sprintf("json5: line %d column %d: %s", e.Line, e.Column, e.Msg)
+}
+
+// json5Decoder is a recursive descent parser of json5, the objects are
+// decoded into JSONDict with the positions of their members.
+type json5Decoder struct {
+	data      []byte
+	offset    int
+	line      int
+	lineStart int
+}
+
+func (d *json5Decoder) position() SourcePosition {
+	return SourcePosition{Line: d.line, Column: d.offset - d.lineStart + 1}
+}
+
+func (d *json5Decoder) errorf(format string, args ...any) error {
+	pos := d.position()
+	return &json5SyntaxError{Line: pos.Line, Column: pos.Column, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (d *json5Decoder) peek() (rune, int) {
+	if d.offset >= len(d.data) {
+		return -1, 0
+	}
+	return utf8.DecodeRune(d.data[d.offset:])
+}
+
+func (d *json5Decoder) next() rune {
+	r, size := d.peek()
+	if size == 0 {
+		return -1
+	}
+	d.offset += size
+	// \r\n is counted once by the \n
+	if r == '\n' || r == '\u2028' || r == '\u2029' || (r == '\r' && (d.offset >= len(d.data) || d.data[d.offset] != '\n')) {
+		d.line++
+		d.lineStart = d.offset
+	}
+	return r
+}
+
+func isJSON5Space(r rune) bool {
+	return r == '\ufeff' || unicode.IsSpace(r) || unicode.Is(unicode.Zs, r)
+}
+
+// skipSpace skips the white space and the comments.
+func (d *json5Decoder) skipSpace() error {
+	for {
+		r, _ := d.peek()
+		switch {
+		case isJSON5Space(r):
+			d.next()
+		case r == '/' && d.offset+1 < len(d.data) && d.data[d.offset+1] == '/':
+			for r, _ := d.peek(); r != -1 && r != '\n' && r != '\r' && r != '\u2028' && r != '\u2029'; r, _ = d.peek() {
+				d.next()
+			}
+		case r == '/' && d.offset+1 < len(d.data) && d.data[d.offset+1] == '*':
+			start := *d
+			d.next()
+			d.next()
+			for !strings.HasPrefix(string(d.data[d.offset:minInt(d.offset+2, len(d.data))]), "*/") {
+				if d.next() == -1 {
+					return start.errorf("unterminated comment")
+				}
+			}
+			d.next()
+			d.next()
+		default:
+			return nil
+		}
+	}
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func (d *json5Decoder) value() (any, error) {
+	if err := d.skipSpace(); err != nil {
+		return nil, err
+	}
+	r, _ := d.peek()
+	switch {
+	case r == '{':
+		return d.object()
+	case r == '[':
+		return d.array()
+	case r == '"' || r == '\'':
+		return d.string()
+	case r == '-' || r == '+' || r == '.' || (r >= '0' && r <= '9'):
+		return d.number()
+	case r == -1:
+		return nil, d.errorf("unexpected end of input")
+	}
+	word := d.identifier()
+	switch word {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	case "Infinity":
+		return math.Inf(1), nil
+	case "NaN":
+		return math.NaN(), nil
+	}
+	return nil, d.errorf("invalid character %q looking for a value", r)
+}
+
+func (d *json5Decoder) object() (any, error) {
+	js := NewJSONDict()
+	d.next()
+	for {
+		if err := d.skipSpace(); err != nil {
+			return nil, err
+		}
+		if r, _ := d.peek(); r == '}' {
+			d.next()
+			return js, nil
+		}
+		keyPos := d.position()
+		key, err := d.key()
+		if err != nil {
+			return nil, err
+		}
+		if err := d.skipSpace(); err != nil {
+			return nil, err
+		}
+		if r, _ := d.peek(); r != ':' {
+			return nil, d.errorf("expected : after the key %q", key)
+		}
+		d.next()
+		if err := d.skipSpace(); err != nil {
+			return nil, err
+		}
+		valuePos := d.position()
+		val, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		js.Set(key, val)
+		setMemberPosition(js, key, memberPosition{key: keyPos, value: valuePos})
+		if err := d.skipSpace(); err != nil {
+			return nil, err
+		}
+		switch r, _ := d.peek(); r {
+		case ',':
+			d.next()
+		case '}':
+		default:
+			return nil, d.errorf("expected , or } after the value of %q", key)
+		}
+	}
+}
+
+func (d *json5Decoder) key() (string, error) {
+	r, _ := d.peek()
+	if r == '"' || r == '\'' {
+		return d.string()
+	}
+	key := d.identifier()
+	if key == "" {
+		return "", d.errorf("invalid character %q looking for a key", r)
+	}
+	return key, nil
+}
+
+func isIdentifierStart(r rune) bool {
+	return r == '$' || r == '_' || unicode.IsLetter(r) || unicode.Is(unicode.Nl, r)
+}
+
+func isIdentifierPart(r rune) bool {
+	return isIdentifierStart(r) || unicode.IsDigit(r) || unicode.In(r, unicode.Mn, unicode.Mc, unicode.Pc) || r == '\u200c' || r == '\u200d'
+}
+
+// identifier reads an ECMAScript IdentifierName without escapes, it is
+// empty if there is none.
+func (d *json5Decoder) identifier() string {
+	start := d.offset
+	for r, _ := d.peek(); r != -1; r, _ = d.peek() {
+		if d.offset == start && !isIdentifierStart(r) || d.offset > start && !isIdentifierPart(r) {
+			break
+		}
+		d.next()
+	}
+	return string(d.data[start:d.offset])
+}
+
+func (d *json5Decoder) array() (any, error) {
+	out := []any{}
+	d.next()
+	for {
+		if err := d.skipSpace(); err != nil {
+			return nil, err
+		}
+		if r, _ := d.peek(); r == ']' {
+			d.next()
+			return out, nil
+		}
+		val, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, val)
+		if err := d.skipSpace(); err != nil {
+			return nil, err
+		}
+		switch r, _ := d.peek(); r {
+		case ',':
+			d.next()
+		case ']':
+		default:
+			return nil, d.errorf("expected , or ] after an array element")
+		}
+	}
+}
+
+func (d *json5Decoder) hex(digits int) (rune, error) {
+	if d.offset+digits > len(d.data) {
+		return 0, d.errorf("invalid escape")
+	}
+	n, err := strconv.ParseUint(string(d.data[d.offset:d.offset+digits]), 16, 32)
+	if err != nil {
+		return 0, d.errorf("invalid escape")
+	}
+	d.offset += digits
+	return rune(n), nil
+}
+
+var json5Escapes = map[rune]string{
+	'b': "\b", 'f': "\f", 'n': "\n", 'r': "\r", 't': "\t", 'v': "\v",
+}
+
+func (d *json5Decoder) string() (string, error) {
+	quote := d.next()
+	out := strings.Builder{}
+	for {
+		r := d.next()
+		switch {
+		case r == -1:
+			return "", d.errorf("unterminated string")
+		case r == quote:
+			return out.String(), nil
+		case r == '\n' || r == '\r':
+			return "", d.errorf("newline in string")
+		case r != '\\':
+			out.WriteRune(r)
+			continue
+		}
+		esc := d.next()
+		switch {
+		case esc == -1:
+			return "", d.errorf("unterminated string")
+		case json5Escapes[esc] != "":
+			out.WriteString(json5Escapes[esc])
+		case esc == '0':
+			if r, _ := d.peek(); r >= '0' && r <= '9' {
+				return "", d.errorf("octal escapes are not allowed")
+			}
+			out.WriteByte(0)
+		case esc >= '1' && esc <= '9':
+			return "", d.errorf("invalid escape \\%c", esc)
+		case esc == 'x':
+			c, err := d.hex(2)
+			if err != nil {
+				return "", err
+			}
+			out.WriteRune(c)
+		case esc == 'u':
+			c, err := d.hex(4)
+			if err != nil {
+				return "", err
+			}
+			if c >= 0xd800 && c < 0xdc00 && strings.HasPrefix(string(d.data[d.offset:]), "\\u") {
+				d.offset += 2
+				low, err := d.hex(4)
+				if err != nil {
+					return "", err
+				}
+				c = utf16Decode(c, low)
+			}
+			out.WriteRune(c)
+		case esc == '\r':
+			// a line continuation
+			if r, _ := d.peek(); r == '\n' {
+				d.next()
+			}
+		case esc == '\n' || esc == '\u2028' || esc == '\u2029':
+		default:
+			// \' \" \\ \/ and the other characters are themselves
+			out.WriteRune(esc)
+		}
+	}
+}
+
+func utf16Decode(high, low rune) rune {
+	if low < 0xdc00 || low >= 0xe000 {
+		return unicode.ReplacementChar
+	}
+	return (high-0xd800)<<10 + (low - 0xdc00) + 0x10000
+}
+
+func (d *json5Decoder) number() (any, error) {
+	start := d.offset
+	sign := 1.0
+	if r, _ := d.peek(); r == '-' || r == '+' {
+		if r == '-' {
+			sign = -1
+		}
+		d.next()
+	}
+	body := d.offset
+	if word := d.identifier(); word != "" {
+		switch word {
+		case "Infinity":
+			return sign * math.Inf(1), nil
+		case "NaN":
+			return math.NaN(), nil
+		}
+		return nil, d.errorf("invalid number %s", string(d.data[start:d.offset]))
+	}
+	isHex := strings.HasPrefix(strings.ToLower(string(d.data[body:minInt(body+2, len(d.data))])), "0x")
+	if isHex {
+		d.offset += 2
+	}
+	for r, _ := d.peek(); r != -1; r, _ = d.peek() {
+		isDigit := (r >= '0' && r <= '9') || (isHex && strings.ContainsRune("abcdefABCDEF", r))
+		isDecimal := !isHex && (r == '.' || r == 'e' || r == 'E' ||
+			((r == '+' || r == '-') && strings.ContainsAny(string(d.data[d.offset-1]), "eE")))
+		if !isDigit && !isDecimal {
+			break
+		}
+		d.next()
+	}
+	text := string(d.data[body:d.offset])
+	if isHex {
+		n, err := strconv.ParseUint(text[2:], 16, 64)
+		if err != nil {
+			return nil, d.errorf("invalid number %s", string(d.data[start:d.offset]))
+		}
+		return sign * float64(n), nil
+	}
+	// json5 allows 5. and .5
+	f, err := strconv.ParseFloat(text, 64)
+	if err != nil || strings.HasPrefix(text, "0") && len(text) > 1 && text[1] >= '0' && text[1] <= '9' {
+		return nil, d.errorf("invalid number %s", string(d.data[start:d.offset]))
+	}
+	return sign * f, nil
+}
+
+// unmarshalJSON5 decodes the json5 data into v, a JSONDict gets the order
+// and the positions of the members.
+func unmarshalJSON5(data []byte, v interface{}) error {
+	d := &json5Decoder{data: data, line: 1}
+	val, err := d.value()
+	if err != nil {
+		return err
+	}
+	if err := d.skipSpace(); err != nil {
+		return err
+	}
+	if d.offset < len(d.data) {
+		return d.errorf("invalid character after the top-level value")
+	}
+	js, ok := v.(JSONDict)
+	if !ok {
+		bytes, err := json.Marshal(val)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(bytes, v)
+	}
+	dict, ok := val.(JSONDict)
+	if !ok {
+		return fmt.Errorf("expected an object got %s", jsonTypeOf(val))
+	}
+	for _, k := range dict.Keys() {
+		js.Set(k, dict.Get(k))
+		if pos, found := memberPositionOf(dict, k); found {
+			setMemberPosition(js, k, pos)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package entity_generator
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnmarshalJSON5(t *testing.T) {
+	js := NewJSONDict()
+	assert.NoError(t, UnmarshalSchema(SchemaJSON5, []byte(`{type:'object', title: 'x'}`), js))
+	assert.Equal(t, []string{"type", "title"}, js.Keys())
+	assert.Equal(t, "object", js.Get("type"))
+	assert.Equal(t, "x", js.Get("title"))
+
+	js = NewJSONDict()
+	assert.NoError(t, UnmarshalSchema(SchemaJSON5, []byte(`{a:1,b:2}`), js))
+	assert.Equal(t, []string{"a", "b"}, js.Keys())
+	assert.Equal(t, float64(2), js.Get("b"))
+
+	js = NewJSONDict()
+	assert.NoError(t, UnmarshalSchema(SchemaJSON5, []byte(`{ type: 'it\'s', "q": "a \"b\"", e: '\x41é\n', lc: 'a\
+b' }`), js))
+	assert.Equal(t, "it's", js.Get("type"))
+	assert.Equal(t, `a "b"`, js.Get("q"))
+	assert.Equal(t, "Aé\n", js.Get("e"))
+	assert.Equal(t, "ab", js.Get("lc"))
+
+	js = NewJSONDict()
+	assert.NoError(t, UnmarshalSchema(SchemaJSON5, []byte(`// head
+{
+	"a//b": '/*', // c
+	/* multi
+	   line */ $id: "x",
+	n: [0x1F, -.5, +5., 1e3,],
+	t: true, f: false, z: null,
+}`), js))
+	assert.Equal(t, []string{"a//b", "$id", "n", "t", "f", "z"}, js.Keys())
+	assert.Equal(t, "/*", js.Get("a//b"))
+	assert.Equal(t, []any{float64(31), -0.5, float64(5), float64(1000)}, js.Get("n"))
+	assert.Equal(t, SourcePosition{Line: 5, Column: 13}, keyPosition(js, "$id").Value())
+	assert.Equal(t, true, js.Get("t"))
+	assert.Nil(t, js.Get("z"))
+
+	for _, invalid := range []string{`{a 1}`, `{a: 'x}`, `{a: 01}`, `{a: 1} x`, `{a: "b` + "\n" + `"}`, `{/* x`, `[1]`} {
+		assert.Error(t, UnmarshalSchema(SchemaJSON5, []byte(invalid), NewJSONDict()), invalid)
+	}
+	var syntaxErr *json5SyntaxError
+	err := UnmarshalSchema(SchemaJSON5, []byte("{\n  a: 1\n  b: 2\n}"), NewJSONDict())
+	assert.True(t, errors.As(err, &syntaxErr))
+	assert.Equal(t, 3, syntaxErr.Line)
+	assert.Equal(t, 3, syntaxErr.Column)
+}
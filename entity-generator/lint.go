@@ -0,0 +1,353 @@
+package entity_generator
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// LintOff disables a rule in LintOptions.Severities.
+const LintOff Severity = "off"
+
+// LintNode is a schema of the linted file. Name is the property name of
+// the node and empty for the root and for array items.
+type LintNode struct {
+	File    string
+	Pointer string
+	Name    string
+	Schema  JSONDict
+}
+
+func (n LintNode) Type() string {
+	return lintString(n.Schema, "type")
+}
+
+// LintFinding is a problem of a node. Fix changes the schema of the node
+// in place, it is nil if the rule can not fix the problem.
+type LintFinding struct {
+	Pointer string
+	Message string
+	Fix     func()
+}
+
+type LintRule struct {
+	Name        string
+	Description string
+	Severity    Severity
+	Check       func(node LintNode) []LintFinding
+}
+
+type LintOptions struct {
+	Rules []LintRule
+	// Severities overrides the severity of the rules by name.
+	Severities map[string]Severity
+	Fix        bool
+}
+
+type LintResult struct {
+	Diagnostics Diagnostics
+	// Fixed is the number of findings which are fixed in the schema.
+	Fixed int
+}
+
+type linter struct {
+	opts   LintOptions
+	result LintResult
+}
+
+func (l *linter) severity(rule LintRule) Severity {
+	if s, found := l.opts.Severities[rule.Name]; found {
+		return s
+	}
+	return rule.Severity
+}
+
+func (l *linter) check(node LintNode) {
+	for _, rule := range l.opts.Rules {
+		severity := l.severity(rule)
+		if severity == LintOff {
+			continue
+		}
+		for _, f := range rule.Check(node) {
+			if l.opts.Fix && f.Fix != nil {
+				// the next rules see the fixed schema
+				f.Fix()
+				l.result.Fixed++
+				continue
+			}
+			d := NewDiagnostic(rule.Name, "%s", f.Message).at(f.Pointer)
+			d.Severity = severity
+			d.File = node.File
+			l.result.Diagnostics = append(l.result.Diagnostics, d)
+		}
+	}
+}
+
+// walk lints the node and its properties and items. The nested dicts are
+// set again into their parents, new keys of a fix would be lost otherwise.
+func (l *linter) walk(node LintNode) {
+	l.check(node)
+	if v, found := node.Schema.Lookup("properties"); found {
+		if props, ok := AsJSONDict(v); ok {
+			for _, k := range props.Keys() {
+				child, ok := AsJSONDict(props.Get(k))
+				if !ok {
+					continue
+				}
+				l.walk(LintNode{
+					File:    node.File,
+					Pointer: node.Pointer + "/properties/" + EscapeJSONPointer(k),
+					Name:    k,
+					Schema:  child,
+				})
+				props.Set(k, child)
+			}
+			node.Schema.Set("properties", props)
+		}
+	}
+	if v, found := node.Schema.Lookup("items"); found {
+		if items, ok := AsJSONDict(v); ok {
+			l.walk(LintNode{
+				File:    node.File,
+				Pointer: node.Pointer + "/items",
+				Schema:  items,
+			})
+			node.Schema.Set("items", items)
+		}
+	}
+}
+
+// Lint checks the schema js of the file fname with the rules of the
+// options. With Fix the fixable findings are fixed in js and are not
+// reported.
+func Lint(fname string, js JSONDict, opts LintOptions) LintResult {
+	l := &linter{
+		opts:   opts,
+		result: LintResult{Diagnostics: Diagnostics{}},
+	}
+	l.walk(LintNode{File: fname, Schema: js})
+	return l.result
+}
+
+// lintString returns the string attribute, other values are ignored as
+// the linted schema is not validated.
+func lintString(js JSONDict, attr string) string {
+	v := getFromAttributeOptionalString(js, attr)
+	if v.IsNone() {
+		return ""
+	}
+	return v.Value()
+}
+
+func hasProperties(js JSONDict) bool {
+	v, found := js.Lookup("properties")
+	if !found {
+		return false
+	}
+	props, ok := AsJSONDict(v)
+	return ok && props.Len() > 0
+}
+
+// schemaFileStem is the file name without the extension of the format and
+// the .schema before it.
+func schemaFileStem(fname string) string {
+	base := filepath.Base(fname)
+	if _, found := SchemaFormatByExt(base); found {
+		base = strings.TrimSuffix(base, filepath.Ext(base))
+	}
+	return strings.TrimSuffix(base, ".schema")
+}
+
+// lintNodeName is the name a fix derives $id or title from.
+func lintNodeName(node LintNode) string {
+	if title := lintString(node.Schema, "title"); title != "" {
+		return title
+	}
+	if id := lintString(node.Schema, "$id"); id != "" {
+		return schemaFileStem(id[strings.LastIndex(id, "/")+1:])
+	}
+	if node.Name != "" {
+		return node.Name
+	}
+	return schemaFileStem(node.File)
+}
+
+func lintMissingId(node LintNode) []LintFinding {
+	if node.Type() != OBJECT || !hasProperties(node.Schema) || lintString(node.Schema, "$id") != "" {
+		return nil
+	}
+	return []LintFinding{{
+		Pointer: node.Pointer,
+		Message: "object with properties has no $id",
+		Fix: func() {
+			node.Schema.Set("$id", lintNodeName(node))
+		},
+	}}
+}
+
+func lintMissingTitle(node LintNode) []LintFinding {
+	if node.Type() != OBJECT || !hasProperties(node.Schema) || lintString(node.Schema, "title") != "" {
+		return nil
+	}
+	return []LintFinding{{
+		Pointer: node.Pointer,
+		Message: "object with properties has no title and is generated as anonymous type",
+		Fix: func() {
+			node.Schema.Set("title", lintNodeName(node))
+		},
+	}}
+}
+
+func lintUnknownRequired(node LintNode) []LintFinding {
+	v, found := node.Schema.Lookup("required")
+	if !found {
+		return nil
+	}
+	required, ok := v.([]any)
+	if !ok {
+		return nil
+	}
+	props := NewJSONDict()
+	if v, found := node.Schema.Lookup("properties"); found {
+		if p, ok := AsJSONDict(v); ok {
+			props = p
+		}
+	}
+	seen := map[string]bool{}
+	keep := make([]any, 0, len(required))
+	findings := []LintFinding{}
+	for i, r := range required {
+		name := fmt.Sprint(r)
+		pointer := fmt.Sprintf("%s/required/%d", node.Pointer, i)
+		switch _, found := props.Lookup(name); {
+		case seen[name]:
+			findings = append(findings, LintFinding{Pointer: pointer, Message: fmt.Sprintf("required property %s is listed twice", name)})
+		case !found:
+			findings = append(findings, LintFinding{Pointer: pointer, Message: fmt.Sprintf("required property %s is not in properties", name)})
+		default:
+			keep = append(keep, r)
+		}
+		seen[name] = true
+	}
+	fix := func() {
+		node.Schema.Set("required", keep)
+	}
+	for i := range findings {
+		// the first fix removes all, the others have nothing left to do
+		findings[i].Fix = fix
+	}
+	return findings
+}
+
+func lintDefaultOutOfRange(node LintNode) []LintFinding {
+	if node.Type() != INTEGER && node.Type() != NUMBER {
+		return nil
+	}
+	v, found := node.Schema.Lookup("default")
+	if !found {
+		return nil
+	}
+	def, ok := asFloat64(v)
+	if !ok {
+		return nil
+	}
+	findings := []LintFinding{}
+	if v, found := node.Schema.Lookup("minimum"); found {
+		if minimum, ok := asFloat64(v); ok && def < minimum {
+			findings = append(findings, LintFinding{
+				Pointer: node.Pointer + "/default",
+				Message: fmt.Sprintf("default %v is less than the minimum %v", def, minimum),
+			})
+		}
+	}
+	if v, found := node.Schema.Lookup("maximum"); found {
+		if maximum, ok := asFloat64(v); ok && def > maximum {
+			findings = append(findings, LintFinding{
+				Pointer: node.Pointer + "/default",
+				Message: fmt.Sprintf("default %v is greater than the maximum %v", def, maximum),
+			})
+		}
+	}
+	return findings
+}
+
+// knownFormats are the formats the generators map to a type.
+var knownFormats = map[Type][]string{
+	STRING:  {DATE_TIME, DATE, TIME},
+	INTEGER: {"int32", "int64"},
+	NUMBER:  {"float32", "float64"},
+}
+
+func lintUnknownFormat(node LintNode) []LintFinding {
+	format, found := node.Schema.Lookup("format")
+	if !found {
+		return nil
+	}
+	formats := knownFormats[node.Type()]
+	name := fmt.Sprint(format)
+	for _, f := range formats {
+		if f == name {
+			return nil
+		}
+	}
+	finding := LintFinding{
+		Pointer: node.Pointer + "/format",
+		Message: fmt.Sprintf("unknown format %q for type %s (known: %s)", name, node.Type(), strings.Join(formats, ", ")),
+	}
+	for _, f := range formats {
+		if strings.EqualFold(f, name) {
+			known := f
+			finding.Fix = func() {
+				node.Schema.Set("format", known)
+			}
+		}
+	}
+	return []LintFinding{finding}
+}
+
+// LintRules are the rules which apply to every language.
+func LintRules() []LintRule {
+	return []LintRule{
+		{
+			Name:        "missing-id",
+			Description: "objects with properties need an $id",
+			Severity:    SeverityError,
+			Check:       lintMissingId,
+		},
+		{
+			Name:        "missing-title",
+			Description: "objects with properties without title are anonymous types",
+			Severity:    SeverityWarning,
+			Check:       lintMissingTitle,
+		},
+		{
+			Name:        "unknown-required",
+			Description: "required lists properties which do not exist or twice",
+			Severity:    SeverityWarning,
+			Check:       lintUnknownRequired,
+		},
+		{
+			Name:        "default-out-of-range",
+			Description: "the default violates minimum or maximum",
+			Severity:    SeverityError,
+			Check:       lintDefaultOutOfRange,
+		},
+		{
+			Name:        "unknown-format",
+			Description: "the format is not known to the generators",
+			Severity:    SeverityWarning,
+			Check:       lintUnknownFormat,
+		},
+	}
+}
+
+// LintRuleNames returns the sorted names of the rules.
+func LintRuleNames(rules []LintRule) []string {
+	names := make([]string, 0, len(rules))
+	for _, r := range rules {
+		names = append(names, r.Name)
+	}
+	sort.Strings(names)
+	return names
+}
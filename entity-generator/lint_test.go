@@ -0,0 +1,101 @@
+package entity_generator
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const lintTestSchema = `{
+	"type": "object",
+	"properties": {
+		"count": { "type": "integer", "minimum": 1, "default": 0 },
+		"at": { "type": "string", "format": "Date-Time" },
+		"uuid": { "type": "string", "format": "uuid" },
+		"nested": {
+			"type": "object",
+			"properties": { "name": { "type": "string" } }
+		}
+	},
+	"required": ["count", "missing", "count"]
+}`
+
+func lintTestDict(t *testing.T) JSONDict {
+	js := NewJSONDict()
+	assert.NoError(t, json.Unmarshal([]byte(lintTestSchema), js))
+	return js
+}
+
+func lintCodes(ds Diagnostics) []string {
+	out := []string{}
+	for _, d := range ds {
+		out = append(out, string(d.Severity)+" "+d.Code+" #"+d.Pointer)
+	}
+	return out
+}
+
+func TestLint(t *testing.T) {
+	res := Lint("order.schema.json", lintTestDict(t), LintOptions{Rules: LintRules()})
+	assert.Equal(t, 0, res.Fixed)
+	assert.Equal(t, []string{
+		"error missing-id #",
+		"warning missing-title #",
+		"warning unknown-required #/required/1",
+		"warning unknown-required #/required/2",
+		"error default-out-of-range #/properties/count/default",
+		"warning unknown-format #/properties/at/format",
+		"warning unknown-format #/properties/uuid/format",
+		"error missing-id #/properties/nested",
+		"warning missing-title #/properties/nested",
+	}, lintCodes(res.Diagnostics))
+	assert.Equal(t, "order.schema.json", res.Diagnostics[0].File)
+	assert.Equal(t, "default 0 is less than the minimum 1", res.Diagnostics[4].Message)
+
+	res = Lint("order.schema.json", lintTestDict(t), LintOptions{
+		Rules: LintRules(),
+		Severities: map[string]Severity{
+			"missing-title":    LintOff,
+			"unknown-required": SeverityError,
+			"unknown-format":   LintOff,
+			"missing-id":       LintOff,
+		},
+	})
+	assert.Equal(t, []string{
+		"error unknown-required #/required/1",
+		"error unknown-required #/required/2",
+		"error default-out-of-range #/properties/count/default",
+	}, lintCodes(res.Diagnostics))
+}
+
+func TestLintFix(t *testing.T) {
+	js := lintTestDict(t)
+	res := Lint("order.schema.json", js, LintOptions{Rules: LintRules(), Fix: true})
+	assert.Equal(t, 7, res.Fixed)
+	assert.Equal(t, []string{
+		"error default-out-of-range #/properties/count/default",
+		"warning unknown-format #/properties/uuid/format",
+	}, lintCodes(res.Diagnostics))
+	bytes, err := json.Marshal(js)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"type": "object",
+		"properties": {
+			"count": { "type": "integer", "minimum": 1, "default": 0 },
+			"at": { "type": "string", "format": "date-time" },
+			"uuid": { "type": "string", "format": "uuid" },
+			"nested": {
+				"type": "object",
+				"properties": { "name": { "type": "string" } },
+				"$id": "nested",
+				"title": "nested"
+			}
+		},
+		"required": ["count"],
+		"$id": "order",
+		"title": "order"
+	}`, string(bytes))
+
+	res = Lint("order.schema.json", js, LintOptions{Rules: LintRules(), Fix: true})
+	assert.Equal(t, 0, res.Fixed)
+}
@@ -0,0 +1,282 @@
+package entity_generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/mabels/wueste/entity-generator/rusty"
+)
+
+// Output receives the files rendered by the generators.
+type Output interface {
+	WriteFile(fname string, content []byte) error
+	RemoveFile(fname string) error
+}
+
+// DiskOutput writes the files via a temp file and rename. Files which
+// already have the rendered content are not touched.
+type DiskOutput struct{}
+
+func (DiskOutput) WriteFile(fname string, content []byte) error {
+	existing, err := os.ReadFile(fname)
+	if err == nil && bytes.Equal(existing, content) {
+		return nil
+	}
+	dir := filepath.Dir(fname)
+	err = os.MkdirAll(dir, 0755)
+	if err != nil {
+		return err
+	}
+	tmpFname := filepath.Join(dir, "."+filepath.Base(fname)+uuid.New().String())
+	err = os.WriteFile(tmpFname, content, 0644)
+	if err != nil {
+		return err
+	}
+	err = os.Rename(tmpFname, fname)
+	if err != nil {
+		os.Remove(tmpFname)
+	}
+	return err
+}
+
+func (DiskOutput) RemoveFile(fname string) error {
+	err := os.Remove(fname)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// MemoryOutput keeps the files in memory, for the use of the generators
+// as library and in tests.
+type MemoryOutput struct {
+	mutex sync.Mutex
+	files map[string][]byte
+}
+
+func NewMemoryOutput() *MemoryOutput {
+	return &MemoryOutput{
+		files: map[string][]byte{},
+	}
+}
+
+func (o *MemoryOutput) WriteFile(fname string, content []byte) error {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	o.files[filepath.Clean(fname)] = append([]byte{}, content...)
+	return nil
+}
+
+func (o *MemoryOutput) RemoveFile(fname string) error {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	delete(o.files, filepath.Clean(fname))
+	return nil
+}
+
+// File returns the content of the written file.
+func (o *MemoryOutput) File(fname string) rusty.Optional[[]byte] {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	content, found := o.files[filepath.Clean(fname)]
+	if !found {
+		return rusty.None[[]byte]()
+	}
+	return rusty.Some(content)
+}
+
+// Files returns the written files by name.
+func (o *MemoryOutput) Files() map[string][]byte {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	files := make(map[string][]byte, len(o.files))
+	for fname, content := range o.files {
+		files[fname] = content
+	}
+	return files
+}
+
+// FileNames returns the sorted names of the written files.
+func (o *MemoryOutput) FileNames() []string {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	names := make([]string, 0, len(o.files))
+	for fname := range o.files {
+		names = append(names, fname)
+	}
+	sort.Strings(names)
+	return names
+}
+
+type StaleFile struct {
+	FileName string
+	Diff     string
+}
+
+// CheckOutput writes nothing, it compares the rendered files with the
+// files on disk and collects the ones which differ.
+type CheckOutput struct {
+	mutex sync.Mutex
+	stale []StaleFile
+}
+
+func (o *CheckOutput) WriteFile(fname string, content []byte) error {
+	existing, err := os.ReadFile(fname)
+	oldName := "a/" + filepath.ToSlash(fname)
+	if os.IsNotExist(err) {
+		oldName = "/dev/null"
+	} else if err != nil {
+		return err
+	}
+	o.addStale(fname, UnifiedDiff(oldName, "b/"+filepath.ToSlash(fname), existing, content))
+	return nil
+}
+
+func (o *CheckOutput) RemoveFile(fname string) error {
+	existing, err := os.ReadFile(fname)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	o.addStale(fname, UnifiedDiff("a/"+filepath.ToSlash(fname), "/dev/null", existing, nil))
+	return nil
+}
+
+func (o *CheckOutput) addStale(fname string, diff string) {
+	if diff == "" {
+		return
+	}
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	for i, s := range o.stale {
+		if s.FileName == fname {
+			o.stale[i].Diff = diff
+			return
+		}
+	}
+	o.stale = append(o.stale, StaleFile{FileName: fname, Diff: diff})
+}
+
+// Stale returns the differing files sorted by name.
+func (o *CheckOutput) Stale() []StaleFile {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	stale := append([]StaleFile{}, o.stale...)
+	sort.Slice(stale, func(i, j int) bool {
+		return stale[i].FileName < stale[j].FileName
+	})
+	return stale
+}
+
+// the manifest lists the files generated into an output directory
+const ManifestFileName = ".wueste-manifest.json"
+
+type manifest struct {
+	Files []string `json:"files"`
+}
+
+// ManifestOutput records the files written to the wrapped output. The
+// manifest of the previous run is used to remove the outputs of schemas
+// which are gone or renamed.
+type ManifestOutput struct {
+	Output Output
+	mutex  sync.Mutex
+	files  map[string]bool
+}
+
+func NewManifestOutput(out Output) *ManifestOutput {
+	return &ManifestOutput{
+		Output: out,
+		files:  map[string]bool{},
+	}
+}
+
+func (o *ManifestOutput) WriteFile(fname string, content []byte) error {
+	abs, err := filepath.Abs(fname)
+	if err != nil {
+		return err
+	}
+	o.mutex.Lock()
+	o.files[abs] = true
+	o.mutex.Unlock()
+	return o.Output.WriteFile(fname, content)
+}
+
+func (o *ManifestOutput) RemoveFile(fname string) error {
+	return o.Output.RemoveFile(fname)
+}
+
+// Files returns the written files below outputDir relative to it.
+func (o *ManifestOutput) Files(outputDir string) []string {
+	absDir, err := filepath.Abs(outputDir)
+	if err != nil {
+		return nil
+	}
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	files := []string{}
+	for fname := range o.files {
+		rel, err := filepath.Rel(absDir, fname)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		files = append(files, filepath.ToSlash(rel))
+	}
+	sort.Strings(files)
+	return files
+}
+
+// Commit removes the files of the previous manifest of outputDir which are
+// not written anymore and writes the new manifest. It returns the removed
+// files.
+func (o *ManifestOutput) Commit(outputDir string) rusty.Result[[]string] {
+	fname := filepath.Join(outputDir, ManifestFileName)
+	files := o.Files(outputDir)
+	written := map[string]bool{}
+	for _, file := range files {
+		written[file] = true
+	}
+	removed := []string{}
+	bytes, err := os.ReadFile(fname)
+	if err == nil {
+		var prev manifest
+		err = json.Unmarshal(bytes, &prev)
+		if err != nil {
+			return rusty.Err[[]string](fmt.Errorf("%s: %w", fname, err))
+		}
+		for _, file := range prev.Files {
+			rel := filepath.Clean(filepath.FromSlash(file))
+			if written[filepath.ToSlash(rel)] || filepath.IsAbs(rel) || strings.HasPrefix(rel, "..") {
+				// never touch files outside of the output directory
+				continue
+			}
+			if _, err := os.Stat(filepath.Join(outputDir, rel)); err != nil {
+				continue
+			}
+			err = o.Output.RemoveFile(filepath.Join(outputDir, rel))
+			if err != nil {
+				return rusty.Err[[]string](err)
+			}
+			removed = append(removed, filepath.Join(outputDir, rel))
+		}
+	} else if !os.IsNotExist(err) {
+		return rusty.Err[[]string](err)
+	}
+	bytes, err = json.MarshalIndent(manifest{Files: files}, "", "  ")
+	if err != nil {
+		return rusty.Err[[]string](err)
+	}
+	err = o.Output.WriteFile(fname, append(bytes, '\n'))
+	if err != nil {
+		return rusty.Err[[]string](err)
+	}
+	return rusty.Ok(removed)
+}
@@ -0,0 +1,99 @@
+package entity_generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiskOutputKeepsUnchanged(t *testing.T) {
+	fname := filepath.Join(t.TempDir(), "sub", "x.ts")
+	assert.NoError(t, DiskOutput{}.WriteFile(fname, []byte("x\n")))
+	mtime := time.Now().Add(-time.Hour).Truncate(time.Second)
+	assert.NoError(t, os.Chtimes(fname, mtime, mtime))
+
+	assert.NoError(t, DiskOutput{}.WriteFile(fname, []byte("x\n")))
+	stat, _ := os.Stat(fname)
+	assert.Equal(t, mtime, stat.ModTime())
+
+	assert.NoError(t, DiskOutput{}.WriteFile(fname, []byte("y\n")))
+	bytes, _ := os.ReadFile(fname)
+	assert.Equal(t, "y\n", string(bytes))
+	entries, _ := os.ReadDir(filepath.Dir(fname))
+	assert.Len(t, entries, 1)
+}
+
+func TestCheckOutput(t *testing.T) {
+	dir := t.TempDir()
+	same := filepath.Join(dir, "same.ts")
+	assert.NoError(t, os.WriteFile(same, []byte("x\n"), 0644))
+	changed := filepath.Join(dir, "changed.ts")
+	assert.NoError(t, os.WriteFile(changed, []byte("x\n"), 0644))
+
+	out := &CheckOutput{}
+	assert.NoError(t, out.WriteFile(same, []byte("x\n")))
+	assert.NoError(t, out.WriteFile(changed, []byte("y\n")))
+	assert.NoError(t, out.WriteFile(filepath.Join(dir, "missing.ts"), []byte("z\n")))
+	stale := out.Stale()
+	assert.Equal(t, 2, len(stale))
+	assert.Equal(t, changed, stale[0].FileName)
+	assert.Contains(t, stale[0].Diff, "-x\n+y\n")
+	assert.Contains(t, stale[1].Diff, "--- /dev/null\n")
+	bytes, _ := os.ReadFile(changed)
+	assert.Equal(t, "x\n", string(bytes))
+	_, err := os.Stat(filepath.Join(dir, "missing.ts"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestManifestOutputRemovesOrphans(t *testing.T) {
+	dir := t.TempDir()
+	outside := filepath.Join(dir, "outside.ts")
+	assert.NoError(t, os.WriteFile(outside, []byte("keep\n"), 0644))
+	outDir := filepath.Join(dir, "out")
+
+	out := NewManifestOutput(DiskOutput{})
+	assert.NoError(t, out.WriteFile(filepath.Join(outDir, "a.ts"), []byte("a\n")))
+	assert.NoError(t, out.WriteFile(filepath.Join(outDir, "sub", "b.ts"), []byte("b\n")))
+	assert.NoError(t, out.WriteFile(outside, []byte("keep\n")))
+	assert.Empty(t, out.Commit(outDir).Ok())
+	bytes, err := os.ReadFile(filepath.Join(outDir, ManifestFileName))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"files":["a.ts","sub/b.ts"]}`, string(bytes))
+
+	// b.ts is not generated anymore
+	out = NewManifestOutput(DiskOutput{})
+	assert.NoError(t, out.WriteFile(filepath.Join(outDir, "a.ts"), []byte("a\n")))
+	assert.Equal(t, []string{filepath.Join(outDir, "sub", "b.ts")}, out.Commit(outDir).Ok())
+	assert.NoFileExists(t, filepath.Join(outDir, "sub", "b.ts"))
+	assert.FileExists(t, filepath.Join(outDir, "a.ts"))
+	assert.FileExists(t, outside)
+
+	// a manifest never removes files outside of the output directory
+	assert.NoError(t, os.WriteFile(filepath.Join(outDir, ManifestFileName), []byte(`{"files":["../outside.ts"]}`), 0644))
+	assert.Empty(t, NewManifestOutput(DiskOutput{}).Commit(outDir).Ok())
+	assert.FileExists(t, outside)
+
+	// check reports the removal without removing
+	assert.NoError(t, os.WriteFile(filepath.Join(outDir, ManifestFileName), []byte(`{"files":["a.ts"]}`), 0644))
+	check := &CheckOutput{}
+	NewManifestOutput(check).Commit(outDir)
+	assert.FileExists(t, filepath.Join(outDir, "a.ts"))
+	assert.Equal(t, 2, len(check.Stale()))
+	assert.Contains(t, check.Stale()[1].Diff, "+++ /dev/null\n")
+}
+
+func TestMemoryOutput(t *testing.T) {
+	out := NewMemoryOutput()
+	content := []byte("a")
+	assert.NoError(t, out.WriteFile("gen/b.ts", content))
+	assert.NoError(t, out.WriteFile("gen/./a.ts", []byte("b")))
+	content[0] = 'x'
+	assert.Equal(t, []string{"gen/a.ts", "gen/b.ts"}, out.FileNames())
+	assert.Equal(t, []byte("a"), out.File("gen/b.ts").Value())
+	assert.NoError(t, out.RemoveFile("gen/b.ts"))
+	assert.True(t, out.File("gen/b.ts").IsNone())
+	assert.Equal(t, map[string][]byte{"gen/a.ts": []byte("b")}, out.Files())
+}
@@ -0,0 +1,214 @@
+// Package plugin runs generator plugins, go files which are interpreted by
+// yaegi after the generators have rendered the entities of a target:
+//
+//	package myplugin
+//
+//	import (
+//		eg "github.com/mabels/wueste/entity-generator"
+//		"github.com/mabels/wueste/entity-generator/plugin"
+//	)
+//
+//	func Generate(ctx *plugin.Context) error {
+//		...
+//	}
+//
+// Besides the standard library a plugin can import the entity generator
+// and this package.
+package plugin
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	eg "github.com/mabels/wueste/entity-generator"
+	"github.com/mabels/wueste/entity-generator/rusty"
+	"github.com/traefik/yaegi/interp"
+	"github.com/traefik/yaegi/stdlib"
+)
+
+// Context is the argument of the Generate func of a plugin, it is called
+// once for every schema in the order of the inputs.
+type Context struct {
+	Config *eg.GeneratorConfig
+	// Entity is the property of the schema.
+	Entity eg.Property
+	// Files are the files of all entities of the target, the plugin adds
+	// files or changes the rendered ones. The files are shared by the
+	// entities, so a plugin can append each entity to a common file.
+	Files *Files
+}
+
+// Writer returns a ForIfWhileLangWriter with the indent of the config.
+func (ctx *Context) Writer() *eg.ForIfWhileLangWriter {
+	return eg.NewForIfWhileLangWriter(eg.ForIfWhileLangWriter{OfsIndent: ctx.Config.EntityCfg.Indent})
+}
+
+// Files is the Output of a generator run, it keeps the files until they
+// are flushed to the Output of the config.
+type Files struct {
+	mutex   sync.Mutex
+	files   map[string][]byte
+	removed map[string]bool
+}
+
+func NewFiles() *Files {
+	return &Files{
+		files:   map[string][]byte{},
+		removed: map[string]bool{},
+	}
+}
+
+func (f *Files) WriteFile(fname string, content []byte) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	fname = filepath.Clean(fname)
+	f.files[fname] = append([]byte{}, content...)
+	delete(f.removed, fname)
+	return nil
+}
+
+func (f *Files) RemoveFile(fname string) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	fname = filepath.Clean(fname)
+	delete(f.files, fname)
+	f.removed[fname] = true
+	return nil
+}
+
+// Names returns the sorted names of the files.
+func (f *Files) Names() []string {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	names := make([]string, 0, len(f.files))
+	for fname := range f.files {
+		names = append(names, fname)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Read returns the content of the file fname.
+func (f *Files) Read(fname string) (string, bool) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	content, found := f.files[filepath.Clean(fname)]
+	return string(content), found
+}
+
+// Write replaces the content of the file fname.
+func (f *Files) Write(fname string, content string) {
+	f.WriteFile(fname, []byte(content))
+}
+
+// Clone returns a copy of the files.
+func (f *Files) Clone() *Files {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	c := NewFiles()
+	for fname, content := range f.files {
+		c.files[fname] = append([]byte{}, content...)
+	}
+	for fname := range f.removed {
+		c.removed[fname] = true
+	}
+	return c
+}
+
+// Append adds content to the end of the file fname.
+func (f *Files) Append(fname string, content string) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	fname = filepath.Clean(fname)
+	f.files[fname] = append(f.files[fname], content...)
+	delete(f.removed, fname)
+}
+
+// AppendLines adds the lines of a ForIfWhileLangWriter to the file fname.
+func (f *Files) AppendLines(fname string, wr *eg.ForIfWhileLangWriter) {
+	f.Append(fname, strings.Join(wr.Lines(), ""))
+}
+
+// Flush writes the files to out in the order of their names.
+func (f *Files) Flush(out eg.Output) error {
+	for _, fname := range f.Names() {
+		content, _ := f.Read(fname)
+		if err := out.WriteFile(fname, []byte(content)); err != nil {
+			return err
+		}
+	}
+	f.mutex.Lock()
+	removed := make([]string, 0, len(f.removed))
+	for fname := range f.removed {
+		removed = append(removed, fname)
+	}
+	f.mutex.Unlock()
+	sort.Strings(removed)
+	for _, fname := range removed {
+		if err := out.RemoveFile(fname); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Plugin is a loaded plugin file, the calls of Generate are serialized.
+type Plugin struct {
+	FileName string
+	mutex    sync.Mutex
+	generate func(ctx *Context) error
+}
+
+// Load interprets the plugin file fname.
+func Load(fname string) rusty.Result[*Plugin] {
+	src, err := os.ReadFile(fname)
+	if err != nil {
+		return rusty.Err[*Plugin](err)
+	}
+	file, err := parser.ParseFile(token.NewFileSet(), fname, src, parser.PackageClauseOnly)
+	if err != nil {
+		return rusty.Err[*Plugin](err)
+	}
+	i := interp.New(interp.Options{})
+	for _, symbols := range []interp.Exports{stdlib.Symbols, Symbols} {
+		if err := i.Use(symbols); err != nil {
+			return rusty.Err[*Plugin](err)
+		}
+	}
+	_, err = i.EvalPath(fname)
+	if err != nil {
+		return rusty.Err[*Plugin](fmt.Errorf("%s: %w", fname, err))
+	}
+	fn, err := i.Eval(file.Name.Name + ".Generate")
+	if err != nil {
+		return rusty.Err[*Plugin](fmt.Errorf("%s: missing func Generate(ctx *plugin.Context) error", fname))
+	}
+	generate, ok := fn.Interface().(func(ctx *Context) error)
+	if !ok {
+		return rusty.Err[*Plugin](fmt.Errorf("%s: Generate is %s, expected func(ctx *plugin.Context) error", fname, fn.Type()))
+	}
+	return rusty.Ok(&Plugin{FileName: fname, generate: generate})
+}
+
+// Generate calls the Generate func of the plugin, a panic of the plugin
+// is returned as error.
+func (p *Plugin) Generate(ctx *Context) (err error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("plugin %s: %v", p.FileName, r)
+		}
+	}()
+	err = p.generate(ctx)
+	if err != nil {
+		return fmt.Errorf("plugin %s: %w", p.FileName, err)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package plugin
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	eg "github.com/mabels/wueste/entity-generator"
+	"github.com/stretchr/testify/assert"
+)
+
+const namesPlugin = `package names
+
+import (
+	"path/filepath"
+	"strings"
+
+	eg "github.com/mabels/wueste/entity-generator"
+	"github.com/mabels/wueste/entity-generator/plugin"
+)
+
+func Generate(ctx *plugin.Context) error {
+	obj, ok := ctx.Entity.(eg.PropertyObject)
+	if !ok {
+		return nil
+	}
+	names := []string{}
+	for _, item := range obj.Items() {
+		if item.Property().Type() == eg.STRING {
+			names = append(names, item.Name())
+		}
+	}
+	wr := ctx.Writer()
+	wr.WriteBlock("export const "+obj.Title()+"Strings =", "", func(wr *eg.ForIfWhileLangWriter) {
+		for _, name := range names {
+			wr.FormatLine("%q,", name)
+		}
+	}, " [", "];")
+	for _, fname := range ctx.Files.Names() {
+		ctx.Files.AppendLines(fname, wr)
+	}
+	ctx.Files.Append(filepath.Join(ctx.Config.OutputDir, "registry.txt"), strings.ToLower(obj.Title())+"\n")
+	return nil
+}
+`
+
+func writePlugin(t *testing.T, src string) string {
+	fname := filepath.Join(t.TempDir(), "plugin.go")
+	assert.NoError(t, os.WriteFile(fname, []byte(src), 0644))
+	return fname
+}
+
+func TestPluginGenerate(t *testing.T) {
+	rPlugin := Load(writePlugin(t, namesPlugin))
+	assert.False(t, rPlugin.IsErr())
+
+	fsys := fstest.MapFS{
+		"base.schema.json": {Data: []byte(`{
+	"$id": "https://Base", "title": "Base", "type": "object",
+	"properties": { "name": { "type": "string" }, "count": { "type": "integer" }, "label": { "type": "string" } }
+}`)},
+	}
+	sl := eg.PropertyCtx{Registry: eg.NewSchemaRegistry(eg.NewFSSchemaLoader(fsys))}
+	files := NewFiles()
+	cfg := &eg.GeneratorConfig{
+		OutputDir: "generated",
+		EntityCfg: eg.Config{Indent: "  "},
+		Output:    files,
+		Log:       io.Discard,
+	}
+	assert.NoError(t, files.WriteFile("generated/base.ts", []byte("// base\n")))
+	ctx := &Context{Config: cfg, Entity: eg.LoadSchemaFile(sl, "base.schema.json").Ok(), Files: files}
+	assert.NoError(t, rPlugin.Ok().Generate(ctx))
+
+	out := eg.NewMemoryOutput()
+	assert.NoError(t, files.Flush(out))
+	assert.Equal(t, []string{"generated/base.ts", "generated/registry.txt"}, out.FileNames())
+	assert.Equal(t, "// base\nexport const BaseStrings = [\n  \"name\",\n  \"label\",\n];\n",
+		string(out.File("generated/base.ts").Value()))
+	assert.Equal(t, "base\n", string(out.File("generated/registry.txt").Value()))
+}
+
+func TestPluginErrors(t *testing.T) {
+	assert.Error(t, Load(filepath.Join(t.TempDir(), "missing.go")).Err())
+	assert.ErrorContains(t, Load(writePlugin(t, "package p\n\nfunc Other() {}\n")).Err(), "missing func Generate")
+	assert.ErrorContains(t, Load(writePlugin(t, "package p\n\nfunc Generate() {}\n")).Err(), "expected func(ctx *plugin.Context) error")
+	assert.Error(t, Load(writePlugin(t, "package p\n\nfunc Generate(\n")).Err())
+
+	rPlugin := Load(writePlugin(t, `package p
+
+import (
+	"errors"
+
+	"github.com/mabels/wueste/entity-generator/plugin"
+)
+
+func Generate(ctx *plugin.Context) error {
+	if ctx.Entity == nil {
+		panic("no entity")
+	}
+	return errors.New("failed")
+}
+`))
+	assert.False(t, rPlugin.IsErr())
+	assert.ErrorContains(t, rPlugin.Ok().Generate(&Context{}), "no entity")
+	fsys := fstest.MapFS{"a.schema.json": {Data: []byte(`{"$id": "https://A", "title": "A", "type": "object", "properties": {}}`)}}
+	sl := eg.PropertyCtx{Registry: eg.NewSchemaRegistry(eg.NewFSSchemaLoader(fsys))}
+	assert.ErrorContains(t, rPlugin.Ok().Generate(&Context{Entity: eg.LoadSchemaFile(sl, "a.schema.json").Ok()}), "failed")
+}
+
+func TestFiles(t *testing.T) {
+	files := NewFiles()
+	files.Write("a", "1")
+	files.Append("a", "2")
+	files.Append("./b", "3")
+	assert.NoError(t, files.RemoveFile("c"))
+	assert.Equal(t, []string{"a", "b"}, files.Names())
+	content, found := files.Read("a")
+	assert.True(t, found)
+	assert.Equal(t, "12", content)
+
+	out := eg.NewMemoryOutput()
+	assert.NoError(t, out.WriteFile("c", []byte("x")))
+	assert.NoError(t, files.Flush(out))
+	assert.Equal(t, []string{"a", "b"}, out.FileNames())
+}
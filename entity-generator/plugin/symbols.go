@@ -0,0 +1,54 @@
+package plugin
+
+import (
+	"reflect"
+
+	eg "github.com/mabels/wueste/entity-generator"
+	"github.com/traefik/yaegi/interp"
+)
+
+// Symbols are the packages of the entity generator which plugins import,
+// the generic funcs are left out as the interpreter can't instantiate them.
+var Symbols = interp.Exports{
+	"github.com/mabels/wueste/entity-generator/entity_generator": {
+		// types
+		"Config":               reflect.ValueOf((*eg.Config)(nil)),
+		"ExtensionValue":       reflect.ValueOf((*eg.ExtensionValue)(nil)),
+		"ForIfWhileLangWriter": reflect.ValueOf((*eg.ForIfWhileLangWriter)(nil)),
+		"GeneratorConfig":      reflect.ValueOf((*eg.GeneratorConfig)(nil)),
+		"JSONDict":             reflect.ValueOf((*eg.JSONDict)(nil)),
+		"Output":               reflect.ValueOf((*eg.Output)(nil)),
+		"Property":             reflect.ValueOf((*eg.Property)(nil)),
+		"PropertyArray":        reflect.ValueOf((*eg.PropertyArray)(nil)),
+		"PropertyBoolean":      reflect.ValueOf((*eg.PropertyBoolean)(nil)),
+		"PropertyInteger":      reflect.ValueOf((*eg.PropertyInteger)(nil)),
+		"PropertyItem":         reflect.ValueOf((*eg.PropertyItem)(nil)),
+		"PropertyMeta":         reflect.ValueOf((*eg.PropertyMeta)(nil)),
+		"PropertyNumber":       reflect.ValueOf((*eg.PropertyNumber)(nil)),
+		"PropertyObject":       reflect.ValueOf((*eg.PropertyObject)(nil)),
+		"PropertyRecursive":    reflect.ValueOf((*eg.PropertyRecursive)(nil)),
+		"PropertyString":       reflect.ValueOf((*eg.PropertyString)(nil)),
+		"Type":                 reflect.ValueOf((*eg.Type)(nil)),
+
+		// consts
+		"ARRAY":      reflect.ValueOf(eg.ARRAY),
+		"ARRAYITEM":  reflect.ValueOf(eg.ARRAYITEM),
+		"BOOLEAN":    reflect.ValueOf(eg.BOOLEAN),
+		"INTEGER":    reflect.ValueOf(eg.INTEGER),
+		"NUMBER":     reflect.ValueOf(eg.NUMBER),
+		"OBJECT":     reflect.ValueOf(eg.OBJECT),
+		"OBJECTITEM": reflect.ValueOf(eg.OBJECTITEM),
+		"STRING":     reflect.ValueOf(eg.STRING),
+
+		// funcs
+		"IsEntityRoot":            reflect.ValueOf(eg.IsEntityRoot),
+		"IsRecursiveRef":          reflect.ValueOf(eg.IsRecursiveRef),
+		"NewForIfWhileLangWriter": reflect.ValueOf(eg.NewForIfWhileLangWriter),
+		"PropertyToJson":          reflect.ValueOf(eg.PropertyToJson),
+	},
+	"github.com/mabels/wueste/entity-generator/plugin/plugin": {
+		"Context":  reflect.ValueOf((*Context)(nil)),
+		"Files":    reflect.ValueOf((*Files)(nil)),
+		"NewFiles": reflect.ValueOf(NewFiles),
+	},
+}
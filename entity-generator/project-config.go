@@ -0,0 +1,322 @@
+package entity_generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mabels/wueste/entity-generator/rusty"
+	"gopkg.in/yaml.v3"
+)
+
+// the project config files which are looked up in the working directory
+var ProjectConfigFileNames = []string{"wueste.json", "wueste.yaml", "wueste.yml"}
+
+// ConfigError reports a problem of the project config at its location.
+type ConfigError struct {
+	FileName string
+	Line     int
+	Column   int
+	Message  string
+}
+
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("%s:%d:%d: %s", e.FileName, e.Line, e.Column, e.Message)
+}
+
+type ConfigErrors []*ConfigError
+
+func (e ConfigErrors) Error() string {
+	out := make([]string, 0, len(e))
+	for _, err := range e {
+		out = append(out, err.Error())
+	}
+	return strings.Join(out, "\n")
+}
+
+type ProjectTarget struct {
+	Name   string
+	Config GeneratorConfig
+}
+
+// ProjectConfig is a wueste.json or wueste.yaml which holds the flags of
+// the generate command. The keys on the top level are the defaults of the
+// targets, without targets the top level is the only target:
+//
+//	includeDirs: [schemas]
+//	targets:
+//	  - name: web
+//	    language: ts
+//	    outputDir: web/src/generated
+//	    fromWueste: wueste
+//	    inputFiles: [schemas/*.schema.json]
+//
+// Relative paths and globs are resolved from the directory of the file.
+type ProjectConfig struct {
+	FileName string
+	Targets  []ProjectTarget
+}
+
+func FindProjectConfig(dir string) rusty.Optional[string] {
+	for _, name := range ProjectConfigFileNames {
+		fname := filepath.Join(dir, name)
+		if _, err := os.Stat(fname); err == nil {
+			return rusty.Some(fname)
+		}
+	}
+	return rusty.None[string]()
+}
+
+type configParser struct {
+	fileName string
+	baseDir  string
+	errors   ConfigErrors
+}
+
+func (p *configParser) errorf(node *yaml.Node, format string, args ...any) {
+	p.errors = append(p.errors, &ConfigError{
+		FileName: p.fileName,
+		Line:     node.Line,
+		Column:   node.Column,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (p *configParser) path(fname string) string {
+	if filepath.IsAbs(fname) {
+		return fname
+	}
+	return filepath.Join(p.baseDir, fname)
+}
+
+func (p *configParser) stringValue(key string, node *yaml.Node) rusty.Optional[string] {
+	if node.Kind != yaml.ScalarNode || node.Tag != "!!str" {
+		p.errorf(node, "%s must be a string", key)
+		return rusty.None[string]()
+	}
+	return rusty.Some(node.Value)
+}
+
+// choiceValue returns the string value of key if it is one of choices.
+func (p *configParser) choiceValue(key string, node *yaml.Node, choices []string) rusty.Optional[string] {
+	v := p.stringValue(key, node)
+	if v.IsSome() {
+		if err := checkChoice(key, v.Value(), choices); err != nil {
+			p.errorf(node, "%v", err)
+			return rusty.None[string]()
+		}
+	}
+	return v
+}
+
+func (p *configParser) boolValue(key string, node *yaml.Node) rusty.Optional[bool] {
+	if node.Kind != yaml.ScalarNode || node.Tag != "!!bool" {
+		p.errorf(node, "%s must be a boolean", key)
+		return rusty.None[bool]()
+	}
+	return rusty.Some(node.Value == "true")
+}
+
+func (p *configParser) stringList(key string, node *yaml.Node) []*yaml.Node {
+	if node.Kind != yaml.SequenceNode {
+		p.errorf(node, "%s must be a list of strings", key)
+		return nil
+	}
+	out := []*yaml.Node{}
+	for _, item := range node.Content {
+		if p.stringValue(key, item).IsSome() {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// globs expands the input patterns and directories.
+func (p *configParser) globs(key string, node *yaml.Node) []string {
+	out := []string{}
+	for _, item := range p.stringList(key, node) {
+		rFiles := ExpandInputFiles([]string{p.path(item.Value)})
+		if rFiles.IsErr() {
+			p.errorf(item, "%s %s: %v", key, item.Value, rFiles.Err())
+			continue
+		}
+		out = append(out, rFiles.Ok()...)
+	}
+	return out
+}
+
+type configField func(p *configParser, key string, node *yaml.Node, target *ProjectTarget)
+
+var generatorConfigFields = map[string]configField{
+	"includeDirs": func(p *configParser, key string, node *yaml.Node, target *ProjectTarget) {
+		dirs := []string{}
+		for _, item := range p.stringList(key, node) {
+			dirs = append(dirs, p.path(item.Value))
+		}
+		target.Config.IncludeDirs = dirs
+	},
+	"outputDir": func(p *configParser, key string, node *yaml.Node, target *ProjectTarget) {
+		if dir := p.stringValue(key, node); dir.IsSome() {
+			target.Config.OutputDir = p.path(dir.Value())
+		}
+	},
+	"inputFiles": func(p *configParser, key string, node *yaml.Node, target *ProjectTarget) {
+		target.Config.InputFiles = p.globs(key, node)
+	},
+	"plugins": func(p *configParser, key string, node *yaml.Node, target *ProjectTarget) {
+		plugins := []string{}
+		for _, item := range p.stringList(key, node) {
+			plugins = append(plugins, p.path(item.Value))
+		}
+		target.Config.Plugins = plugins
+	},
+	"writeTestSchema": func(p *configParser, key string, node *yaml.Node, target *ProjectTarget) {
+		if v := p.boolValue(key, node); v.IsSome() {
+			target.Config.WriteTestSchema = v.Value()
+		}
+	},
+	"language": func(p *configParser, key string, node *yaml.Node, target *ProjectTarget) {
+		if v := p.stringValue(key, node); v.IsSome() {
+			target.Config.EntityCfg.Language = v.Value()
+		}
+	},
+	"indent": func(p *configParser, key string, node *yaml.Node, target *ProjectTarget) {
+		if v := p.stringValue(key, node); v.IsSome() {
+			target.Config.EntityCfg.Indent = v.Value()
+		}
+	},
+	"packageName": func(p *configParser, key string, node *yaml.Node, target *ProjectTarget) {
+		if v := p.stringValue(key, node); v.IsSome() {
+			target.Config.EntityCfg.PackageName = v.Value()
+		}
+	},
+	"templateDir": func(p *configParser, key string, node *yaml.Node, target *ProjectTarget) {
+		if dir := p.stringValue(key, node); dir.IsSome() {
+			target.Config.EntityCfg.TemplateDir = p.path(dir.Value())
+		}
+	},
+	"layout": func(p *configParser, key string, node *yaml.Node, target *ProjectTarget) {
+		if v := p.choiceValue(key, node, Layouts); v.IsSome() {
+			target.Config.EntityCfg.Layout = v.Value()
+		}
+	},
+	"index": func(p *configParser, key string, node *yaml.Node, target *ProjectTarget) {
+		if v := p.boolValue(key, node); v.IsSome() {
+			target.Config.EntityCfg.Index = v.Value()
+		}
+	},
+	"fileCase": func(p *configParser, key string, node *yaml.Node, target *ProjectTarget) {
+		if v := p.choiceValue(key, node, FileCases); v.IsSome() {
+			target.Config.EntityCfg.FileCase = v.Value()
+		}
+	},
+	"importExtension": func(p *configParser, key string, node *yaml.Node, target *ProjectTarget) {
+		if v := p.choiceValue(key, node, ImportExtensions); v.IsSome() {
+			target.Config.EntityCfg.ImportExtension = v.Value()
+		}
+	},
+	"typeImports": func(p *configParser, key string, node *yaml.Node, target *ProjectTarget) {
+		if v := p.boolValue(key, node); v.IsSome() {
+			target.Config.EntityCfg.TypeImports = v.Value()
+		}
+	},
+	"fromWueste": func(p *configParser, key string, node *yaml.Node, target *ProjectTarget) {
+		// an import path of the generated code, it is not resolved
+		if v := p.stringValue(key, node); v.IsSome() {
+			target.Config.EntityCfg.FromWueste = v.Value()
+		}
+	},
+}
+
+func (p *configParser) mapping(node *yaml.Node, target *ProjectTarget, extra map[string]configField) {
+	if node.Kind != yaml.MappingNode {
+		p.errorf(node, "expected an object")
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		field, found := extra[key.Value]
+		if !found {
+			field, found = generatorConfigFields[key.Value]
+		}
+		if !found {
+			p.errorf(key, "unknown key %s", key.Value)
+			continue
+		}
+		field(p, key.Value, value, target)
+	}
+}
+
+func (p *configParser) targets(node *yaml.Node, defaults ProjectTarget) []ProjectTarget {
+	var targetsNode *yaml.Node
+	p.mapping(node, &defaults, map[string]configField{
+		"targets": func(p *configParser, key string, node *yaml.Node, target *ProjectTarget) {
+			targetsNode = node
+		},
+	})
+	if targetsNode == nil {
+		return []ProjectTarget{defaults}
+	}
+	if targetsNode.Kind != yaml.SequenceNode {
+		p.errorf(targetsNode, "targets must be a list")
+		return nil
+	}
+	names := map[string]bool{}
+	targets := []ProjectTarget{}
+	for _, targetNode := range targetsNode.Content {
+		target := defaults
+		target.Name = ""
+		p.mapping(targetNode, &target, map[string]configField{
+			"name": func(p *configParser, key string, node *yaml.Node, target *ProjectTarget) {
+				if v := p.stringValue(key, node); v.IsSome() {
+					target.Name = v.Value()
+				}
+			},
+		})
+		if target.Name == "" {
+			target.Name = target.Config.EntityCfg.Language
+		}
+		if names[target.Name] {
+			p.errorf(targetNode, "duplicate target %s", target.Name)
+		}
+		names[target.Name] = true
+		targets = append(targets, target)
+	}
+	return targets
+}
+
+// LoadProjectConfig reads a project config in json or yaml, the values
+// which are not set in the file are taken from defaults.
+func LoadProjectConfig(fname string, defaults GeneratorConfig) rusty.Result[*ProjectConfig] {
+	bytes, err := os.ReadFile(fname)
+	if err != nil {
+		return rusty.Err[*ProjectConfig](err)
+	}
+	// json is a subset of yaml, so both are read as yaml to get the locations
+	var doc yaml.Node
+	err = yaml.Unmarshal(bytes, &doc)
+	if err != nil {
+		return rusty.Err[*ProjectConfig](fmt.Errorf("%s: %w", fname, err))
+	}
+	absFname, err := filepath.Abs(fname)
+	if err != nil {
+		return rusty.Err[*ProjectConfig](err)
+	}
+	p := &configParser{
+		fileName: fname,
+		baseDir:  filepath.Dir(absFname),
+	}
+	if len(doc.Content) == 0 {
+		p.errorf(&doc, "empty project config")
+		return rusty.Err[*ProjectConfig](p.errors)
+	}
+	targets := p.targets(doc.Content[0], ProjectTarget{Name: defaults.EntityCfg.Language, Config: defaults})
+	if len(p.errors) > 0 {
+		return rusty.Err[*ProjectConfig](p.errors)
+	}
+	return rusty.Ok(&ProjectConfig{
+		FileName: fname,
+		Targets:  targets,
+	})
+}
@@ -0,0 +1,115 @@
+package entity_generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeProjectFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		fname := filepath.Join(dir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(fname), 0755))
+		assert.NoError(t, os.WriteFile(fname, []byte(content), 0644))
+	}
+	return dir
+}
+
+func TestProjectConfigYaml(t *testing.T) {
+	dir := writeProjectFiles(t, map[string]string{
+		"schemas/a.schema.json": "{}",
+		"schemas/b.schema.json": "{}",
+		"wueste.yaml": `
+includeDirs: [schemas]
+fromWueste: wueste
+targets:
+  - name: web
+    outputDir: web/generated
+    templateDir: templates
+    layout: mirror
+    index: true
+    fileCase: kebab
+    importExtension: .js
+    typeImports: true
+    inputFiles: [schemas/*.schema.json]
+  - language: avro
+    outputDir: avro
+    packageName: com.example
+    inputFiles: [schemas/b.schema.json]
+    plugins: [plugins/avro.go]
+`,
+	})
+	defaults := GeneratorConfig{OutputDir: "./", EntityCfg: Config{Language: "ts", Indent: "  "}}
+	project := LoadProjectConfig(filepath.Join(dir, "wueste.yaml"), defaults).Ok()
+	assert.Equal(t, 2, len(project.Targets))
+
+	web := project.Targets[0]
+	assert.Equal(t, "web", web.Name)
+	assert.Equal(t, "ts", web.Config.EntityCfg.Language)
+	assert.Equal(t, "wueste", web.Config.EntityCfg.FromWueste)
+	assert.Equal(t, "  ", web.Config.EntityCfg.Indent)
+	assert.Equal(t, filepath.Join(dir, "web/generated"), web.Config.OutputDir)
+	assert.Equal(t, filepath.Join(dir, "templates"), web.Config.EntityCfg.TemplateDir)
+	assert.Equal(t, "mirror", web.Config.EntityCfg.Layout)
+	assert.True(t, web.Config.EntityCfg.Index)
+	assert.Equal(t, "kebab", web.Config.EntityCfg.FileCase)
+	assert.Equal(t, ".js", web.Config.EntityCfg.ImportExtension)
+	assert.True(t, web.Config.EntityCfg.TypeImports)
+	assert.Equal(t, []string{filepath.Join(dir, "schemas")}, web.Config.IncludeDirs)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "schemas/a.schema.json"),
+		filepath.Join(dir, "schemas/b.schema.json"),
+	}, web.Config.InputFiles)
+
+	avro := project.Targets[1]
+	assert.Equal(t, "avro", avro.Name)
+	assert.Equal(t, "com.example", avro.Config.EntityCfg.PackageName)
+	assert.Equal(t, []string{filepath.Join(dir, "schemas/b.schema.json")}, avro.Config.InputFiles)
+	assert.Equal(t, []string{filepath.Join(dir, "plugins/avro.go")}, avro.Config.Plugins)
+	assert.Empty(t, web.Config.Plugins)
+}
+
+func TestProjectConfigJsonWithoutTargets(t *testing.T) {
+	dir := writeProjectFiles(t, map[string]string{
+		"a.schema.json": "{}",
+		"wueste.json": `{
+	"outputDir": "out",
+	"inputFiles": ["a.schema.json"]
+}`,
+	})
+	assert.Equal(t, filepath.Join(dir, "wueste.json"), FindProjectConfig(dir).Value())
+	project := LoadProjectConfig(filepath.Join(dir, "wueste.json"), GeneratorConfig{EntityCfg: Config{Language: "ts"}}).Ok()
+	assert.Equal(t, 1, len(project.Targets))
+	assert.Equal(t, "ts", project.Targets[0].Name)
+	assert.Equal(t, filepath.Join(dir, "out"), project.Targets[0].Config.OutputDir)
+	assert.True(t, FindProjectConfig(t.TempDir()).IsNone())
+}
+
+func TestProjectConfigErrors(t *testing.T) {
+	dir := writeProjectFiles(t, map[string]string{
+		"wueste.yaml": `outptDir: out
+targets:
+  - name: web
+    inputFiles: [missing/*.json]
+    writeTestSchema: yes please
+  - name: web
+    fileCase: camel
+`,
+	})
+	fname := filepath.Join(dir, "wueste.yaml")
+	err := LoadProjectConfig(fname, GeneratorConfig{}).Err()
+	errs, ok := err.(ConfigErrors)
+	assert.True(t, ok)
+	assert.Equal(t, 5, len(errs))
+	assert.Equal(t, fname+":1:1: unknown key outptDir", errs[0].Error())
+	assert.Equal(t, fname+":4:18: inputFiles missing/*.json: no file matches "+filepath.Join(dir, "missing/*.json"), errs[1].Error())
+	assert.Equal(t, fname+":5:22: writeTestSchema must be a boolean", errs[2].Error())
+	assert.Equal(t, fname+":7:15: unknown fileCase camel, expected one of lower, kebab, snake, as-is", errs[3].Error())
+	assert.Equal(t, fname+":6:5: duplicate target web", errs[4].Error())
+
+	assert.NoError(t, os.WriteFile(fname, []byte("targets: [\n"), 0644))
+	assert.Error(t, LoadProjectConfig(fname, GeneratorConfig{}).Err())
+}
@@ -10,6 +10,11 @@ type PropertyArray interface {
 	Id() string
 	Type() Type
 	Description() rusty.Optional[string]
+	Examples() []interface{}
+	Deprecated() rusty.Optional[bool]
+	Comment() rusty.Optional[string]
+	ReadOnly() rusty.Optional[bool]
+	WriteOnly() rusty.Optional[bool]
 	XProperties() map[string]interface{}
 	// Format() rusty.Optional[string]
 	// Optional() bool
@@ -34,6 +39,11 @@ type PropertyArrayBuilder struct {
 	Type        Type
 	Ref         rusty.Optional[string]
 	Description rusty.Optional[string]
+	Examples    []interface{}
+	Deprecated  rusty.Optional[bool]
+	Comment     rusty.Optional[string]
+	ReadOnly    rusty.Optional[bool]
+	WriteOnly   rusty.Optional[bool]
 	XProperties map[string]interface{}
 	// Format      rusty.Optional[string]
 	// Optional    bool
@@ -61,12 +71,27 @@ func (b *PropertyArrayBuilder) FromJson(js JSONDict) *PropertyArrayBuilder {
 	b.Type = ARRAY
 	ensureAttributeId(js, func(id string) { b.Id = id })
 	b.Description = getFromAttributeOptionalString(js, "description")
+	b.Examples = getFromAttributeArray(js, "examples")
+	b.Deprecated = getFromAttributeOptionalBoolean(js, "deprecated")
+	b.Comment = getFromAttributeOptionalString(js, "$comment")
+	b.ReadOnly = getFromAttributeOptionalBoolean(js, "readOnly")
+	b.WriteOnly = getFromAttributeOptionalBoolean(js, "writeOnly")
 	b.MaxItems = getFromAttributeOptionalInt(js, "maxItems")
 	b.MinItems = getFromAttributeOptionalInt(js, "minItems")
 
+	_items, _ := js.Lookup("items")
+	items, found := AsJSONDict(_items)
+	if !found {
+		b.Items = rusty.Err[Property](Diagnostics{NewDiagnostic(DiagInvalidItems, "Array needs items").at("/items")})
+		return b
+	}
 	builder := NewPropertiesBuilder(b._propertiesBuilder.ctx)
+	builder.parent = b._propertiesBuilder
 	builder.parentFileName = b._propertiesBuilder.FileName()
-	b.Items = builder.FromJson(js.Get("items").(JSONDict)).Build()
+	b.Items = builder.FromJson(items).Build()
+	if b.Items.IsErr() {
+		b.Items = rusty.Err[Property](AsDiagnostics(b.Items.Err()).inPointer("/items").atSource(keyPosition(js, "items")))
+	}
 	return b
 }
 
@@ -75,6 +100,11 @@ func PropertyArrayToJson(b PropertyArray) JSONDict {
 	JSONsetId(jsp, b)
 	JSONsetString(jsp, "type", b.Type())
 	JSONsetOptionalString(jsp, "description", b.Description())
+	JSONsetArray(jsp, "examples", b.Examples())
+	JSONsetOptionalBoolean(jsp, "deprecated", b.Deprecated())
+	JSONsetOptionalString(jsp, "$comment", b.Comment())
+	JSONsetOptionalBoolean(jsp, "readOnly", b.ReadOnly())
+	JSONsetOptionalBoolean(jsp, "writeOnly", b.WriteOnly())
 	JSONsetOptionalInt(jsp, "maxItems", b.MaxItems())
 	JSONsetOptionalInt(jsp, "minItems", b.MinItems())
 	jsp.Set("items", PropertyToJson(b.Items()))
@@ -84,10 +114,13 @@ func PropertyArrayToJson(b PropertyArray) JSONDict {
 func (b *PropertyArrayBuilder) Build() rusty.Result[Property] {
 	if b.Items.IsOk() {
 		pa := NewPropertyArray(*b)
-		b.Items.Ok().Meta().SetParent(pa.Ok())
+		// the target of a $ref cycle is an ancestor, it keeps its parent
+		if _, isRecursive := b.Items.Ok().(PropertyRecursive); !isRecursive {
+			b.Items.Ok().Meta().SetParent(pa.Ok())
+		}
 		return pa
 	} else {
-		return rusty.Err[Property](fmt.Errorf("Array needs items:%v", b.Items.Err()))
+		return rusty.Err[Property](b.Items.Err())
 	}
 }
 
@@ -113,6 +146,31 @@ func (p *propertyArray) Description() rusty.Optional[string] {
 	return p.param.Description
 }
 
+// Examples implements Property.
+func (p *propertyArray) Examples() []interface{} {
+	return p.param.Examples
+}
+
+// Deprecated implements Property.
+func (p *propertyArray) Deprecated() rusty.Optional[bool] {
+	return p.param.Deprecated
+}
+
+// Comment implements Property.
+func (p *propertyArray) Comment() rusty.Optional[string] {
+	return p.param.Comment
+}
+
+// ReadOnly implements Property.
+func (p *propertyArray) ReadOnly() rusty.Optional[bool] {
+	return p.param.ReadOnly
+}
+
+// WriteOnly implements Property.
+func (p *propertyArray) WriteOnly() rusty.Optional[bool] {
+	return p.param.WriteOnly
+}
+
 // Format implements PropertyArray.
 // func (p *propertyArray) Format() rusty.Optional[string] {
 // 	return p.param.Format
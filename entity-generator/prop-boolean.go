@@ -8,6 +8,11 @@ type PropertyBoolean interface {
 	Id() string
 	Type() Type
 	Description() rusty.Optional[string]
+	Examples() []interface{}
+	Deprecated() rusty.Optional[bool]
+	Comment() rusty.Optional[string]
+	ReadOnly() rusty.Optional[bool]
+	WriteOnly() rusty.Optional[bool]
 	Default() rusty.Optional[bool] // match Type
 	XProperties() map[string]interface{}
 	Ref() rusty.Optional[string]
@@ -19,6 +24,11 @@ type PropertyBooleanBuilder struct {
 	Type        Type
 	XProperties map[string]interface{}
 	Description rusty.Optional[string]
+	Examples    []interface{}
+	Deprecated  rusty.Optional[bool]
+	Comment     rusty.Optional[string]
+	ReadOnly    rusty.Optional[bool]
+	WriteOnly   rusty.Optional[bool]
 	Default     rusty.Optional[bool]
 	Ref         rusty.Optional[string]
 }
@@ -31,6 +41,11 @@ func (b *PropertyBooleanBuilder) FromJson(js JSONDict) *PropertyBooleanBuilder {
 	b.Type = "boolean"
 	ensureAttributeId(js, func(id string) { b.Id = id })
 	b.Description = getFromAttributeOptionalString(js, "description")
+	b.Examples = getFromAttributeArray(js, "examples")
+	b.Deprecated = getFromAttributeOptionalBoolean(js, "deprecated")
+	b.Comment = getFromAttributeOptionalString(js, "$comment")
+	b.ReadOnly = getFromAttributeOptionalBoolean(js, "readOnly")
+	b.WriteOnly = getFromAttributeOptionalBoolean(js, "writeOnly")
 	b.Default = getFromAttributeOptionalBoolean(js, "default")
 	b.XProperties = getFromAttributeXProperties(js)
 	return b
@@ -41,6 +56,11 @@ func PropertyBooleanToJson(b PropertyBoolean) JSONDict {
 	JSONsetId(jsp, b)
 	JSONsetString(jsp, "type", b.Type())
 	JSONsetOptionalString(jsp, "description", b.Description())
+	JSONsetArray(jsp, "examples", b.Examples())
+	JSONsetOptionalBoolean(jsp, "deprecated", b.Deprecated())
+	JSONsetOptionalString(jsp, "$comment", b.Comment())
+	JSONsetOptionalBoolean(jsp, "readOnly", b.ReadOnly())
+	JSONsetOptionalBoolean(jsp, "writeOnly", b.WriteOnly())
 	JSONsetOptionalBoolean(jsp, "default", b.Default())
 	JSONsetXProperties(jsp, b.XProperties())
 	return jsp
@@ -81,6 +101,31 @@ func (p *propertyBoolean) Description() rusty.Optional[string] {
 	return p.param.Description
 }
 
+// Examples implements Property.
+func (p *propertyBoolean) Examples() []interface{} {
+	return p.param.Examples
+}
+
+// Deprecated implements Property.
+func (p *propertyBoolean) Deprecated() rusty.Optional[bool] {
+	return p.param.Deprecated
+}
+
+// Comment implements Property.
+func (p *propertyBoolean) Comment() rusty.Optional[string] {
+	return p.param.Comment
+}
+
+// ReadOnly implements Property.
+func (p *propertyBoolean) ReadOnly() rusty.Optional[bool] {
+	return p.param.ReadOnly
+}
+
+// WriteOnly implements Property.
+func (p *propertyBoolean) WriteOnly() rusty.Optional[bool] {
+	return p.param.WriteOnly
+}
+
 // func (p *propertyBoolean) Runtime() *PropertyRuntime {
 // 	return &p.param.Runtime
 // }
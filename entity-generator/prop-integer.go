@@ -8,14 +8,21 @@ type PropertyInteger interface {
 	Id() string
 	Type() Type
 	Description() rusty.Optional[string]
+	Examples() []interface{}
+	Deprecated() rusty.Optional[bool]
+	Comment() rusty.Optional[string]
+	ReadOnly() rusty.Optional[bool]
+	WriteOnly() rusty.Optional[bool]
 	Format() rusty.Optional[string]
 	XProperties() map[string]interface{}
 	// Optional() bool
 	// SetOptional()
 	Default() rusty.Optional[int] // match Type
-	// Enum() []T
+	Enum() []int
 	Maximum() rusty.Optional[int]
 	Minimum() rusty.Optional[int]
+	ExclusiveMaximum() rusty.Optional[int]
+	ExclusiveMinimum() rusty.Optional[int]
 
 	Ref() rusty.Optional[string]
 	Meta() PropertyMeta
@@ -23,8 +30,6 @@ type PropertyInteger interface {
 
 	// Clone() Property
 
-	// ExclusiveMinimum() rusty.Optional[int]
-	// ExclusiveMaximum() rusty.Optional[int]
 	// MultipleOf() rusty.Optional[int]
 }
 
@@ -34,18 +39,25 @@ type PropertyIntegerBuilder struct {
 	Type        Type
 	Ref         rusty.Optional[string]
 	Description rusty.Optional[string]
+	Examples    []interface{}
+	Deprecated  rusty.Optional[bool]
+	Comment     rusty.Optional[string]
+	ReadOnly    rusty.Optional[bool]
+	WriteOnly   rusty.Optional[bool]
 	Format      rusty.Optional[string]
 	Default     rusty.Optional[int]
 	XProperties map[string]interface{}
-	// Enum        []T
+	Enum        []int
 	// Default rusty.Optional[T]
 	Maximum rusty.Optional[int]
 	Minimum rusty.Optional[int]
+	// the exclusive bounds are numbers, the booleans of draft-04 are
+	// migrated on load
+	ExclusiveMaximum rusty.Optional[int]
+	ExclusiveMinimum rusty.Optional[int]
 
 	// Runtime PropertyRuntime
 	// Ctx     PropertyCtx
-	// ExclusiveMinimum() rusty.Optional[int]
-	// ExclusiveMaximum() rusty.Optional[int]
 	// MultipleOf() rusty.Optional[int]
 }
 
@@ -57,11 +69,19 @@ func (b *PropertyIntegerBuilder) FromJson(js JSONDict) *PropertyIntegerBuilder {
 	b.Type = "integer"
 	ensureAttributeId(js, func(id string) { b.Id = id })
 	b.Description = getFromAttributeOptionalString(js, "description")
+	b.Examples = getFromAttributeArray(js, "examples")
+	b.Deprecated = getFromAttributeOptionalBoolean(js, "deprecated")
+	b.Comment = getFromAttributeOptionalString(js, "$comment")
+	b.ReadOnly = getFromAttributeOptionalBoolean(js, "readOnly")
+	b.WriteOnly = getFromAttributeOptionalBoolean(js, "writeOnly")
 	b.XProperties = getFromAttributeXProperties(js)
 	b.Format = getFromAttributeOptionalString(js, "format")
 	b.Default = getFromAttributeOptionalInt(js, "default")
+	b.Enum = getFromAttributeEnum(js, "enum", coerceInt)
 	b.Maximum = getFromAttributeOptionalInt(js, "maximum")
 	b.Minimum = getFromAttributeOptionalInt(js, "minimum")
+	b.ExclusiveMaximum = getFromAttributeOptionalInt(js, "exclusiveMaximum")
+	b.ExclusiveMinimum = getFromAttributeOptionalInt(js, "exclusiveMinimum")
 	return b
 }
 
@@ -71,10 +91,18 @@ func PropertyIntegerToJson(b PropertyInteger) JSONDict {
 	JSONsetString(jsp, "type", b.Type())
 	JSONsetOptionalString(jsp, "format", b.Format())
 	JSONsetOptionalString(jsp, "description", b.Description())
+	JSONsetArray(jsp, "examples", b.Examples())
+	JSONsetOptionalBoolean(jsp, "deprecated", b.Deprecated())
+	JSONsetOptionalString(jsp, "$comment", b.Comment())
+	JSONsetOptionalBoolean(jsp, "readOnly", b.ReadOnly())
+	JSONsetOptionalBoolean(jsp, "writeOnly", b.WriteOnly())
 	JSONsetXProperties(jsp, b.XProperties())
 	JSONsetOptionalInt(jsp, "default", b.Default())
+	JSONsetEnum(jsp, "enum", b.Enum())
 	JSONsetOptionalInt(jsp, "maximum", b.Maximum())
 	JSONsetOptionalInt(jsp, "minimum", b.Minimum())
+	JSONsetOptionalInt(jsp, "exclusiveMaximum", b.ExclusiveMaximum())
+	JSONsetOptionalInt(jsp, "exclusiveMinimum", b.ExclusiveMinimum())
 	return jsp
 }
 
@@ -123,6 +151,31 @@ func (p *propertyInteger) Description() rusty.Optional[string] {
 	return p.param.Description
 }
 
+// Examples implements Property.
+func (p *propertyInteger) Examples() []interface{} {
+	return p.param.Examples
+}
+
+// Deprecated implements Property.
+func (p *propertyInteger) Deprecated() rusty.Optional[bool] {
+	return p.param.Deprecated
+}
+
+// Comment implements Property.
+func (p *propertyInteger) Comment() rusty.Optional[string] {
+	return p.param.Comment
+}
+
+// ReadOnly implements Property.
+func (p *propertyInteger) ReadOnly() rusty.Optional[bool] {
+	return p.param.ReadOnly
+}
+
+// WriteOnly implements Property.
+func (p *propertyInteger) WriteOnly() rusty.Optional[bool] {
+	return p.param.WriteOnly
+}
+
 // Format implements PropertyBoolean.
 func (p *propertyInteger) Format() rusty.Optional[string] {
 	return p.param.Format
@@ -137,6 +190,11 @@ func (p *propertyInteger) Type() Type {
 	return INTEGER
 }
 
+// Enum implements PropertyInteger.
+func (p *propertyInteger) Enum() []int {
+	return p.param.Enum
+}
+
 func (p *propertyInteger) Default() rusty.Optional[int] {
 	if p.param.Default.IsSome() {
 		// lit := wueste.IntegerLiteral(*p.param.Default.Value())
@@ -153,3 +211,11 @@ func (p *propertyInteger) Maximum() rusty.Optional[int] {
 func (p *propertyInteger) Minimum() rusty.Optional[int] {
 	return p.param.Minimum
 }
+
+func (p *propertyInteger) ExclusiveMaximum() rusty.Optional[int] {
+	return p.param.ExclusiveMaximum
+}
+
+func (p *propertyInteger) ExclusiveMinimum() rusty.Optional[int] {
+	return p.param.ExclusiveMinimum
+}
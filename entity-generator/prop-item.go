@@ -11,6 +11,11 @@ type PropertyItem interface {
 	Id() string
 	Type() Type
 	Description() rusty.Optional[string]
+	Examples() []interface{}
+	Deprecated() rusty.Optional[bool]
+	Comment() rusty.Optional[string]
+	ReadOnly() rusty.Optional[bool]
+	WriteOnly() rusty.Optional[bool]
 	XProperties() map[string]interface{}
 	Ref() rusty.Optional[string]
 	Meta() PropertyMeta
@@ -30,19 +35,34 @@ func (pi *propertyItem) XProperties() map[string]interface{} {
 }
 
 func (pi *propertyItem) Description() rusty.Optional[string] {
-	panic("propertyItem:Description: implement me")
+	return pi.property.Description()
+}
+func (pi *propertyItem) Examples() []interface{} {
+	return pi.property.Examples()
+}
+func (pi *propertyItem) Deprecated() rusty.Optional[bool] {
+	return pi.property.Deprecated()
+}
+func (pi *propertyItem) Comment() rusty.Optional[string] {
+	return pi.property.Comment()
+}
+func (pi *propertyItem) ReadOnly() rusty.Optional[bool] {
+	return pi.property.ReadOnly()
+}
+func (pi *propertyItem) WriteOnly() rusty.Optional[bool] {
+	return pi.property.WriteOnly()
 }
 func (pi *propertyItem) Id() string {
-	panic("propertyItem:Id: implement me")
+	return pi.property.Id()
 }
 func (pi *propertyItem) Type() Type {
 	return pi.typ
 }
 func (pi *propertyItem) Ref() rusty.Optional[string] {
-	panic("propertyItem:Ref: implement me")
+	return pi.property.Ref()
 }
 func (pi *propertyItem) Meta() PropertyMeta {
-	panic("propertyItem:Meta: implement me")
+	return pi.property.Meta()
 }
 
 func (pi *propertyItem) Idx() int {
@@ -9,12 +9,19 @@ type PropertyNumber interface {
 	Type() Type
 	Ref() rusty.Optional[string]
 	Description() rusty.Optional[string]
+	Examples() []interface{}
+	Deprecated() rusty.Optional[bool]
+	Comment() rusty.Optional[string]
+	ReadOnly() rusty.Optional[bool]
+	WriteOnly() rusty.Optional[bool]
 	Format() rusty.Optional[string]
 	XProperties() map[string]interface{}
 	Default() rusty.Optional[float64] // match Type
-	// Enum() []float64
+	Enum() []float64
 	Maximum() rusty.Optional[float64]
 	Minimum() rusty.Optional[float64]
+	ExclusiveMaximum() rusty.Optional[float64]
+	ExclusiveMinimum() rusty.Optional[float64]
 
 	Meta() PropertyMeta
 
@@ -28,12 +35,21 @@ type PropertyNumberBuilder struct {
 	Ref         rusty.Optional[string]
 	Type        Type
 	Description rusty.Optional[string]
+	Examples    []interface{}
+	Deprecated  rusty.Optional[bool]
+	Comment     rusty.Optional[string]
+	ReadOnly    rusty.Optional[bool]
+	WriteOnly   rusty.Optional[bool]
 	Format      rusty.Optional[string]
 	Default     rusty.Optional[float64]
 	XProperties map[string]interface{}
-	// Enum        []float64
-	Maximum rusty.Optional[float64]
-	Minimum rusty.Optional[float64]
+	Enum        []float64
+	Maximum     rusty.Optional[float64]
+	Minimum     rusty.Optional[float64]
+	// the exclusive bounds are numbers, the booleans of draft-04 are
+	// migrated on load
+	ExclusiveMaximum rusty.Optional[float64]
+	ExclusiveMinimum rusty.Optional[float64]
 
 	// Runtime PropertyRuntime
 	// Ctx     PropertyCtx
@@ -47,11 +63,19 @@ func (b *PropertyNumberBuilder) FromJson(js JSONDict) *PropertyNumberBuilder {
 	b.Type = "number"
 	ensureAttributeId(js, func(id string) { b.Id = id })
 	b.Description = getFromAttributeOptionalString(js, "description")
+	b.Examples = getFromAttributeArray(js, "examples")
+	b.Deprecated = getFromAttributeOptionalBoolean(js, "deprecated")
+	b.Comment = getFromAttributeOptionalString(js, "$comment")
+	b.ReadOnly = getFromAttributeOptionalBoolean(js, "readOnly")
+	b.WriteOnly = getFromAttributeOptionalBoolean(js, "writeOnly")
 	b.XProperties = getFromAttributeXProperties(js)
 	b.Format = getFromAttributeOptionalString(js, "format")
 	b.Default = getFromAttributeOptionalFloat64(js, "default")
+	b.Enum = getFromAttributeEnum(js, "enum", coerceFloat64)
 	b.Maximum = getFromAttributeOptionalFloat64(js, "maximum")
 	b.Minimum = getFromAttributeOptionalFloat64(js, "minimum")
+	b.ExclusiveMaximum = getFromAttributeOptionalFloat64(js, "exclusiveMaximum")
+	b.ExclusiveMinimum = getFromAttributeOptionalFloat64(js, "exclusiveMinimum")
 	return b
 }
 
@@ -61,10 +85,18 @@ func PropertyNumberToJson(b PropertyNumber) JSONDict {
 	JSONsetString(jsp, "type", b.Type())
 	JSONsetOptionalString(jsp, "format", b.Format())
 	JSONsetOptionalString(jsp, "description", b.Description())
+	JSONsetArray(jsp, "examples", b.Examples())
+	JSONsetOptionalBoolean(jsp, "deprecated", b.Deprecated())
+	JSONsetOptionalString(jsp, "$comment", b.Comment())
+	JSONsetOptionalBoolean(jsp, "readOnly", b.ReadOnly())
+	JSONsetOptionalBoolean(jsp, "writeOnly", b.WriteOnly())
 	JSONsetXProperties(jsp, b.XProperties())
 	JSONsetOptionalFloat64(jsp, "default", b.Default())
+	JSONsetEnum(jsp, "enum", b.Enum())
 	JSONsetOptionalFloat64(jsp, "maximum", b.Maximum())
 	JSONsetOptionalFloat64(jsp, "minimum", b.Minimum())
+	JSONsetOptionalFloat64(jsp, "exclusiveMaximum", b.ExclusiveMaximum())
+	JSONsetOptionalFloat64(jsp, "exclusiveMinimum", b.ExclusiveMinimum())
 	return jsp
 }
 
@@ -108,13 +140,41 @@ func (p *propertyNumber) Id() string {
 func (p *propertyNumber) Ref() rusty.Optional[string] {
 	return p.param.Ref
 }
+
+// Enum implements PropertyNumber.
 func (p *propertyNumber) Enum() []float64 {
-	panic("PropNumber Enum implement me")
+	return p.param.Enum
 }
+
 func (p *propertyNumber) Description() rusty.Optional[string] {
 	return p.param.Description
 }
 
+// Examples implements Property.
+func (p *propertyNumber) Examples() []interface{} {
+	return p.param.Examples
+}
+
+// Deprecated implements Property.
+func (p *propertyNumber) Deprecated() rusty.Optional[bool] {
+	return p.param.Deprecated
+}
+
+// Comment implements Property.
+func (p *propertyNumber) Comment() rusty.Optional[string] {
+	return p.param.Comment
+}
+
+// ReadOnly implements Property.
+func (p *propertyNumber) ReadOnly() rusty.Optional[bool] {
+	return p.param.ReadOnly
+}
+
+// WriteOnly implements Property.
+func (p *propertyNumber) WriteOnly() rusty.Optional[bool] {
+	return p.param.WriteOnly
+}
+
 // // Format implements PropertyBoolean.
 func (p *propertyNumber) Format() rusty.Optional[string] {
 	return p.param.Format
@@ -140,3 +200,11 @@ func (p *propertyNumber) Maximum() rusty.Optional[float64] {
 func (p *propertyNumber) Minimum() rusty.Optional[float64] {
 	return p.param.Minimum
 }
+
+func (p *propertyNumber) ExclusiveMaximum() rusty.Optional[float64] {
+	return p.param.ExclusiveMaximum
+}
+
+func (p *propertyNumber) ExclusiveMinimum() rusty.Optional[float64] {
+	return p.param.ExclusiveMinimum
+}
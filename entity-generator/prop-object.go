@@ -42,6 +42,11 @@ type PropertyObject interface {
 	Title() string
 	Schema() string
 	Description() rusty.Optional[string]
+	Examples() []interface{}
+	Deprecated() rusty.Optional[bool]
+	Comment() rusty.Optional[string]
+	ReadOnly() rusty.Optional[bool]
+	WriteOnly() rusty.Optional[bool]
 	XProperties() map[string]interface{}
 
 	Properties() *properties
@@ -107,6 +112,31 @@ func (s *propertyObject) Description() rusty.Optional[string] {
 	return s.param.Description
 }
 
+// Examples implements Property.
+func (s *propertyObject) Examples() []interface{} {
+	return s.param.Examples
+}
+
+// Deprecated implements Property.
+func (s *propertyObject) Deprecated() rusty.Optional[bool] {
+	return s.param.Deprecated
+}
+
+// Comment implements Property.
+func (s *propertyObject) Comment() rusty.Optional[string] {
+	return s.param.Comment
+}
+
+// ReadOnly implements Property.
+func (s *propertyObject) ReadOnly() rusty.Optional[bool] {
+	return s.param.ReadOnly
+}
+
+// WriteOnly implements Property.
+func (s *propertyObject) WriteOnly() rusty.Optional[bool] {
+	return s.param.WriteOnly
+}
+
 // Id implements Schema.
 func (s *propertyObject) Id() string {
 	return s.param.Id
@@ -171,6 +201,11 @@ type PropertyObjectBuilder struct {
 	// items       []PropertyItem
 	Type        Type
 	Description rusty.Optional[string]
+	Examples    []interface{}
+	Deprecated  rusty.Optional[bool]
+	Comment     rusty.Optional[string]
+	ReadOnly    rusty.Optional[bool]
+	WriteOnly   rusty.Optional[bool]
 
 	Id          string
 	Title       string
@@ -285,27 +320,33 @@ func (b *PropertyObjectBuilder) FromJson(js JSONDict) *PropertyObjectBuilder {
 	b.Title = getFromAttributeString(js, "title")
 	b.Schema = getFromAttributeString(js, "$schema")
 	b.Description = getFromAttributeOptionalString(js, "description")
+	b.Examples = getFromAttributeArray(js, "examples")
+	b.Deprecated = getFromAttributeOptionalBoolean(js, "deprecated")
+	b.Comment = getFromAttributeOptionalString(js, "$comment")
+	b.ReadOnly = getFromAttributeOptionalBoolean(js, "readOnly")
+	b.WriteOnly = getFromAttributeOptionalBoolean(js, "writeOnly")
 	b.XProperties = getFromAttributeXProperties(js)
 	b.Properties = newProperties()
 	_properties, found := js.Lookup("properties")
 	if found {
 		properties, found := _properties.(JSONDict)
 		if !found {
-			b.Errors = append(b.Errors, fmt.Errorf("properties[%s] is not JSONProperty", b.Id))
+			b.Errors = append(b.Errors, NewDiagnostic(DiagInvalidProperties, "properties[%s] is not JSONProperty", b.Id).at("/properties").atSource(keyPosition(js, "properties")))
 			return b
 		}
 		for _, k := range properties.Keys() {
 			_v := properties.Get(k)
 			v, found := _v.(JSONDict)
 			if !found {
-				b.Errors = append(b.Errors, fmt.Errorf("properties[%s->%s] is not JSONProperty", b.Id, k))
+				b.Errors = append(b.Errors, NewDiagnostic(DiagInvalidProperties, "properties[%s->%s] is not JSONProperty", b.Id, k).at("/properties/"+EscapeJSONPointer(k)).atSource(keyPosition(properties, k)))
 				continue
 			}
 			builder := NewPropertiesBuilder(b._propertiesBuilder.ctx)
+			builder.parent = b._propertiesBuilder
 			builder.parentFileName = b._propertiesBuilder.FileName()
 			r := builder.FromJson(v).Build()
 			if r.IsErr() {
-				b.Errors = append(b.Errors, r.Err())
+				b.Errors = append(b.Errors, AsDiagnostics(r.Err()).inPointer("/properties/"+EscapeJSONPointer(k)).atSource(keyPosition(properties, k)))
 			} else {
 				b.Properties.Set(k, r.Ok())
 			}
@@ -319,7 +360,7 @@ func (b *PropertyObjectBuilder) FromJson(js JSONDict) *PropertyObjectBuilder {
 			if found {
 				b.Required = stringArray
 			} else {
-				b.Errors = append(b.Errors, fmt.Errorf("required[%s] is not []string", b.Id))
+				b.Errors = append(b.Errors, NewDiagnostic(DiagInvalidRequired, "required[%s] is not []string", b.Id).at("/required").atSource(keyPosition(js, "required")))
 				return b
 			}
 		} else {
@@ -327,7 +368,7 @@ func (b *PropertyObjectBuilder) FromJson(js JSONDict) *PropertyObjectBuilder {
 			for _, v := range stringArray {
 				vs := coerceString(v)
 				if vs.IsNone() {
-					b.Errors = append(b.Errors, fmt.Errorf("required[%v] is not string", v))
+					b.Errors = append(b.Errors, NewDiagnostic(DiagInvalidRequired, "required[%v] is not string", v).at("/required").atSource(keyPosition(js, "required")))
 					return b
 				}
 				out = append(out, coerceString(v).Value())
@@ -353,6 +394,11 @@ func PropertyObjectToJson(b PropertyObject) JSONDict {
 		JSONsetString(jsp, "$schema", b.Schema())
 	}
 	JSONsetOptionalString(jsp, "description", b.Description())
+	JSONsetArray(jsp, "examples", b.Examples())
+	JSONsetOptionalBoolean(jsp, "deprecated", b.Deprecated())
+	JSONsetOptionalString(jsp, "$comment", b.Comment())
+	JSONsetOptionalBoolean(jsp, "readOnly", b.ReadOnly())
+	JSONsetOptionalBoolean(jsp, "writeOnly", b.WriteOnly())
 	JSONsetXProperties(jsp, b.XProperties())
 	props := NewJSONDict()
 	items := b.Items()
@@ -371,17 +417,20 @@ func PropertyObjectToJson(b PropertyObject) JSONDict {
 
 func (p *PropertyObjectBuilder) Build() rusty.Result[Property] {
 	if len(p.Errors) > 0 {
-		str := ""
-		for _, v := range p.Errors {
-			str += v.Error() + "\n"
-		}
-		return rusty.Err[Property](fmt.Errorf(str))
+		return rusty.Err[Property](joinDiagnostics(p.Errors))
 	}
 	po := NewPropertyObject(*p)
+	if po.IsErr() {
+		return po
+	}
 	if p._propertiesBuilder.filename.IsSome() {
 		po.Ok().Meta().SetFileName(p._propertiesBuilder.filename.Value())
 	}
 	for _, v := range po.Ok().(PropertyObject).Items() {
+		if _, isRecursive := v.Property().(PropertyRecursive); isRecursive {
+			// the target of a $ref cycle is an ancestor, it keeps its parent
+			continue
+		}
 		v.Property().Meta().SetMeta(po.Ok())
 	}
 	return po
@@ -389,7 +438,7 @@ func (p *PropertyObjectBuilder) Build() rusty.Result[Property] {
 
 func NewPropertyObject(p PropertyObjectBuilder) rusty.Result[Property] {
 	if !(p.Properties == nil || p.Properties.Len() == 0) && p.Id == "" {
-		return rusty.Err[Property](fmt.Errorf("PropertyObject Id is required"))
+		return rusty.Err[Property](Diagnostics{NewDiagnostic(DiagMissingId, "PropertyObject Id is required")})
 	}
 	p.Type = OBJECT
 	r := &propertyObject{
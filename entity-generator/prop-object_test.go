@@ -2,6 +2,7 @@ package entity_generator
 
 import (
 	"testing"
+	"testing/fstest"
 
 	"github.com/mabels/wueste/entity-generator/rusty"
 	"github.com/stretchr/testify/assert"
@@ -179,19 +180,15 @@ func TestDeref(t *testing.T) {
 		}
 	}
 	base := &schemaRegistryItem{
-		written:  false,
 		jsonFile: BaseSchema(),
 	}
 	testSub := &schemaRegistryItem{
-		written:  false,
 		jsonFile: TestJsonSubSchema(),
 	}
 	sub2 := &schemaRegistryItem{
-		written:  false,
 		jsonFile: Sub2Schema(),
 	}
 	sub3 := &schemaRegistryItem{
-		written:  false,
 		jsonFile: Sub3Schema(),
 	}
 	refItems := []SchemaRegistryItem{base, testSub, sub2, sub3}
@@ -261,3 +258,42 @@ func TestSetFilename(t *testing.T) {
 	r := builder.FromJson(jsDict).Build()
 	assert.False(t, r.IsErr())
 }
+
+func TestAnnotations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"user.schema.json": {Data: []byte(`{
+	"$id": "https://User", "title": "User", "type": "object",
+	"$comment": "owned by the auth team",
+	"properties": {
+		"name": { "type": "string", "examples": ["bob", "alice"], "deprecated": true },
+		"age": { "type": "integer", "$comment": "in years", "readOnly": true },
+		"secret": { "type": "string", "writeOnly": true }
+	}
+}`)},
+	}
+	ctx := PropertyCtx{Registry: NewSchemaRegistry(NewFSSchemaLoader(fsys))}
+	user := LoadSchemaFile(ctx, "user.schema.json").Ok().(PropertyObject)
+	assert.Equal(t, "owned by the auth team", user.Comment().Value())
+	assert.True(t, user.Deprecated().IsNone())
+	assert.Empty(t, user.Examples())
+
+	name := user.PropertyByName("name").Ok()
+	assert.Equal(t, []interface{}{"bob", "alice"}, name.Examples())
+	assert.True(t, name.Deprecated().Value())
+	age := user.PropertyByName("age").Ok().Property()
+	assert.Equal(t, "in years", age.Comment().Value())
+	assert.True(t, age.ReadOnly().Value())
+	assert.True(t, age.WriteOnly().IsNone())
+	secret := user.PropertyByName("secret").Ok()
+	assert.True(t, secret.WriteOnly().Value())
+	assert.True(t, secret.ReadOnly().IsNone())
+
+	js := PropertyToJson(name.Property())
+	assert.Equal(t, []interface{}{"bob", "alice"}, js.Get("examples"))
+	assert.Equal(t, true, js.Get("deprecated"))
+	assert.Equal(t, "in years", PropertyToJson(age).Get("$comment"))
+	assert.Equal(t, true, PropertyToJson(age).Get("readOnly"))
+	assert.Equal(t, true, PropertyToJson(secret.Property()).Get("writeOnly"))
+	_, found := PropertyToJson(age).Lookup("examples")
+	assert.False(t, found)
+}
@@ -0,0 +1,167 @@
+package entity_generator
+
+import (
+	"github.com/mabels/wueste/entity-generator/rusty"
+)
+
+// PropertyRecursive is the object of a $ref back into a schema which is
+// still being built, like the children of a tree node. It forwards to the
+// referenced object, the walkers stop at it to not recurse forever.
+type PropertyRecursive interface {
+	PropertyObject
+	Target() PropertyObject
+}
+
+type propertyRecursive struct {
+	ref    string
+	target *PropertiesBuilder
+}
+
+func newPropertyRecursive(ref string, target *PropertiesBuilder) Property {
+	return &propertyRecursive{ref: ref, target: target}
+}
+
+// Target returns the referenced object, it is set once the build is done.
+func (p *propertyRecursive) Target() PropertyObject {
+	return p.target.property.Value().(PropertyObject)
+}
+
+func (p *propertyRecursive) Type() Type {
+	return OBJECT
+}
+
+func (p *propertyRecursive) Ref() rusty.Optional[string] {
+	return rusty.Some(p.ref)
+}
+
+func (p *propertyRecursive) Id() string {
+	return p.Target().Id()
+}
+
+func (p *propertyRecursive) Title() string {
+	return p.Target().Title()
+}
+
+func (p *propertyRecursive) Schema() string {
+	return p.Target().Schema()
+}
+
+func (p *propertyRecursive) Description() rusty.Optional[string] {
+	return p.Target().Description()
+}
+
+func (p *propertyRecursive) Examples() []interface{} {
+	return p.Target().Examples()
+}
+
+func (p *propertyRecursive) Deprecated() rusty.Optional[bool] {
+	return p.Target().Deprecated()
+}
+
+func (p *propertyRecursive) Comment() rusty.Optional[string] {
+	return p.Target().Comment()
+}
+
+func (p *propertyRecursive) ReadOnly() rusty.Optional[bool] {
+	return p.Target().ReadOnly()
+}
+
+func (p *propertyRecursive) WriteOnly() rusty.Optional[bool] {
+	return p.Target().WriteOnly()
+}
+
+func (p *propertyRecursive) XProperties() map[string]interface{} {
+	return p.Target().XProperties()
+}
+
+func (p *propertyRecursive) Properties() *properties {
+	return p.Target().Properties()
+}
+
+func (p *propertyRecursive) Items() []PropertyItem {
+	return p.Target().Items()
+}
+
+func (p *propertyRecursive) PropertyByName(name string) rusty.Result[PropertyItem] {
+	return p.Target().PropertyByName(name)
+}
+
+func (p *propertyRecursive) Required() []string {
+	return p.Target().Required()
+}
+
+// Meta is the one of the target, the recursive object has the same name
+// and file.
+func (p *propertyRecursive) Meta() PropertyMeta {
+	return p.Target().Meta()
+}
+
+// IsEntityRoot returns true for the objects loaded by a $ref, they are
+// generated as entities of their own which are named below the referring
+// object.
+func IsEntityRoot(p Property) bool {
+	return p.Type() == OBJECT && p.Ref().IsSome()
+}
+
+// recursiveRef returns the builder of the ancestors which loads fname, it
+// is the target of a $ref cycle.
+func (b *PropertiesBuilder) recursiveRef(fname string) rusty.Optional[*PropertiesBuilder] {
+	for a := b.parent; a != nil; a = a.parent {
+		if a.filename.IsSome() && a.filename.Value() == fname {
+			return rusty.Some(a)
+		}
+	}
+	return rusty.None[*PropertiesBuilder]()
+}
+
+// PropertyRecursiveToJson writes the $ref, the referenced object is
+// written where it is defined.
+func PropertyRecursiveToJson(p PropertyRecursive) JSONDict {
+	jsp := NewJSONDict()
+	JSONsetString(jsp, "type", p.Type())
+	JSONsetString(jsp, "$ref", p.Ref().Value())
+	return jsp
+}
+
+// RefersTo returns true if prop refers to the entity of the file fname,
+// directly or through other entities.
+func RefersTo(prop Property, fname string) bool {
+	seen := map[string]bool{}
+	var walk func(p Property, root bool) bool
+	walk = func(p Property, root bool) bool {
+		if !root && IsEntityRoot(p) && p.Meta().FileName().IsSome() {
+			pname := p.Meta().FileName().Value()
+			if pname == fname {
+				return true
+			}
+			if seen[pname] {
+				return false
+			}
+			seen[pname] = true
+		}
+		switch v := p.(type) {
+		case PropertyObject:
+			for _, pi := range v.Items() {
+				if walk(pi.Property(), false) {
+					return true
+				}
+			}
+		case PropertyArray:
+			return walk(v.Items(), false)
+		}
+		return false
+	}
+	if prop.Meta().FileName().IsSome() {
+		seen[prop.Meta().FileName().Value()] = true
+	}
+	return walk(prop, true)
+}
+
+// IsRecursiveRef returns true if the entity ref which is used by from
+// refers back to from, the generators have to break these cycles.
+func IsRecursiveRef(ref Property, from Property) bool {
+	if !IsEntityRoot(ref) || from.Meta().FileName().IsNone() {
+		return false
+	}
+	return RefersTo(ref, from.Meta().FileName().Value())
+}
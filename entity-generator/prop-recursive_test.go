@@ -0,0 +1,73 @@
+package entity_generator
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func recursiveSchemaFS() fstest.MapFS {
+	return fstest.MapFS{
+		"tree.schema.json": {Data: []byte(`{
+	"$id": "https://Tree", "title": "Tree", "type": "object",
+	"properties": {
+		"name": { "type": "string" },
+		"parent": { "$ref": "file://tree.schema.json" },
+		"children": { "type": "array", "items": { "$ref": "file://tree.schema.json" } },
+		"forest": { "$ref": "file://forest.schema.json" }
+	},
+	"required": ["name"]
+}`)},
+		"forest.schema.json": {Data: []byte(`{
+	"$id": "https://Forest", "title": "Forest", "type": "object",
+	"properties": {
+		"trees": { "type": "array", "items": { "$ref": "file://tree.schema.json" } }
+	}
+}`)},
+		"leaf.schema.json": {Data: []byte(`{
+	"$id": "https://Leaf", "title": "Leaf", "type": "object",
+	"properties": { "name": { "type": "string" } }
+}`)},
+		"list.schema.json": {Data: []byte(`{
+	"type": "array", "items": { "$ref": "file://list.schema.json" }
+}`)},
+	}
+}
+
+func TestRecursiveSchema(t *testing.T) {
+	ctx := PropertyCtx{Registry: NewSchemaRegistry(NewFSSchemaLoader(recursiveSchemaFS()))}
+	rTree := LoadSchemaFile(ctx, "tree.schema.json")
+	assert.True(t, rTree.IsOk())
+	tree := rTree.Ok().(PropertyObject)
+
+	parent, ok := tree.PropertyByName("parent").Ok().Property().(PropertyRecursive)
+	assert.True(t, ok)
+	assert.Equal(t, tree, parent.Target())
+	assert.Equal(t, "Tree", parent.Title())
+	assert.Equal(t, tree.Meta(), parent.Meta())
+	children := tree.PropertyByName("children").Ok().Property().(PropertyArray)
+	assert.Equal(t, tree, children.Items().(PropertyRecursive).Target())
+
+	// the forest is built inside of the tree and refers back to it
+	forest := tree.PropertyByName("forest").Ok().Property().(PropertyObject)
+	assert.Equal(t, tree, forest.Meta().Parent().Value())
+	trees := forest.PropertyByName("trees").Ok().Property().(PropertyArray)
+	assert.Equal(t, tree, trees.Items().(PropertyRecursive).Target())
+
+	assert.True(t, IsRecursiveRef(parent, tree))
+	assert.True(t, IsRecursiveRef(forest, tree))
+	assert.Equal(t, []string{"/tree.schema.json", "/forest.schema.json"}, PropertyFileNames(tree))
+	assert.Equal(t, "file://tree.schema.json", PropertyToJson(tree).Get("properties").(JSONDict).Get("parent").(JSONDict).Get("$ref"))
+	assert.Empty(t, DiffSchemas(tree, tree))
+
+	leaf := LoadSchemaFile(ctx, "leaf.schema.json").Ok()
+	assert.False(t, IsRecursiveRef(leaf, tree))
+	assert.False(t, IsRecursiveRef(tree.PropertyByName("name").Ok().Property(), tree))
+
+	ds := AsDiagnostics(LoadSchemaFile(ctx, "list.schema.json").Err())
+	assert.Equal(t, 1, len(ds), ds.Error())
+	assert.Equal(t, DiagRefCycle, ds[0].Code)
+	assert.Equal(t, "/list.schema.json", ds[0].File)
+	assert.Equal(t, "/items/$ref", ds[0].Pointer)
+}
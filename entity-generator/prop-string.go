@@ -17,8 +17,14 @@ type PropertyString interface {
 	Id() string
 	Type() string
 	Description() rusty.Optional[string]
+	Examples() []interface{}
+	Deprecated() rusty.Optional[bool]
+	Comment() rusty.Optional[string]
+	ReadOnly() rusty.Optional[bool]
+	WriteOnly() rusty.Optional[bool]
 	Default() rusty.Optional[string] // match Type
 	Format() rusty.Optional[StringFormat]
+	Enum() []string
 	Ref() rusty.Optional[string]
 	XProperties() map[string]interface{}
 	Meta() PropertyMeta
@@ -37,10 +43,15 @@ type PropertyStringBuilder struct {
 	Id          string
 	Type        Type
 	Description rusty.Optional[string]
+	Examples    []interface{}
+	Deprecated  rusty.Optional[bool]
+	Comment     rusty.Optional[string]
+	ReadOnly    rusty.Optional[bool]
+	WriteOnly   rusty.Optional[bool]
 	Default     rusty.Optional[string]
 	Ref         rusty.Optional[string]
 	XProperties map[string]interface{}
-	// Enum      []string
+	Enum        []string
 	// MinLength rusty.Optional[int]
 	// MaxLength rusty.Optional[int]
 
@@ -68,8 +79,14 @@ func (b *PropertyStringBuilder) FromJson(js JSONDict) *PropertyStringBuilder {
 	b.Type = STRING
 	ensureAttributeId(js, func(id string) { b.Id = id })
 	b.Description = getFromAttributeOptionalString(js, "description")
+	b.Examples = getFromAttributeArray(js, "examples")
+	b.Deprecated = getFromAttributeOptionalBoolean(js, "deprecated")
+	b.Comment = getFromAttributeOptionalString(js, "$comment")
+	b.ReadOnly = getFromAttributeOptionalBoolean(js, "readOnly")
+	b.WriteOnly = getFromAttributeOptionalBoolean(js, "writeOnly")
 	b.Format = getFromAttributeOptionalString(js, "format")
 	b.Default = getFromAttributeOptionalString(js, "default")
+	b.Enum = getFromAttributeEnum(js, "enum", coerceString)
 	b.XProperties = getFromAttributeXProperties(js)
 	return b
 }
@@ -79,8 +96,14 @@ func PropertyStringToJson(b PropertyString) JSONDict {
 	JSONsetId(jsp, b)
 	JSONsetString(jsp, "type", b.Type())
 	JSONsetOptionalString(jsp, "description", b.Description())
+	JSONsetArray(jsp, "examples", b.Examples())
+	JSONsetOptionalBoolean(jsp, "deprecated", b.Deprecated())
+	JSONsetOptionalString(jsp, "$comment", b.Comment())
+	JSONsetOptionalBoolean(jsp, "readOnly", b.ReadOnly())
+	JSONsetOptionalBoolean(jsp, "writeOnly", b.WriteOnly())
 	JSONsetOptionalString(jsp, "format", b.Format())
 	JSONsetOptionalString(jsp, "default", b.Default())
+	JSONsetEnum(jsp, "enum", b.Enum())
 	JSONsetXProperties(jsp, b.XProperties())
 	return jsp
 }
@@ -112,6 +135,31 @@ func (p *propertyString) Description() rusty.Optional[string] {
 	return p.param.Description
 }
 
+// Examples implements Property.
+func (p *propertyString) Examples() []interface{} {
+	return p.param.Examples
+}
+
+// Deprecated implements Property.
+func (p *propertyString) Deprecated() rusty.Optional[bool] {
+	return p.param.Deprecated
+}
+
+// Comment implements Property.
+func (p *propertyString) Comment() rusty.Optional[string] {
+	return p.param.Comment
+}
+
+// ReadOnly implements Property.
+func (p *propertyString) ReadOnly() rusty.Optional[bool] {
+	return p.param.ReadOnly
+}
+
+// WriteOnly implements Property.
+func (p *propertyString) WriteOnly() rusty.Optional[bool] {
+	return p.param.WriteOnly
+}
+
 // Id implements PropertyString.
 // func (p *propertyString) Id() string {
 // 	return p.param.Id
@@ -128,9 +176,9 @@ func (p *propertyString) Description() rusty.Optional[string] {
 // }
 
 // Enum implements PropertyString.
-// func (p *propertyString) Enum() []string {
-// 	return p.param.enum
-// }
+func (p *propertyString) Enum() []string {
+	return p.param.Enum
+}
 
 func (p *propertyString) Type() Type {
 	return STRING
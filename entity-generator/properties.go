@@ -1,12 +1,11 @@
 package entity_generator
 
 import (
-	"fmt"
-
 	"github.com/mabels/wueste/entity-generator/rusty"
 )
 
 type PropertiesBuilder struct {
+	parent         *PropertiesBuilder
 	parentFileName rusty.Optional[string]
 	property       rusty.Optional[Property]
 	filename       rusty.Optional[string]
@@ -72,6 +71,22 @@ func JSONsetOptionalString(js JSONDict, key string, value rusty.Optional[string]
 	}
 }
 
+func JSONsetArray(js JSONDict, key string, value []interface{}) {
+	if len(value) > 0 {
+		js.Set(key, value)
+	}
+}
+
+func JSONsetEnum[T any](js JSONDict, key string, values []T) {
+	if len(values) > 0 {
+		arr := make([]interface{}, 0, len(values))
+		for _, v := range values {
+			arr = append(arr, v)
+		}
+		js.Set(key, arr)
+	}
+}
+
 func JSONsetXProperties(js JSONDict, value map[string]interface{}) {
 	for k, v := range value {
 		js.Set(k, v)
@@ -147,6 +162,9 @@ func (b *PropertiesBuilder) MergeJson(parentFname rusty.Optional[string], ref st
 	for _, k := range fjs.Keys() {
 		v, _ := fjs.Lookup(k)
 		js.Set(k, v)
+		if pos, found := memberPositionOf(fjs, k); found {
+			setMemberPosition(js, k, pos)
+		}
 	}
 	return rusty.Ok[JSonFile](JSonFile{
 		FileName:     rJson.Ok().FileName,
@@ -162,23 +180,37 @@ func (b *PropertiesBuilder) FromJson(js JSONDict) *PropertiesBuilder {
 		// }
 		refStr := coerceString(ref)
 		if refStr.IsNone() {
-			b.errors = append(b.errors, fmt.Errorf("ref not a string"))
+			b.errors = append(b.errors, NewDiagnostic(DiagInvalidRef, "$ref is not a string").at("/$ref").atSource(keyPosition(js, "$ref")))
 			return b
 		}
 		rJs := b.MergeJson(b.parentFileName, refStr.Value(), js)
 		if rJs.IsErr() {
-			b.errors = append(b.errors, rJs.Err())
+			b.errors = append(b.errors, AsDiagnostics(rJs.Err()).inPointer("/$ref").atSource(keyPosition(js, "$ref")))
 			return b
 		}
 		js = rJs.Ok().JSONProperty
+		target := b.recursiveRef(rJs.Ok().FileName)
+		if target.IsSome() {
+			// a $ref cycle refers back to the object which is being built
+			if typ, _ := js.Lookup("type"); coerceString(typ).IsNone() || coerceString(typ).Value() != OBJECT {
+				b.errors = append(b.errors, NewDiagnostic(DiagRefCycle, "recursive $ref %s is not an object", refStr.Value()).at("/$ref").atSource(keyPosition(js, "$ref")))
+				return b
+			}
+			b.property = rusty.Some(newPropertyRecursive(refStr.Value(), target.Value()))
+			return b
+		}
 		b.filename = rusty.Some(rJs.Ok().FileName)
 	}
 	_typ, found := js.Lookup("type")
 	if !found {
-		b.errors = append(b.errors, fmt.Errorf("no type"))
+		b.errors = append(b.errors, NewDiagnostic(DiagMissingType, "no type"))
+		return b
+	}
+	typ, ok := _typ.(string)
+	if !ok {
+		b.errors = append(b.errors, NewDiagnostic(DiagInvalidType, "type is not a string").at("/type").atSource(keyPosition(js, "type")))
 		return b
 	}
-	typ := coerceString(_typ).Value()
 	switch typ {
 	case OBJECT:
 		b.assignProperty(func(b *PropertiesBuilder) rusty.Result[Property] {
@@ -205,7 +237,12 @@ func (b *PropertiesBuilder) FromJson(js JSONDict) *PropertiesBuilder {
 			return NewPropertyArrayBuilder(b).FromJson(js).Build()
 		})
 	default:
-		panic("unknown type:" + typ)
+		b.errors = append(b.errors, NewDiagnostic(DiagInvalidType, "unknown type: %s", typ).at("/type").atSource(keyPosition(js, "type")))
+	}
+	if len(b.errors) == 0 && b.property.IsSome() {
+		if ds := b.ctx.Registry.Extensions.decodeExtensions(b.property.Value(), js); len(ds) > 0 {
+			b.errors = append(b.errors, ds)
+		}
 	}
 	return b
 }
@@ -216,7 +253,7 @@ func (b *PropertiesBuilder) assignProperty(fn func(b *PropertiesBuilder) rusty.R
 		b.errors = append(b.errors, p.Err())
 	} else {
 		if b.property.IsSome() {
-			b.errors = append(b.errors, fmt.Errorf("property already set"))
+			b.errors = append(b.errors, NewDiagnostic(DiagDuplicateProperty, "property already set"))
 			return b
 		}
 		b.property = rusty.Some(p.Ok())
@@ -236,6 +273,8 @@ func PropertyToJson(iprop Property) JSONDict {
 		return PropertyIntegerToJson(prop)
 	case PropertyNumber:
 		return PropertyNumberToJson(prop)
+	case PropertyRecursive:
+		return PropertyRecursiveToJson(prop)
 	case PropertyObject:
 		return PropertyObjectToJson(prop)
 	default:
@@ -243,6 +282,35 @@ func PropertyToJson(iprop Property) JSONDict {
 	}
 }
 
+// PropertyFileNames returns the files a property tree was loaded from.
+func PropertyFileNames(prop Property) []string {
+	fnames := []string{}
+	seen := map[string]bool{}
+	var walk func(prop Property)
+	walk = func(prop Property) {
+		if _, ok := prop.(PropertyRecursive); ok {
+			return
+		}
+		if prop.Meta().FileName().IsSome() {
+			fname := prop.Meta().FileName().Value()
+			if !seen[fname] {
+				seen[fname] = true
+				fnames = append(fnames, fname)
+			}
+		}
+		switch p := prop.(type) {
+		case PropertyObject:
+			for _, pi := range p.Items() {
+				walk(pi.Property())
+			}
+		case PropertyArray:
+			walk(p.Items())
+		}
+	}
+	walk(prop)
+	return fnames
+}
+
 // func (b *PropertiesBuilder) Resolve(meta PropertyMeta, prop Property) rusty.Result[Property] {
 // 	if prop.Ref().IsSome() && prop.Meta().FileName().IsSome() {
 // 		return rusty.Ok(prop)
@@ -257,16 +325,17 @@ func PropertyToJson(iprop Property) JSONDict {
 
 func (b *PropertiesBuilder) Build() rusty.Result[Property] {
 	if len(b.errors) > 0 {
-		str := ""
-		for _, v := range b.errors {
-			str += v.Error() + "\n"
+		ds := joinDiagnostics(b.errors)
+		if b.filename.IsSome() {
+			ds = ds.InFile(b.filename.Value())
 		}
-		return rusty.Err[Property](fmt.Errorf(str))
+		return rusty.Err[Property](ds)
 	}
 
 	if b.property.IsNone() {
-		b.errors = append(b.errors, fmt.Errorf("no property set"))
-		return rusty.Err[Property](fmt.Errorf("no property set"))
+		err := NewDiagnostic(DiagSchemaError, "no property set")
+		b.errors = append(b.errors, err)
+		return rusty.Err[Property](Diagnostics{err})
 	}
 	if b.filename.IsSome() {
 		b.property.Value().Meta().SetFileName(b.filename.Value())
@@ -1,6 +1,8 @@
 package entity_generator
 
 import (
+	"sort"
+
 	"github.com/mabels/wueste/entity-generator/rusty"
 )
 
@@ -23,11 +25,17 @@ type PropertyMeta interface {
 	FileName() rusty.Optional[string]
 	SetFileName(fn string) PropertyMeta
 	SetMeta(m Property) PropertyMeta
+	// Extension is the decoded x- keyword name of the property.
+	Extension(name string) rusty.Optional[ExtensionValue]
+	// Extensions are sorted by name.
+	Extensions() []ExtensionValue
+	SetExtension(ev ExtensionValue) PropertyMeta
 }
 
 type propertyMeta struct {
-	parent   rusty.Optional[Property]
-	filename rusty.Optional[string]
+	parent     rusty.Optional[Property]
+	filename   rusty.Optional[string]
+	extensions map[string]ExtensionValue
 }
 
 // SetMeta implements PropertyMeta.
@@ -68,6 +76,38 @@ func (m *propertyMeta) SetParent(p Property) PropertyMeta {
 	return m
 }
 
+// Extension implements PropertyMeta.
+func (m propertyMeta) Extension(name string) rusty.Optional[ExtensionValue] {
+	ev, found := m.extensions[name]
+	if !found {
+		return rusty.None[ExtensionValue]()
+	}
+	return rusty.Some(ev)
+}
+
+// Extensions implements PropertyMeta.
+func (m propertyMeta) Extensions() []ExtensionValue {
+	names := make([]string, 0, len(m.extensions))
+	for name := range m.extensions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	out := make([]ExtensionValue, 0, len(names))
+	for _, name := range names {
+		out = append(out, m.extensions[name])
+	}
+	return out
+}
+
+// SetExtension implements PropertyMeta.
+func (m *propertyMeta) SetExtension(ev ExtensionValue) PropertyMeta {
+	if m.extensions == nil {
+		m.extensions = map[string]ExtensionValue{}
+	}
+	m.extensions[ev.Extension.Name] = ev
+	return m
+}
+
 func NewPropertyMeta() PropertyMeta {
 	return &propertyMeta{}
 }
@@ -81,6 +121,11 @@ type Property interface {
 	Id() string
 	Type() Type
 	Description() rusty.Optional[string]
+	Examples() []interface{}
+	Deprecated() rusty.Optional[bool]
+	Comment() rusty.Optional[string]
+	ReadOnly() rusty.Optional[bool]
+	WriteOnly() rusty.Optional[bool]
 	Ref() rusty.Optional[string]
 	XProperties() map[string]interface{}
 	Meta() PropertyMeta
@@ -94,6 +139,11 @@ type PropertyBuilder struct {
 	Id          string
 	Type        Type
 	Description rusty.Optional[string]
+	Examples    []interface{}
+	Deprecated  rusty.Optional[bool]
+	Comment     rusty.Optional[string]
+	ReadOnly    rusty.Optional[bool]
+	WriteOnly   rusty.Optional[bool]
 	XProperties map[string]interface{}
 	Ref         rusty.Optional[string]
 }
@@ -166,3 +216,28 @@ func (p *property) Type() Type {
 func (p *property) Description() rusty.Optional[string] {
 	return p.param.Description
 }
+
+// Examples implements Property.
+func (p *property) Examples() []interface{} {
+	return p.param.Examples
+}
+
+// Deprecated implements Property.
+func (p *property) Deprecated() rusty.Optional[bool] {
+	return p.param.Deprecated
+}
+
+// Comment implements Property.
+func (p *property) Comment() rusty.Optional[string] {
+	return p.param.Comment
+}
+
+// ReadOnly implements Property.
+func (p *property) ReadOnly() rusty.Optional[bool] {
+	return p.param.ReadOnly
+}
+
+// WriteOnly implements Property.
+func (p *property) WriteOnly() rusty.Optional[bool] {
+	return p.param.WriteOnly
+}
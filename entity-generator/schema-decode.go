@@ -0,0 +1,162 @@
+package entity_generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SchemaFileDecoder is implemented by loaders which pick the decoder by
+// the file name, the others only get the bytes to Unmarshal.
+type SchemaFileDecoder interface {
+	UnmarshalFile(fname string, bytes []byte, v interface{}) error
+}
+
+type SchemaFormat string
+
+const (
+	SchemaJSON  SchemaFormat = "json"
+	SchemaJSON5 SchemaFormat = "json5"
+	SchemaYAML  SchemaFormat = "yaml"
+)
+
+// SchemaFormatByExt returns the format of the file extension, none if it
+// is not known.
+func SchemaFormatByExt(fname string) (SchemaFormat, bool) {
+	switch strings.ToLower(filepath.Ext(fname)) {
+	case ".json":
+		return SchemaJSON, true
+	case ".json5":
+		return SchemaJSON5, true
+	case ".yaml", ".yml":
+		return SchemaYAML, true
+	}
+	return "", false
+}
+
+// SniffSchemaFormat guesses the format of the content, json5 starts with
+// a comment, json with an object or array, everything else is yaml.
+func SniffSchemaFormat(data []byte) SchemaFormat {
+	trimmed := bytes.TrimLeft(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")), " \t\r\n")
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("//")), bytes.HasPrefix(trimmed, []byte("/*")):
+		return SchemaJSON5
+	case bytes.HasPrefix(trimmed, []byte("{")), bytes.HasPrefix(trimmed, []byte("[")):
+		if json.Valid(trimmed) {
+			return SchemaJSON
+		}
+		return SchemaJSON5
+	}
+	return SchemaYAML
+}
+
+// UnmarshalSchema decodes data of the format into v. The objects are
+// decoded into JSONDict to keep the order and the positions of the keys,
+// numbers are float64 like in json.
+func UnmarshalSchema(format SchemaFormat, data []byte, v interface{}) error {
+	switch format {
+	case SchemaJSON:
+		return unmarshalJSON(data, v)
+	case SchemaJSON5:
+		return unmarshalJSON5(data, v)
+	case SchemaYAML:
+		return unmarshalYAML(data, v)
+	}
+	return fmt.Errorf("unknown schema format: %s", format)
+}
+
+var reYamlErrorLine = regexp.MustCompile(`line (\d+):`)
+
+// yamlErrorLine returns the line of the yaml error message, 0 if it has none.
+func yamlErrorLine(err error) int {
+	m := reYamlErrorLine.FindStringSubmatch(err.Error())
+	if m == nil {
+		return 0
+	}
+	line, _ := strconv.Atoi(m[1])
+	return line
+}
+
+func unmarshalYAML(data []byte, v interface{}) error {
+	var node yaml.Node
+	err := yaml.Unmarshal(data, &node)
+	if err != nil {
+		return err
+	}
+	js, ok := v.(JSONDict)
+	if !ok {
+		return node.Decode(v)
+	}
+	val, err := yamlNodeValue(&node)
+	if err != nil {
+		return err
+	}
+	dict, ok := val.(JSONDict)
+	if !ok {
+		return fmt.Errorf("expected an object got %s", jsonTypeOf(val))
+	}
+	for _, k := range dict.Keys() {
+		js.Set(k, dict.Get(k))
+		if pos, found := memberPositionOf(dict, k); found {
+			setMemberPosition(js, k, pos)
+		}
+	}
+	return nil
+}
+
+func yamlNodeValue(node *yaml.Node) (any, error) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			return nil, nil
+		}
+		return yamlNodeValue(node.Content[0])
+	case yaml.AliasNode:
+		return yamlNodeValue(node.Alias)
+	case yaml.MappingNode:
+		js := NewJSONDict()
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			val, err := yamlNodeValue(node.Content[i+1])
+			if err != nil {
+				return nil, err
+			}
+			key := node.Content[i]
+			js.Set(key.Value, val)
+			setMemberPosition(js, key.Value, memberPosition{
+				key:   SourcePosition{Line: key.Line, Column: key.Column},
+				value: SourcePosition{Line: node.Content[i+1].Line, Column: node.Content[i+1].Column},
+			})
+		}
+		return js, nil
+	case yaml.SequenceNode:
+		out := make([]any, 0, len(node.Content))
+		for _, n := range node.Content {
+			val, err := yamlNodeValue(n)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, val)
+		}
+		return out, nil
+	}
+	var val any
+	err := node.Decode(&val)
+	if err != nil {
+		return nil, fmt.Errorf("line %d: %w", node.Line, err)
+	}
+	switch n := val.(type) {
+	case int:
+		return float64(n), nil
+	case int64:
+		return float64(n), nil
+	case uint64:
+		return float64(n), nil
+	}
+	return val, nil
+}
@@ -0,0 +1,131 @@
+package entity_generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSniffSchemaFormat(t *testing.T) {
+	assert.Equal(t, SchemaJSON, SniffSchemaFormat([]byte(" \n{ \"a\": 1 }")))
+	assert.Equal(t, SchemaJSON5, SniffSchemaFormat([]byte("// comment\n{ a: 1 }")))
+	assert.Equal(t, SchemaJSON5, SniffSchemaFormat([]byte("{ a: 1, }")))
+	assert.Equal(t, SchemaYAML, SniffSchemaFormat([]byte("a: 1\n")))
+
+	format, found := SchemaFormatByExt("x/y.schema.YML")
+	assert.True(t, found)
+	assert.Equal(t, SchemaYAML, format)
+	_, found = SchemaFormatByExt("x/y.txt")
+	assert.False(t, found)
+}
+
+func TestUnmarshalSchemaKeepsOrder(t *testing.T) {
+	yamlSchema := []byte(`
+title: Order
+type: object
+properties:
+  zeta: { type: integer, minimum: 3 }
+  alpha:
+    type: array
+    items:
+      type: object
+      properties:
+        y: { type: string }
+        b: { type: string }
+`)
+	json5Schema := []byte(`// the order
+{
+	title: 'Order', /* inline */ type: "object",
+	properties: {
+		zeta: { type: "integer", minimum: 3, },
+		alpha: { type: "array", items: { type: "object", properties: { y: { type: "string" }, b: { type: "string" } } } },
+	},
+}`)
+	for _, data := range [][]byte{yamlSchema, json5Schema} {
+		js := NewJSONDict()
+		assert.NoError(t, NewSchemaLoaderImpl().Unmarshal(data, js))
+		assert.Equal(t, []string{"title", "type", "properties"}, js.Keys())
+		props, _ := AsJSONDict(js.Get("properties"))
+		assert.Equal(t, []string{"zeta", "alpha"}, props.Keys())
+		zeta, _ := AsJSONDict(props.Get("zeta"))
+		assert.Equal(t, float64(3), zeta.Get("minimum"))
+		alpha, _ := AsJSONDict(props.Get("alpha"))
+		items, _ := AsJSONDict(alpha.Get("items"))
+		itemProps, _ := AsJSONDict(items.Get("properties"))
+		assert.Equal(t, []string{"y", "b"}, itemProps.Keys())
+	}
+
+	assert.Error(t, NewSchemaLoaderImpl().Unmarshal([]byte("- a\n- b\n"), NewJSONDict()))
+}
+
+func TestLoadYamlRefJson(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "main.schema.yaml"), []byte(`$id: https://Main
+title: Main
+type: object
+properties:
+  zeta: { type: string }
+  sub: { $ref: "file://sub.schema.json" }
+  alpha: { $ref: "file://other.schema.json5" }
+required: [zeta]
+`), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "sub.schema.json"), []byte(`{
+	"$id": "https://Sub", "title": "Sub", "type": "object",
+	"properties": { "name": { "type": "string" } }
+}`), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "other.schema.json5"), []byte(`{
+	// json5 with comments
+	$id: "https://Other", title: "Other", type: "object",
+	properties: { count: { type: "integer" }, },
+}`), 0644))
+	js := NewJSONDict()
+	js.Set("$ref", "file://"+filepath.Join(dir, "main.schema.yaml"))
+	ctx := PropertyCtx{Registry: NewSchemaRegistry(NewSchemaLoaderImpl())}
+	rProp := NewPropertiesBuilder(ctx).FromJson(js).Build()
+	assert.True(t, rProp.IsOk())
+	po := rProp.Ok().(PropertyObject)
+	assert.Equal(t, "Main", po.Title())
+	names := []string{}
+	for _, item := range po.Items() {
+		names = append(names, item.Name())
+	}
+	assert.Equal(t, []string{"zeta", "sub", "alpha"}, names)
+	assert.Equal(t, "Sub", po.PropertyByName("sub").Ok().Property().(PropertyObject).Title())
+	assert.Equal(t, "Other", po.PropertyByName("alpha").Ok().Property().(PropertyObject).Title())
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "broken.yaml"), []byte("a: 1\nb: [\n"), 0644))
+	js.Set("$ref", "file://"+filepath.Join(dir, "broken.yaml"))
+	ds := AsDiagnostics(NewPropertiesBuilder(ctx).FromJson(js).Build().Err())
+	assert.Equal(t, DiagInvalidJson, ds[0].Code)
+	assert.Equal(t, filepath.Join(dir, "broken.yaml"), ds[0].File)
+	assert.Equal(t, 2, ds[0].Line)
+}
+
+func TestMarshalSchema(t *testing.T) {
+	js := NewJSONDict()
+	assert.NoError(t, UnmarshalSchema(SchemaJSON5, []byte(`{
+		// comments are not kept
+		z: 'a <b> & c', a: [1, {}], e: [],
+	}`), js))
+	bytes, err := MarshalSchema(SchemaJSON, js, "  ")
+	assert.NoError(t, err)
+	assert.Equal(t, `{
+  "z": "a <b> & c",
+  "a": [
+    1,
+    {}
+  ],
+  "e": []
+}
+`, string(bytes))
+	bytes, err = MarshalSchema(SchemaJSON5, js, "\t")
+	assert.NoError(t, err)
+	assert.Equal(t, "{\n\t\"z\": \"a <b> & c\",\n\t\"a\": [\n\t\t1,\n\t\t{}\n\t],\n\t\"e\": []\n}\n", string(bytes))
+	bytes, err = MarshalSchema(SchemaYAML, js, "  ")
+	assert.NoError(t, err)
+	assert.Equal(t, "z: a <b> & c\na:\n  - 1\n  - {}\ne: []\n", string(bytes))
+	_, err = MarshalSchema("toml", js, "  ")
+	assert.Error(t, err)
+}
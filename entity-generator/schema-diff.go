@@ -0,0 +1,317 @@
+package entity_generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mabels/wueste/entity-generator/rusty"
+)
+
+type ChangeKind string
+
+const (
+	COSMETIC   ChangeKind = "cosmetic"
+	COMPATIBLE ChangeKind = "compatible"
+	BREAKING   ChangeKind = "breaking"
+)
+
+// SchemaChange is a difference between two schemas at the JSON pointer
+// Path of the old schema. Reader classifies it for consumers which read
+// payloads of old writers with the new schema, Writer for producers which
+// write payloads with the new schema to old readers.
+type SchemaChange struct {
+	Path    string     `json:"path"`
+	Message string     `json:"message"`
+	Reader  ChangeKind `json:"reader"`
+	Writer  ChangeKind `json:"writer"`
+}
+
+func (c SchemaChange) String() string {
+	return fmt.Sprintf("#%s: %s (reader: %s, writer: %s)", c.Path, c.Message, c.Reader, c.Writer)
+}
+
+type schemaDiff struct {
+	changes []SchemaChange
+}
+
+func (d *schemaDiff) add(path string, reader, writer ChangeKind, format string, args ...any) {
+	d.changes = append(d.changes, SchemaChange{
+		Path:    path,
+		Message: fmt.Sprintf(format, args...),
+		Reader:  reader,
+		Writer:  writer,
+	})
+}
+
+// narrowed accepts less values than before, old payloads could be
+// rejected by new readers, new payloads are still accepted by old ones.
+func (d *schemaDiff) narrowed(path string, format string, args ...any) {
+	d.add(path, BREAKING, COMPATIBLE, format, args...)
+}
+
+// widened accepts more values than before, new payloads could be
+// rejected by old readers.
+func (d *schemaDiff) widened(path string, format string, args ...any) {
+	d.add(path, COMPATIBLE, BREAKING, format, args...)
+}
+
+func optionalString(o rusty.Optional[string]) string {
+	if o.IsNone() {
+		return "<none>"
+	}
+	return fmt.Sprintf("%q", o.Value())
+}
+
+func (d *schemaDiff) description(path string, old, new Property) {
+	if optionalString(old.Description()) != optionalString(new.Description()) {
+		d.add(path, COSMETIC, COSMETIC, "description changed")
+	}
+}
+
+func toFloat64Optional[T int | float64](o rusty.Optional[T]) rusty.Optional[float64] {
+	if o.IsNone() {
+		return rusty.None[float64]()
+	}
+	return rusty.Some(float64(o.Value()))
+}
+
+// bound compares a lower bound, upper bounds are compared with a negated
+// direction.
+func (d *schemaDiff) bound(path string, name string, lower bool, old, new rusty.Optional[float64]) {
+	switch {
+	case old.IsNone() && new.IsNone():
+	case old.IsNone():
+		d.narrowed(path, "%s %v added", name, new.Value())
+	case new.IsNone():
+		d.widened(path, "%s %v removed", name, old.Value())
+	case old.Value() == new.Value():
+	case (new.Value() > old.Value()) == lower:
+		d.narrowed(path, "%s tightened from %v to %v", name, old.Value(), new.Value())
+	default:
+		d.widened(path, "%s loosened from %v to %v", name, old.Value(), new.Value())
+	}
+}
+
+func (d *schemaDiff) defaultValue(path string, old, new string) {
+	if old != new {
+		d.add(path, COMPATIBLE, COMPATIBLE, "default changed from %s to %s", old, new)
+	}
+}
+
+func optionalValue[T any](o rusty.Optional[T]) string {
+	if o.IsNone() {
+		return "<none>"
+	}
+	return fmt.Sprintf("%v", o.Value())
+}
+
+// numericFormatBits orders the numeric formats, no format is the widest.
+var numericFormatBits = map[string]int{
+	"int32":   32,
+	"int64":   64,
+	"float32": 32,
+	"float64": 64,
+	"":        64,
+}
+
+func (d *schemaDiff) numericFormat(path string, old, new rusty.Optional[string]) {
+	oldFormat, newFormat := "", ""
+	if old.IsSome() {
+		oldFormat = old.Value()
+	}
+	if new.IsSome() {
+		newFormat = new.Value()
+	}
+	oldBits, oldFound := numericFormatBits[oldFormat]
+	newBits, newFound := numericFormatBits[newFormat]
+	switch {
+	case oldFormat == newFormat:
+	case !oldFound || !newFound:
+		d.add(path, BREAKING, BREAKING, "format changed from %s to %s", optionalString(old), optionalString(new))
+	case newBits < oldBits:
+		d.narrowed(path, "format narrowed from %s to %s", optionalString(old), optionalString(new))
+	case newBits > oldBits:
+		d.widened(path, "format widened from %s to %s", optionalString(old), optionalString(new))
+	}
+}
+
+func (d *schemaDiff) stringFormat(path string, old, new rusty.Optional[string]) {
+	switch {
+	case optionalString(old) == optionalString(new):
+	case old.IsNone():
+		d.narrowed(path, "format %s added", optionalString(new))
+	case new.IsNone():
+		d.widened(path, "format %s removed", optionalString(old))
+	default:
+		d.add(path, BREAKING, BREAKING, "format changed from %s to %s", optionalString(old), optionalString(new))
+	}
+}
+
+// enumValues formats the values of an enum, strings are quoted.
+func enumValues[T string | int | float64](values []T) []string {
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if s, ok := any(v).(string); ok {
+			out = append(out, fmt.Sprintf("%q", s))
+		} else {
+			out = append(out, fmt.Sprintf("%v", v))
+		}
+	}
+	return out
+}
+
+// missingValues returns the values of a which are not in b.
+func missingValues(a, b []string) []string {
+	in := map[string]bool{}
+	for _, v := range b {
+		in[v] = true
+	}
+	missing := []string{}
+	for _, v := range a {
+		if !in[v] {
+			missing = append(missing, v)
+		}
+	}
+	return missing
+}
+
+// enum compares the allowed values, an empty enum allows every value.
+// Removed values narrow the property and added ones widen it.
+func (d *schemaDiff) enum(path string, old, new []string) {
+	switch {
+	case len(old) == 0 && len(new) == 0:
+	case len(old) == 0:
+		d.narrowed(path, "enum %s added", strings.Join(new, ", "))
+	case len(new) == 0:
+		d.widened(path, "enum %s removed", strings.Join(old, ", "))
+	default:
+		if removed := missingValues(old, new); len(removed) > 0 {
+			d.narrowed(path, "enum values %s removed", strings.Join(removed, ", "))
+		}
+		if added := missingValues(new, old); len(added) > 0 {
+			d.widened(path, "enum values %s added", strings.Join(added, ", "))
+		}
+	}
+}
+
+func (d *schemaDiff) object(path string, old, new PropertyObject) {
+	if old.Id() != new.Id() {
+		d.add(path, BREAKING, BREAKING, "$id changed from %q to %q", old.Id(), new.Id())
+	}
+	if old.Title() != new.Title() {
+		d.add(path, COSMETIC, COSMETIC, "title changed from %q to %q", old.Title(), new.Title())
+	}
+	d.description(path, old, new)
+	for _, oldItem := range old.Items() {
+		itemPath := path + "/properties/" + EscapeJSONPointer(oldItem.Name())
+		rNewItem := new.PropertyByName(oldItem.Name())
+		if rNewItem.IsErr() {
+			if oldItem.Optional() {
+				d.add(itemPath, COMPATIBLE, COMPATIBLE, "optional property %s removed", oldItem.Name())
+			} else {
+				d.add(itemPath, COMPATIBLE, BREAKING, "required property %s removed", oldItem.Name())
+			}
+			continue
+		}
+		newItem := rNewItem.Ok()
+		switch {
+		case oldItem.Optional() && !newItem.Optional():
+			d.narrowed(itemPath, "property %s is now required", oldItem.Name())
+		case !oldItem.Optional() && newItem.Optional():
+			d.widened(itemPath, "property %s is now optional", oldItem.Name())
+		}
+		d.property(itemPath, oldItem.Property(), newItem.Property())
+	}
+	for _, newItem := range new.Items() {
+		if old.PropertyByName(newItem.Name()).IsOk() {
+			continue
+		}
+		itemPath := path + "/properties/" + EscapeJSONPointer(newItem.Name())
+		if newItem.Optional() {
+			d.add(itemPath, COMPATIBLE, COMPATIBLE, "optional property %s added", newItem.Name())
+		} else {
+			d.narrowed(itemPath, "required property %s added", newItem.Name())
+		}
+	}
+}
+
+func (d *schemaDiff) property(path string, old, new Property) {
+	if old.Type() != new.Type() {
+		switch {
+		case old.Type() == INTEGER && new.Type() == NUMBER:
+			d.widened(path, "type widened from %s to %s", old.Type(), new.Type())
+		case old.Type() == NUMBER && new.Type() == INTEGER:
+			d.narrowed(path, "type narrowed from %s to %s", old.Type(), new.Type())
+		default:
+			d.add(path, BREAKING, BREAKING, "type changed from %s to %s", old.Type(), new.Type())
+		}
+		return
+	}
+	_, oldRecursive := old.(PropertyRecursive)
+	_, newRecursive := new.(PropertyRecursive)
+	if oldRecursive || newRecursive {
+		// the properties are compared where the object is defined
+		if old.Id() != new.Id() {
+			d.add(path, BREAKING, BREAKING, "$id changed from %q to %q", old.Id(), new.Id())
+		}
+		return
+	}
+	switch old.Type() {
+	case OBJECT:
+		d.object(path, old.(PropertyObject), new.(PropertyObject))
+	case ARRAY:
+		o, n := old.(PropertyArray), new.(PropertyArray)
+		d.description(path, old, new)
+		d.bound(path, "minItems", true, toFloat64Optional(o.MinItems()), toFloat64Optional(n.MinItems()))
+		d.bound(path, "maxItems", false, toFloat64Optional(o.MaxItems()), toFloat64Optional(n.MaxItems()))
+		d.property(path+"/items", o.Items(), n.Items())
+	case STRING:
+		o, n := old.(PropertyString), new.(PropertyString)
+		d.description(path, old, new)
+		d.stringFormat(path, o.Format(), n.Format())
+		d.enum(path, enumValues(o.Enum()), enumValues(n.Enum()))
+		d.defaultValue(path, optionalString(o.Default()), optionalString(n.Default()))
+	case INTEGER:
+		o, n := old.(PropertyInteger), new.(PropertyInteger)
+		d.description(path, old, new)
+		d.numericFormat(path, o.Format(), n.Format())
+		d.bound(path, "minimum", true, toFloat64Optional(o.Minimum()), toFloat64Optional(n.Minimum()))
+		d.bound(path, "maximum", false, toFloat64Optional(o.Maximum()), toFloat64Optional(n.Maximum()))
+		d.bound(path, "exclusiveMinimum", true, toFloat64Optional(o.ExclusiveMinimum()), toFloat64Optional(n.ExclusiveMinimum()))
+		d.bound(path, "exclusiveMaximum", false, toFloat64Optional(o.ExclusiveMaximum()), toFloat64Optional(n.ExclusiveMaximum()))
+		d.enum(path, enumValues(o.Enum()), enumValues(n.Enum()))
+		d.defaultValue(path, optionalValue(o.Default()), optionalValue(n.Default()))
+	case NUMBER:
+		o, n := old.(PropertyNumber), new.(PropertyNumber)
+		d.description(path, old, new)
+		d.numericFormat(path, o.Format(), n.Format())
+		d.bound(path, "minimum", true, o.Minimum(), n.Minimum())
+		d.bound(path, "maximum", false, o.Maximum(), n.Maximum())
+		d.bound(path, "exclusiveMinimum", true, o.ExclusiveMinimum(), n.ExclusiveMinimum())
+		d.bound(path, "exclusiveMaximum", false, o.ExclusiveMaximum(), n.ExclusiveMaximum())
+		d.enum(path, enumValues(o.Enum()), enumValues(n.Enum()))
+		d.defaultValue(path, optionalValue(o.Default()), optionalValue(n.Default()))
+	case BOOLEAN:
+		o, n := old.(PropertyBoolean), new.(PropertyBoolean)
+		d.description(path, old, new)
+		d.defaultValue(path, optionalValue(o.Default()), optionalValue(n.Default()))
+	}
+}
+
+// DiffSchemas compares two schema trees and classifies every change for
+// the reader and the writer side.
+func DiffSchemas(old, new Property) []SchemaChange {
+	d := &schemaDiff{changes: []SchemaChange{}}
+	d.property("", old, new)
+	return d.changes
+}
+
+// HasBreakingChanges reports if a change breaks one of the given sides.
+func HasBreakingChanges(changes []SchemaChange, reader, writer bool) bool {
+	for _, c := range changes {
+		if (reader && c.Reader == BREAKING) || (writer && c.Writer == BREAKING) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+package entity_generator
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func diffSchemaProperty(t *testing.T, schema string) Property {
+	js := NewJSONDict()
+	assert.NoError(t, json.Unmarshal([]byte(schema), js))
+	return NewPropertiesBuilder(NewTestContext()).FromJson(js).Build().Ok()
+}
+
+func TestDiffSchemas(t *testing.T) {
+	old := diffSchemaProperty(t, `{
+		"$id": "https://Order", "title": "Order", "type": "object",
+		"properties": {
+			"id": { "type": "string" },
+			"note": { "type": "string" },
+			"legacy": { "type": "string" },
+			"count": { "type": "integer", "minimum": 1, "maximum": 10 },
+			"price": { "type": "integer" },
+			"size": { "type": "number" },
+			"tags": { "type": "array", "items": { "type": "string" } },
+			"createdAt": { "type": "string", "description": "when" }
+		},
+		"required": ["id", "legacy", "count"]
+	}`)
+	new := diffSchemaProperty(t, `{
+		"$id": "https://Order/v2", "title": "OrderV2", "type": "object",
+		"properties": {
+			"id": { "type": "string" },
+			"note": { "type": "string" },
+			"count": { "type": "integer", "format": "int32", "minimum": 2, "maximum": 20 },
+			"price": { "type": "number" },
+			"size": { "type": "integer" },
+			"tags": { "type": "array", "items": { "type": "boolean" }, "maxItems": 3 },
+			"createdAt": { "type": "string", "format": "date-time" },
+			"customer": { "type": "string" }
+		},
+		"required": ["id", "note", "count", "customer"]
+	}`)
+	assert.Equal(t, []SchemaChange{
+		{Path: "", Message: `$id changed from "https://Order" to "https://Order/v2"`, Reader: BREAKING, Writer: BREAKING},
+		{Path: "", Message: `title changed from "Order" to "OrderV2"`, Reader: COSMETIC, Writer: COSMETIC},
+		{Path: "/properties/note", Message: "property note is now required", Reader: BREAKING, Writer: COMPATIBLE},
+		{Path: "/properties/legacy", Message: "required property legacy removed", Reader: COMPATIBLE, Writer: BREAKING},
+		{Path: "/properties/count", Message: `format narrowed from <none> to "int32"`, Reader: BREAKING, Writer: COMPATIBLE},
+		{Path: "/properties/count", Message: "minimum tightened from 1 to 2", Reader: BREAKING, Writer: COMPATIBLE},
+		{Path: "/properties/count", Message: "maximum loosened from 10 to 20", Reader: COMPATIBLE, Writer: BREAKING},
+		{Path: "/properties/price", Message: "type widened from integer to number", Reader: COMPATIBLE, Writer: BREAKING},
+		{Path: "/properties/size", Message: "type narrowed from number to integer", Reader: BREAKING, Writer: COMPATIBLE},
+		{Path: "/properties/tags", Message: "maxItems 3 added", Reader: BREAKING, Writer: COMPATIBLE},
+		{Path: "/properties/tags/items", Message: "type changed from string to boolean", Reader: BREAKING, Writer: BREAKING},
+		{Path: "/properties/createdAt", Message: "description changed", Reader: COSMETIC, Writer: COSMETIC},
+		{Path: "/properties/createdAt", Message: `format "date-time" added`, Reader: BREAKING, Writer: COMPATIBLE},
+		{Path: "/properties/customer", Message: "required property customer added", Reader: BREAKING, Writer: COMPATIBLE},
+	}, DiffSchemas(old, new))
+	assert.Empty(t, DiffSchemas(old, old))
+}
+
+func TestDiffSchemasEnum(t *testing.T) {
+	old := diffSchemaProperty(t, `{
+		"$id": "https://Order", "title": "Order", "type": "object",
+		"properties": {
+			"state": { "type": "string", "enum": ["new", "paid", "sent"] },
+			"level": { "type": "integer", "enum": [1, 2] },
+			"rate": { "type": "number" },
+			"kind": { "type": "string", "enum": ["a"] }
+		}
+	}`)
+	new := diffSchemaProperty(t, `{
+		"$id": "https://Order", "title": "Order", "type": "object",
+		"properties": {
+			"state": { "type": "string", "enum": ["new", "sent", "canceled"] },
+			"level": { "type": "integer", "enum": [2, 1] },
+			"rate": { "type": "number", "enum": [0.5, 1.5] },
+			"kind": { "type": "string" }
+		}
+	}`)
+	assert.Equal(t, []string{"new", "paid", "sent"}, old.(PropertyObject).PropertyByName("state").Ok().Property().(PropertyString).Enum())
+	assert.Equal(t, []SchemaChange{
+		{Path: "/properties/state", Message: `enum values "paid" removed`, Reader: BREAKING, Writer: COMPATIBLE},
+		{Path: "/properties/state", Message: `enum values "canceled" added`, Reader: COMPATIBLE, Writer: BREAKING},
+		{Path: "/properties/rate", Message: "enum 0.5, 1.5 added", Reader: BREAKING, Writer: COMPATIBLE},
+		{Path: "/properties/kind", Message: `enum "a" removed`, Reader: COMPATIBLE, Writer: BREAKING},
+	}, DiffSchemas(old, new))
+}
+
+func TestHasBreakingChanges(t *testing.T) {
+	changes := []SchemaChange{{Reader: COMPATIBLE, Writer: BREAKING}}
+	assert.False(t, HasBreakingChanges(changes, true, false))
+	assert.True(t, HasBreakingChanges(changes, false, true))
+	assert.True(t, HasBreakingChanges(changes, true, true))
+	assert.False(t, HasBreakingChanges([]SchemaChange{{Reader: COSMETIC, Writer: COMPATIBLE}}, true, true))
+}
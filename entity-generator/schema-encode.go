@@ -0,0 +1,128 @@
+package entity_generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MarshalSchema encodes v in the format with one indent level of indent.
+// The order of the JSONDict keys is kept and html is not escaped. json5
+// is written as json, which is json5 without the comments.
+func MarshalSchema(format SchemaFormat, v interface{}, indent string) ([]byte, error) {
+	switch format {
+	case SchemaJSON, SchemaJSON5:
+		out := &bytes.Buffer{}
+		if err := writeSchemaJSON(out, v, "", indent); err != nil {
+			return nil, err
+		}
+		out.WriteByte('\n')
+		return out.Bytes(), nil
+	case SchemaYAML:
+		node, err := schemaYAMLNode(v)
+		if err != nil {
+			return nil, err
+		}
+		out := &bytes.Buffer{}
+		enc := yaml.NewEncoder(out)
+		enc.SetIndent(len(indent))
+		if err := enc.Encode(node); err != nil {
+			return nil, err
+		}
+		if err := enc.Close(); err != nil {
+			return nil, err
+		}
+		return out.Bytes(), nil
+	}
+	return nil, fmt.Errorf("unknown schema format: %s", format)
+}
+
+// writeSchemaJSON writes v like json.MarshalIndent without escaping html,
+// prefix is the indent of the current level.
+func writeSchemaJSON(out *bytes.Buffer, v interface{}, prefix string, indent string) error {
+	switch v := v.(type) {
+	case JSONDict:
+		if v.Len() == 0 {
+			out.WriteString("{}")
+			return nil
+		}
+		out.WriteString("{\n")
+		for i, key := range v.Keys() {
+			out.WriteString(prefix + indent)
+			if err := writeSchemaJSON(out, key, prefix+indent, indent); err != nil {
+				return err
+			}
+			out.WriteString(": ")
+			if err := writeSchemaJSON(out, v.Get(key), prefix+indent, indent); err != nil {
+				return err
+			}
+			if i < v.Len()-1 {
+				out.WriteByte(',')
+			}
+			out.WriteByte('\n')
+		}
+		out.WriteString(prefix + "}")
+		return nil
+	case []interface{}:
+		if len(v) == 0 {
+			out.WriteString("[]")
+			return nil
+		}
+		out.WriteString("[\n")
+		for i, item := range v {
+			out.WriteString(prefix + indent)
+			if err := writeSchemaJSON(out, item, prefix+indent, indent); err != nil {
+				return err
+			}
+			if i < len(v)-1 {
+				out.WriteByte(',')
+			}
+			out.WriteByte('\n')
+		}
+		out.WriteString(prefix + "]")
+		return nil
+	}
+	enc := json.NewEncoder(out)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent(prefix, indent)
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	// Encode ends with a newline
+	out.Truncate(out.Len() - 1)
+	return nil
+}
+
+// schemaYAMLNode returns the yaml node of v with the order of the JSONDict
+// keys.
+func schemaYAMLNode(v interface{}) (*yaml.Node, error) {
+	switch v := v.(type) {
+	case JSONDict:
+		node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		for _, key := range v.Keys() {
+			val, err := schemaYAMLNode(v.Get(key))
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, val)
+		}
+		return node, nil
+	case []interface{}:
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, item := range v {
+			val, err := schemaYAMLNode(item)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, val)
+		}
+		return node, nil
+	}
+	node := &yaml.Node{}
+	if err := node.Encode(v); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
@@ -0,0 +1,30 @@
+package entity_generator
+
+// SchemaError is the pointer form of a Diagnostic for errors.As and the
+// sentinels of errors.Is, the Code of the diagnostic is the kind of the
+// error and the Pointer its path into File. A Diagnostic is a target of
+// errors.As as well.
+type SchemaError struct {
+	Diagnostic
+}
+
+func schemaError(code string) *SchemaError {
+	return &SchemaError{Diagnostic: Diagnostic{Code: code}}
+}
+
+// the kinds of schema errors to match with errors.Is
+var (
+	ErrSchema            = schemaError(DiagSchemaError)
+	ErrInvalidJson       = schemaError(DiagInvalidJson)
+	ErrInvalidRef        = schemaError(DiagInvalidRef)
+	ErrRefNotFound       = schemaError(DiagRefNotFound)
+	ErrRefCycle          = schemaError(DiagRefCycle)
+	ErrMissingType       = schemaError(DiagMissingType)
+	ErrInvalidType       = schemaError(DiagInvalidType)
+	ErrMissingId         = schemaError(DiagMissingId)
+	ErrInvalidProperties = schemaError(DiagInvalidProperties)
+	ErrInvalidRequired   = schemaError(DiagInvalidRequired)
+	ErrInvalidItems      = schemaError(DiagInvalidItems)
+	ErrDuplicateProperty = schemaError(DiagDuplicateProperty)
+	ErrInvalidExtension  = schemaError(DiagInvalidExtension)
+)
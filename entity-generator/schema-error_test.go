@@ -0,0 +1,73 @@
+package entity_generator
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchemaError(t *testing.T) {
+	fsys := fstest.MapFS{
+		"base.schema.json": {Data: []byte(`{
+	"$id": "https://Base",
+	"type": "object",
+	"properties": {
+		"missing": { "$ref": "file://missing.schema.json" },
+		"required": { "type": "object", "properties": {}, "required": "x" }
+	}
+}`)},
+		"broken.schema.json": {Data: []byte(`{ "broken": `)},
+	}
+	ctx := PropertyCtx{Registry: NewSchemaRegistry(NewFSSchemaLoader(fsys))}
+	err := LoadSchemaFile(ctx, "base.schema.json").Err()
+
+	assert.True(t, errors.Is(err, ErrRefNotFound))
+	assert.True(t, errors.Is(err, ErrInvalidRequired))
+	assert.False(t, errors.Is(err, ErrInvalidJson))
+	// the cause of the failure is wrapped
+	assert.True(t, errors.Is(err, fs.ErrNotExist))
+	assert.True(t, errors.Is(err, &SchemaError{Diagnostic{Code: DiagRefNotFound, Pointer: "/properties/missing/$ref"}}))
+	assert.False(t, errors.Is(err, &SchemaError{Diagnostic{Code: DiagRefNotFound, Pointer: "/properties/required"}}))
+
+	var se *SchemaError
+	assert.True(t, errors.As(err, &se))
+	assert.Equal(t, DiagRefNotFound, se.Code)
+	assert.Equal(t, "/properties/missing/$ref", se.Pointer)
+	assert.Equal(t, "/base.schema.json", se.File)
+
+	// wrapping keeps the errors reachable
+	wrapped := fmt.Errorf("load: %w", err)
+	assert.True(t, errors.Is(wrapped, ErrInvalidRequired))
+	assert.Len(t, AsDiagnostics(wrapped), 2)
+	assert.Len(t, AsDiagnostics(se), 1)
+
+	// errors.As reaches the first diagnostic, all of them are in Diagnostics
+	var d Diagnostic
+	assert.True(t, errors.As(wrapped, &d))
+	assert.Equal(t, DiagRefNotFound, d.Code)
+	var ds Diagnostics
+	assert.True(t, errors.As(wrapped, &ds))
+	assert.Equal(t, DiagInvalidRequired, ds[1].Code)
+
+	err = LoadSchemaFile(ctx, "broken.schema.json").Err()
+	assert.True(t, errors.Is(err, ErrInvalidJson))
+	assert.NotContains(t, fmt.Sprint(err), "%!")
+}
+
+func TestSchemaErrorRoundTrip(t *testing.T) {
+	d := NewDiagnostic(DiagDialectKeyword, "id is draft-04")
+	d.Severity = SeverityWarning
+	d.File, d.Pointer, d.Line, d.Column = "x.json", "/id", 2, 3
+	var se *SchemaError
+	assert.True(t, errors.As(Diagnostics{d}, &se))
+	assert.Equal(t, SeverityWarning, se.Severity)
+	assert.Equal(t, d, se.Diagnostic)
+	assert.False(t, AsDiagnostics(se).HasErrors())
+
+	// a SchemaError without severity is an error
+	assert.Equal(t, SeverityError, AsDiagnostics(ErrInvalidJson)[0].Severity)
+}
@@ -2,11 +2,14 @@ package entity_generator
 
 import (
 	"encoding/json"
-	"fmt"
+	"errors"
+	"io/fs"
 	"os"
 	"path"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/mabels/wueste/entity-generator/rusty"
 )
@@ -21,8 +24,9 @@ type SchemaLoader interface {
 	// LoadRef(refVal string) (Property, error)
 }
 
+// SchemaRegistryItem is a loaded file of the registry, which entities are
+// generated is tracked by ClaimOutput.
 type SchemaRegistryItem interface {
-	Written() bool
 	JSonFile() JSonFile
 }
 
@@ -32,23 +36,34 @@ type JSonFile struct {
 }
 
 type schemaRegistryItem struct {
-	written bool
-	// prop    Property
 	jsonFile JSonFile
-}
-
-func (sri *schemaRegistryItem) Written() bool {
-	return sri.written
+	warnings Diagnostics
 }
 
 func (sri *schemaRegistryItem) JSonFile() JSonFile {
 	return sri.jsonFile
 }
 
+// registryLoad is a file which is loading, the other callers wait for
+// done and share the result.
+type registryLoad struct {
+	done   chan struct{}
+	result rusty.Result[JSonFile]
+}
+
+// SchemaRegistry caches the loaded files, it is safe for concurrent use and
+// loads every file once.
 type SchemaRegistry struct {
+	mutex    sync.Mutex
 	registry map[string]*schemaRegistryItem
-	BaseDir  rusty.Optional[string]
-	loader   SchemaLoader
+	loading  map[string]*registryLoad
+	// outputs are the claimed output files by the identity of their schema
+	outputs map[string]string
+	BaseDir rusty.Optional[string]
+	loader  SchemaLoader
+	// Extensions are the x- keywords the builders decode, the others are
+	// warned about.
+	Extensions *ExtensionRegistry
 }
 
 func NewSchemaRegistry(loaders ...SchemaLoader) *SchemaRegistry {
@@ -59,22 +74,28 @@ func NewSchemaRegistry(loaders ...SchemaLoader) *SchemaRegistry {
 		loader = loaders[0]
 	}
 	return &SchemaRegistry{
-		loader:   loader,
-		registry: map[string]*schemaRegistryItem{},
+		loader:     loader,
+		registry:   map[string]*schemaRegistryItem{},
+		loading:    map[string]*registryLoad{},
+		outputs:    map[string]string{},
+		Extensions: DefaultExtensions(),
 	}
 }
 
 func (sr *SchemaRegistry) EnsureJSONProperty(parentFname rusty.Optional[string], inRef string) rusty.Result[JSonFile] {
 	ref := strings.TrimSpace(inRef)
-	if ref[0] == '#' {
-		return rusty.Err[JSonFile](fmt.Errorf("local ref not supported"))
+	if ref == "" || ref == "file://" {
+		return rusty.Err[JSonFile](NewDiagnostic(DiagInvalidRef, "empty ref"))
+	}
+	if strings.HasPrefix(ref, "#") {
+		return rusty.Err[JSonFile](NewDiagnostic(DiagInvalidRef, "local ref not supported: %s", ref))
 	}
 	if !strings.HasPrefix(ref, "file://") {
-		return rusty.Err[JSonFile](fmt.Errorf("only file:// ref supported"))
+		return rusty.Err[JSonFile](NewDiagnostic(DiagInvalidRef, "only file:// ref supported: %s", ref))
 	}
 	fname := ref[len("file://"):]
 	loader := sr.loader
-	if !strings.HasSuffix(fname, "/") {
+	if !strings.HasSuffix(fname, "/") && !path.IsAbs(fname) {
 		dir := "./"
 		if sr.BaseDir.IsSome() {
 			dir = sr.BaseDir.Value()
@@ -87,21 +108,92 @@ func (sr *SchemaRegistry) EnsureJSONProperty(parentFname rusty.Optional[string],
 	}
 	absFname, err := loader.Abs(fname)
 	if err != nil {
-		var err error = fmt.Errorf("no file found for %s->%s", fname, absFname)
-		return rusty.Err[JSonFile](err)
+		return rusty.Err[JSonFile](NewDiagnostic(DiagRefNotFound, "no file found for %s->%s", fname, absFname).wrap(err))
 	}
+	sr.mutex.Lock()
 	sri, found := sr.registry[absFname]
 	if found {
+		sr.mutex.Unlock()
 		return rusty.Ok[JSonFile](sri.jsonFile)
 	}
-	rjsonFile := loadSchema(absFname, sr.loader)
-	if rjsonFile.IsErr() {
-		return rjsonFile
+	load, found := sr.loading[absFname]
+	if found {
+		sr.mutex.Unlock()
+		<-load.done
+		return load.result
+	}
+	load = &registryLoad{done: make(chan struct{})}
+	sr.loading[absFname] = load
+	sr.mutex.Unlock()
+
+	load.result = loadSchema(absFname, sr.loader)
+	var warnings Diagnostics
+	if load.result.IsOk() {
+		js := load.result.Ok()
+		warnings = CheckDialect(js.FileName, js.JSONProperty)
+		warnings = append(warnings, sr.Extensions.CheckExtensions(js.FileName, js.JSONProperty)...)
+		readLatestVocabulary(js.JSONProperty)
+	}
+	sr.mutex.Lock()
+	if load.result.IsOk() {
+		sr.registry[load.result.Ok().FileName] = &schemaRegistryItem{
+			jsonFile: load.result.Ok(),
+			warnings: warnings,
+		}
+	}
+	delete(sr.loading, absFname)
+	sr.mutex.Unlock()
+	close(load.done)
+	return load.result
+}
+
+// Warnings returns the warnings of the loaded files ordered by file.
+func (sr *SchemaRegistry) Warnings() Diagnostics {
+	sr.mutex.Lock()
+	defer sr.mutex.Unlock()
+	fnames := make([]string, 0, len(sr.registry))
+	for fname := range sr.registry {
+		fnames = append(fnames, fname)
+	}
+	sort.Strings(fnames)
+	ds := Diagnostics{}
+	for _, fname := range fnames {
+		ds = append(ds, sr.registry[fname].warnings...)
+	}
+	return ds
+}
+
+// SchemaIdentity identifies the schema of prop for ClaimOutput, it is the
+// file the schema is loaded from and the id of prop.
+func SchemaIdentity(prop Property) string {
+	if prop.Meta().FileName().IsNone() {
+		return prop.Id()
+	}
+	return prop.Meta().FileName().Value() + "#" + prop.Id()
+}
+
+// ClaimOutput returns true for the first claim of the output file by the
+// schema owner, the generators skip files which are generated already in
+// this run. The claim of another schema is an output collision.
+func (sr *SchemaRegistry) ClaimOutput(fname string, owner string) rusty.Result[bool] {
+	sr.mutex.Lock()
+	defer sr.mutex.Unlock()
+	claimed, found := sr.outputs[fname]
+	if !found {
+		sr.outputs[fname] = owner
+		return rusty.Ok(true)
 	}
-	sr.registry[rjsonFile.Ok().FileName] = &schemaRegistryItem{
-		jsonFile: rjsonFile.Ok(),
+	if claimed != owner {
+		return rusty.Err[bool](NewDiagnostic(DiagOutputCollision, "output %s of %s is generated for %s already", fname, owner, claimed))
 	}
-	return rjsonFile
+	return rusty.Ok(false)
+}
+
+// ResetOutputs starts a new run, all output files can be claimed again.
+func (sr *SchemaRegistry) ResetOutputs() {
+	sr.mutex.Lock()
+	defer sr.mutex.Unlock()
+	sr.outputs = map[string]string{}
 }
 
 // func (sr *SchemaRegistry) EnsureSchema(key string, parentFname rusty.Optional[string], fn func(fname string) rusty.Result[Property]) rusty.Result[Property] {
@@ -113,7 +205,7 @@ func (sr *SchemaRegistry) EnsureJSONProperty(parentFname rusty.Optional[string],
 // 		return rusty.Err[Property](fmt.Errorf("only file:// ref supported"))
 // 	}
 // 	fname := ref[len("file://"):]
-// 	if !strings.HasSuffix(fname, "/") {
+// 	if !strings.HasSuffix(fname, "/") && !path.IsAbs(fname) {
 // 		dir := "./"
 // 		if sr.BaseDir.IsSome() {
 // 			dir = sr.BaseDir.Value()
@@ -159,24 +251,19 @@ func (sr *SchemaRegistry) EnsureJSONProperty(parentFname rusty.Optional[string],
 // 	return pi
 // }
 
-// func (sr *SchemaRegistry) SetWritten(prop Property) bool {
-// 	sri, found := sr.registry[prop.Meta().FileName().Value()]
-// 	if !found {
-// 		panic("schema not found in registry: " + prop.Id())
-// 	}
-// 	sri.written = true
-// 	return sri.written
-// }
-
-// func (sr *SchemaRegistry) IsWritten(prop JSonFile) bool {
-// 	sri, found := sr.registry[prop.FileName]
-// 	if !found {
-// 		return false
-// 	}
-// 	return sri.written
-// }
+// Invalidate drops a loaded file from the registry, so the next reference
+// loads it again.
+func (sr *SchemaRegistry) Invalidate(absFname string) bool {
+	sr.mutex.Lock()
+	defer sr.mutex.Unlock()
+	_, found := sr.registry[absFname]
+	delete(sr.registry, absFname)
+	return found
+}
 
 func (sr *SchemaRegistry) Items() []SchemaRegistryItem {
+	sr.mutex.Lock()
+	defer sr.mutex.Unlock()
 	ret := []SchemaRegistryItem{}
 	for _, v := range sr.registry {
 		ret = append(ret, v)
@@ -221,7 +308,7 @@ func (sl SchemaLoaderImpl) Abs(fname string) (string, error) {
 			return filepath.Abs(incFname)
 		}
 	}
-	return fname, fmt.Errorf("file not found: %s", fname)
+	return fname, &fs.PathError{Op: "abs", Path: fname, Err: fs.ErrNotExist}
 
 }
 
@@ -231,32 +318,65 @@ func (sl SchemaLoaderImpl) ReadFile(fname string) ([]byte, error) {
 	return bytes, err
 }
 
-// Unmarshal implements SchemaLoader.
+// Unmarshal implements SchemaLoader, the format is sniffed from the content.
 func (SchemaLoaderImpl) Unmarshal(bytes []byte, v interface{}) error {
-	return json.Unmarshal(bytes, v)
+	return UnmarshalSchema(SniffSchemaFormat(bytes), bytes, v)
+}
+
+// UnmarshalFile implements SchemaFileDecoder, the format is picked by the
+// file extension and sniffed for unknown extensions.
+func (sl SchemaLoaderImpl) UnmarshalFile(fname string, bytes []byte, v interface{}) error {
+	format, found := SchemaFormatByExt(fname)
+	if !found {
+		return sl.Unmarshal(bytes, v)
+	}
+	return UnmarshalSchema(format, bytes, v)
+}
+
+// DecodeSchemaFile decodes the content of the schema file fname in the
+// format of its extension, a syntax error is a Diagnostic with the line.
+func DecodeSchemaFile(fname string, bytes []byte) rusty.Result[JSONDict] {
+	return loadSchemaFromBytes(fname, bytes, SchemaLoaderImpl{})
 }
 
-func loadSchemaFromBytes(bytes []byte, loader SchemaLoader) rusty.Result[JSONDict] {
+func loadSchemaFromBytes(fname string, bytes []byte, loader SchemaLoader) rusty.Result[JSONDict] {
 	jsonSchema := NewJSONDict()
-	err := loader.Unmarshal(bytes, jsonSchema)
+	var err error
+	if decoder, ok := loader.(SchemaFileDecoder); ok {
+		err = decoder.UnmarshalFile(fname, bytes, jsonSchema)
+	} else {
+		err = loader.Unmarshal(bytes, jsonSchema)
+	}
 	if err != nil {
-		return rusty.Err[JSONDict](fmt.Errorf("error parsing json schema: %v:%w", string(bytes), err))
+		d := NewDiagnostic(DiagInvalidJson, "error parsing schema: %v", err).wrap(err)
+		var syntaxErr *json.SyntaxError
+		var json5Err *json5SyntaxError
+		if errors.As(err, &syntaxErr) {
+			d.Line, d.Column = offsetToLineColumn(bytes, int(syntaxErr.Offset))
+		} else if errors.As(err, &json5Err) {
+			d.Line, d.Column = json5Err.Line, json5Err.Column
+		} else if line := yamlErrorLine(err); line > 0 {
+			// yaml errors only know the line
+			d.Line, d.Column = line, 1
+		}
+		return rusty.Err[JSONDict](d)
 	}
+	setPositionFile(jsonSchema, fname)
 	return rusty.Ok(jsonSchema)
 }
 
 func loadSchema(fname string, loader SchemaLoader) rusty.Result[JSonFile] {
 	fname, err := loader.Abs(fname)
 	if err != nil {
-		return rusty.Err[JSonFile](err)
+		return rusty.Err[JSonFile](NewDiagnostic(DiagRefNotFound, "%v", err).wrap(err))
 	}
 	bytes, err := loader.ReadFile(fname)
 	if err != nil {
-		return rusty.Err[JSonFile](err)
+		return rusty.Err[JSonFile](NewDiagnostic(DiagRefNotFound, "%v", err).wrap(err))
 	}
-	rjs := loadSchemaFromBytes(bytes, loader)
+	rjs := loadSchemaFromBytes(fname, bytes, loader)
 	if rjs.IsErr() {
-		return rusty.Err[JSonFile](rjs.Err())
+		return rusty.Err[JSonFile](AsDiagnostics(rjs.Err()).InFile(fname))
 	}
 	return rusty.Ok[JSonFile](JSonFile{
 		FileName:     fname,
@@ -264,3 +384,22 @@ func loadSchema(fname string, loader SchemaLoader) rusty.Result[JSonFile] {
 	})
 
 }
+
+// LoadSchemaFile loads and builds the schema fname through the loader of
+// the registry, the diagnostics of the $ref itself are reported on fname.
+func LoadSchemaFile(ctx PropertyCtx, fname string) rusty.Result[Property] {
+	prop := NewJSONDict()
+	prop.Set("$ref", "file://"+fname)
+	rProp := NewPropertiesBuilder(ctx).FromJson(prop).Build()
+	if rProp.IsErr() {
+		ds := AsDiagnostics(rProp.Err())
+		for i := range ds {
+			if ds[i].File == "" {
+				ds[i].File = fname
+				ds[i].Pointer = ""
+			}
+		}
+		return rusty.Err[Property](ds)
+	}
+	return rProp
+}
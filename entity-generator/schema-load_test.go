@@ -2,8 +2,13 @@ package entity_generator
 
 import (
 	"encoding/json"
+	"errors"
 	"path/filepath"
+	"sync"
 	"testing"
+	"testing/fstest"
+
+	"github.com/mabels/wueste/entity-generator/rusty"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -129,6 +134,34 @@ func TestSchemaLoadImpl(t *testing.T) {
 	assert.Equal(t, fname, filepath.Join(baseDir, "ts", "ts-generator_test.go"))
 }
 
+func TestSchemaRegistryConcurrent(t *testing.T) {
+	sr := NewSchemaRegistry(NewTestSchemaLoader())
+	var wg sync.WaitGroup
+	files := make([]JSonFile, 16)
+	for i := range files {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			files[i] = sr.EnsureJSONProperty(rusty.None[string](), "file:///abs/base.schema.json").Ok()
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, 1, len(sr.Items()))
+	for _, file := range files {
+		// all callers share the one loaded dict
+		assert.Equal(t, files[0].JSONProperty, file.JSONProperty)
+	}
+
+	assert.True(t, sr.ClaimOutput("out/base.ts", "base").Ok())
+	assert.False(t, sr.ClaimOutput("out/base.ts", "base").Ok())
+	// another schema with the same output
+	rClaim := sr.ClaimOutput("out/base.ts", "other")
+	assert.True(t, rClaim.IsErr())
+	assert.Equal(t, DiagOutputCollision, AsDiagnostics(rClaim.Err())[0].Code)
+	sr.ResetOutputs()
+	assert.True(t, sr.ClaimOutput("out/base.ts", "other").Ok())
+}
+
 // type JsonProperty struct {
 // 	Type        string  `json:"type"`
 // 	Description *string `json:"description,omitempty"`
@@ -371,3 +404,80 @@ func TestSchemaLoadImpl(t *testing.T) {
 // // switch my.()
 // assert.NoError(tx, err)
 // }
+
+func fuzzSeeds(f *testing.F) {
+	for _, seed := range []string{
+		string(JSONBase()),
+		string(JSONSub()),
+		`{ "type": "object", "$id": "x", "properties": { "a": { "$ref": "file://sub.schema.json" } } }`,
+		`{ "type": "object", "$id": "x", "properties": { "a": { "$ref": "" } }, "required": [[]] }`,
+		`{ "type": "array", "items": { "type": ["string"] } }`,
+		`{ "type": "string", "format": {}, "default": [], "enum": [1, {}] }`,
+		`{ "type": "number", "minimum": "a", "default": {} }`,
+		`{ "type": "boolean", "default": [] }`,
+		`{ "type": 7 }`,
+		`{ "type": "unknown" }`,
+		"type: object\n$id: x\nproperties:\n  a:\n    type: integer\n",
+		`[]`,
+		`null`,
+	} {
+		f.Add([]byte(seed), false)
+		f.Add([]byte(seed), true)
+	}
+}
+
+func FuzzLoadSchema(f *testing.F) {
+	fuzzSeeds(f)
+	f.Fuzz(func(t *testing.T, data []byte, yaml bool) {
+		fname := "/fuzz.schema.json"
+		if yaml {
+			fname = "/fuzz.schema.yaml"
+		}
+		fsys := fstest.MapFS{
+			"sub.schema.json":  {Data: JSONSub()},
+			"fuzz.schema.json": {Data: data},
+			"fuzz.schema.yaml": {Data: data},
+		}
+		loader := NewFSSchemaLoader(fsys)
+		rjs := loadSchemaFromBytes(fname, data, loader)
+		if rjs.IsErr() {
+			return
+		}
+		ctx := PropertyCtx{Registry: NewSchemaRegistry(loader)}
+		prop := NewPropertiesBuilder(ctx).FromJson(rjs.Ok()).Build()
+		if prop.IsOk() {
+			PropertyToJson(prop.Ok())
+		}
+		LoadSchemaFile(ctx, fname)
+	})
+}
+
+func TestUntrustedSchema(t *testing.T) {
+	loader := NewFSSchemaLoader(fstest.MapFS{})
+	for src, kind := range map[string]*SchemaError{
+		`{ "type": 7 }`:                   ErrInvalidType,
+		`{ "type": "unknown" }`:           ErrInvalidType,
+		`{ "$ref": "" }`:                  ErrInvalidRef,
+		`{ "$ref": "file://" }`:           ErrInvalidRef,
+		`{ "$ref": "file://x.json" }`:     ErrRefNotFound,
+		`{ "description": "no type" }`:    ErrMissingType,
+		`{ "type": "array", "items": 1 }`: ErrInvalidItems,
+	} {
+		rjs := loadSchemaFromBytes("/t.schema.json", []byte(src), loader)
+		assert.True(t, rjs.IsOk(), src)
+		ctx := PropertyCtx{Registry: NewSchemaRegistry(loader)}
+		err := NewPropertiesBuilder(ctx).FromJson(rjs.Ok()).Build().Err()
+		assert.True(t, errors.Is(err, kind), "%s: %v", src, err)
+	}
+
+	assert.True(t, coerceString([]any{}).IsNone())
+	assert.True(t, coerceBool(map[string]any{}).IsNone())
+	assert.True(t, coerceInt(NewJSONDict()).IsNone())
+	assert.Nil(t, NewJSONDict().Get("missing"))
+
+	ctx := NewTestContext()
+	po := LoadSchemaFile(ctx, "base.schema.json").Ok().(PropertyObject)
+	pi := po.PropertyByName("foo").Ok()
+	assert.Equal(t, pi.Property().Id(), pi.Id())
+	assert.Equal(t, pi.Property().Meta(), pi.Meta())
+}
@@ -0,0 +1,45 @@
+package entity_generator
+
+import "sort"
+
+// lineIndex are the offsets of the line starts of a file.
+type lineIndex []int
+
+func newLineIndex(data []byte) lineIndex {
+	li := lineIndex{0}
+	for i, c := range data {
+		if c == '\n' {
+			li = append(li, i+1)
+		}
+	}
+	return li
+}
+
+func (li lineIndex) position(fname string, offset int) SourcePosition {
+	line := sort.Search(len(li), func(i int) bool { return li[i] > offset }) - 1
+	return SourcePosition{
+		File:   fname,
+		Line:   line + 1,
+		Column: offset - li[line] + 1,
+	}
+}
+
+// setPositionFile assigns the file to the positions the decoders recorded
+// without it.
+func setPositionFile(v any, fname string) {
+	switch val := v.(type) {
+	case []any:
+		for _, item := range val {
+			setPositionFile(item, fname)
+		}
+	case JSONDict:
+		for _, k := range val.Keys() {
+			if pos, found := memberPositionOf(val, k); found && pos.key.File == "" {
+				pos.key.File = fname
+				pos.value.File = fname
+				setMemberPosition(val, k, pos)
+			}
+			setPositionFile(val.Get(k), fname)
+		}
+	}
+}
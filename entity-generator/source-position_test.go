@@ -0,0 +1,62 @@
+package entity_generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordJSONPositions(t *testing.T) {
+	data := []byte(`{
+  "type": "object",
+  "properties": {
+    "list": { "type": "array", "items": [ { "a": 1 } ] }
+  }
+}`)
+	rjs := loadSchemaFromBytes("x.json", data, NewSchemaLoaderImpl())
+	assert.True(t, rjs.IsOk())
+	js := rjs.Ok()
+	assert.Equal(t, SourcePosition{File: "x.json", Line: 2, Column: 3}, keyPosition(js, "type").Value())
+	assert.Equal(t, SourcePosition{File: "x.json", Line: 2, Column: 11}, valuePosition(js, "type").Value())
+	assert.True(t, keyPosition(js, "missing").IsNone())
+
+	props, _ := AsJSONDict(js.Get("properties"))
+	list, _ := AsJSONDict(props.Get("list"))
+	assert.Equal(t, "x.json:4:5", keyPosition(props, "list").Value().String())
+	assert.Equal(t, "x.json:4:15", keyPosition(list, "type").Value().String())
+	item, ok := AsJSONDict(list.Get("items").([]any)[0])
+	assert.True(t, ok)
+	assert.Equal(t, "x.json:4:45", keyPosition(item, "a").Value().String())
+}
+
+func TestRecordYAMLPositions(t *testing.T) {
+	data := []byte("type: object\nproperties:\n  name:\n    type: string\n")
+	rjs := loadSchemaFromBytes("x.yaml", data, NewSchemaLoaderImpl())
+	assert.True(t, rjs.IsOk())
+	js := rjs.Ok()
+	props, _ := AsJSONDict(js.Get("properties"))
+	name, _ := AsJSONDict(props.Get("name"))
+	assert.Equal(t, "x.yaml:3:3", keyPosition(props, "name").Value().String())
+	assert.Equal(t, "x.yaml:4:11", valuePosition(name, "type").Value().String())
+}
+
+func TestYAMLDiagnosticPositions(t *testing.T) {
+	dir := t.TempDir()
+	fname := filepath.Join(dir, "base.schema.yaml")
+	assert.NoError(t, os.WriteFile(fname, []byte(`$id: https://Base
+type: object
+properties:
+  name: { type: string }
+  sub:
+    $ref: "file://missing.schema.json"
+required: 1
+`), 0644))
+	ctx := PropertyCtx{Registry: NewSchemaRegistry(NewSchemaLoaderImpl())}
+	ds := AsDiagnostics(LoadSchemaFile(ctx, fname).Err())
+	assert.Equal(t, 2, len(ds), ds.Error())
+	assert.True(t, strings.HasPrefix(ds[0].Error(), fname+":6:5#/properties/sub/$ref: error[ref-not-found]: "), ds[0].Error())
+	assert.Equal(t, fname+":7:1#/required: error[invalid-required]: required[https://Base] is not []string", ds[1].Error())
+}
@@ -0,0 +1,55 @@
+package template
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	gotemplate "text/template"
+
+	eg "github.com/mabels/wueste/entity-generator"
+)
+
+var reNonIdentifier = regexp.MustCompile("[^a-zA-Z0-9_$]+")
+
+// identifier replaces the characters which are not valid in identifiers.
+func identifier(name string) string {
+	return strings.TrimLeft(reNonIdentifier.ReplaceAllString(name, "_"), "_")
+}
+
+func toJson(value any) (string, error) {
+	bytes, err := json.Marshal(value)
+	return string(bytes), err
+}
+
+// Funcs are the helpers of the templates in addition to the builtins of
+// text/template.
+var Funcs = gotemplate.FuncMap{
+	"lower":  strings.ToLower,
+	"upper":  strings.ToUpper,
+	"pascal": eg.PascalCase,
+	"camel":  eg.CamelCase,
+	"snake":  eg.SnakeCase,
+	"kebab":  eg.KebabCase,
+	"ident":  identifier,
+	// quote is a double quoted string with the escapes of json
+	"quote": func(value any) (string, error) { return toJson(fmt.Sprint(value)) },
+	// squote is a single quoted string
+	"squote": func(value any) string {
+		return "'" + strings.ReplaceAll(strings.ReplaceAll(fmt.Sprint(value), `\`, `\\`), "'", `\'`) + "'"
+	},
+	"json": toJson,
+	"join": func(sep string, list []string) string { return strings.Join(list, sep) },
+	"indent": func(prefix string, text string) string {
+		lines := strings.Split(text, "\n")
+		for i, line := range lines {
+			if line != "" {
+				lines[i] = prefix + line
+			}
+		}
+		return strings.Join(lines, "\n")
+	},
+	"hasPrefix": strings.HasPrefix,
+	"hasSuffix": strings.HasSuffix,
+	"trim":      strings.TrimSpace,
+}
@@ -0,0 +1,24 @@
+package template
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdentifier(t *testing.T) {
+	assert.Equal(t, "Simple_Type", identifier("--Simple Type"))
+}
+
+func TestFuncs(t *testing.T) {
+	quote := Funcs["quote"].(func(any) (string, error))
+	q, err := quote("a \"b\"\n")
+	assert.NoError(t, err)
+	assert.Equal(t, `"a \"b\"\n"`, q)
+	q, err = quote(4)
+	assert.NoError(t, err)
+	assert.Equal(t, `"4"`, q)
+	assert.Equal(t, `'it\'s \\'`, Funcs["squote"].(func(any) string)(`it's \`))
+	assert.Equal(t, "  a\n\n  b", Funcs["indent"].(func(string, string) string)("  ", "a\n\nb"))
+	assert.Equal(t, "a, b", Funcs["join"].(func(string, []string) string)(", ", []string{"a", "b"}))
+}
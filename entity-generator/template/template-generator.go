@@ -0,0 +1,228 @@
+// Package template is the language "template" which renders a directory of
+// text/template files, the --eg-template-dir:
+//
+//	entity/   rendered once for every entity with an EntityData
+//	project/  rendered once for all entities with a Project
+//	*.tmpl    shared templates which can be used by the others
+//
+// The name of an entity or project template without the .tmpl suffix is a
+// template as well, which returns the name of the output file:
+//
+//	entity/{{.Entity.FileName}}.md.tmpl
+//	project/index.md.tmpl
+//
+// The view model are the types Entity, Field, EntityData and Project, the
+// helpers in addition to the builtins are the Funcs.
+package template
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	gotemplate "text/template"
+
+	eg "github.com/mabels/wueste/entity-generator"
+	"github.com/mabels/wueste/entity-generator/rusty"
+)
+
+const templateExt = ".tmpl"
+
+// Templates are the parsed templates of a template dir.
+type Templates struct {
+	Entity  []*gotemplate.Template
+	Project []*gotemplate.Template
+}
+
+func newTemplate(name string) *gotemplate.Template {
+	return gotemplate.New(name).Funcs(Funcs).Option("missingkey=error")
+}
+
+func templateFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	files := []string{}
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), templateExt) {
+			files = append(files, filepath.Join(dir, entry.Name()))
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func parseDir(shared *gotemplate.Template, dir string) ([]*gotemplate.Template, error) {
+	files, err := templateFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	out := []*gotemplate.Template{}
+	for _, fname := range files {
+		content, err := os.ReadFile(fname)
+		if err != nil {
+			return nil, err
+		}
+		tmpl, err := shared.Clone()
+		if err != nil {
+			return nil, err
+		}
+		// the name of the template is the one of the output file
+		tmpl, err = tmpl.New(strings.TrimSuffix(filepath.Base(fname), templateExt)).Parse(string(content))
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, tmpl)
+	}
+	return out, nil
+}
+
+// LoadTemplates parses the templates of the template dir.
+func LoadTemplates(dir string) rusty.Result[*Templates] {
+	if dir == "" {
+		return rusty.Err[*Templates](fmt.Errorf("the language template needs a template dir"))
+	}
+	shared := newTemplate("")
+	files, err := templateFiles(dir)
+	if err != nil {
+		return rusty.Err[*Templates](err)
+	}
+	if len(files) > 0 {
+		shared, err = shared.ParseFiles(files...)
+		if err != nil {
+			return rusty.Err[*Templates](err)
+		}
+	}
+	t := &Templates{}
+	t.Entity, err = parseDir(shared, filepath.Join(dir, "entity"))
+	if err != nil {
+		return rusty.Err[*Templates](err)
+	}
+	t.Project, err = parseDir(shared, filepath.Join(dir, "project"))
+	if err != nil {
+		return rusty.Err[*Templates](err)
+	}
+	if len(t.Entity) == 0 && len(t.Project) == 0 {
+		return rusty.Err[*Templates](fmt.Errorf("%s: no templates in entity/ or project/", dir))
+	}
+	return rusty.Ok(t)
+}
+
+// render writes the output of tmpl to the file named by the name of tmpl,
+// owner identifies the schema of the output.
+func render(cfg *eg.GeneratorConfig, sl eg.PropertyCtx, tmpl *gotemplate.Template, data any, source string, owner string) error {
+	name := bytes.Buffer{}
+	nameTmpl, err := newTemplate("name").Parse(tmpl.Name())
+	if err != nil {
+		return fmt.Errorf("%s: %w", tmpl.Name(), err)
+	}
+	if err := nameTmpl.Execute(&name, data); err != nil {
+		return err
+	}
+	fname := filepath.Join(cfg.OutputDir, name.String())
+	// a ../ in the rendered name must not write outside of the output dir
+	rel, err := filepath.Rel(cfg.OutputDir, fname)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("%s: output %s is outside of %s", tmpl.Name(), name.String(), cfg.OutputDir)
+	}
+	rClaim := sl.Registry.ClaimOutput(fname, owner)
+	if rClaim.IsErr() {
+		return rClaim.Err()
+	}
+	if !rClaim.Ok() {
+		return nil
+	}
+	out := bytes.Buffer{}
+	if err := tmpl.Execute(&out, data); err != nil {
+		return err
+	}
+	cfg.Logf("Generate: %s -> %s\n", source, fname)
+	return cfg.WriteFile(fname, out.Bytes())
+}
+
+// Generators are the entity and the project generator of a target, they
+// share the templates which are parsed once.
+type Generators struct {
+	templates *Templates
+}
+
+// NewGenerators parses the templates of the template dir of cfg.
+func NewGenerators(cfg *eg.GeneratorConfig) rusty.Result[*Generators] {
+	rTemplates := LoadTemplates(cfg.EntityCfg.TemplateDir)
+	if rTemplates.IsErr() {
+		return rusty.Err[*Generators](rTemplates.Err())
+	}
+	return rusty.Ok(&Generators{templates: rTemplates.Ok()})
+}
+
+func loadGenerators(cfg *eg.GeneratorConfig) *Generators {
+	rGenerators := NewGenerators(cfg)
+	if rGenerators.IsErr() {
+		panic(rGenerators.Err())
+	}
+	return rGenerators.Ok()
+}
+
+// TemplateGenerator renders the entity templates for prop and the entities
+// which are used by it, the templates are parsed on every call.
+func TemplateGenerator(cfg *eg.GeneratorConfig, prop eg.Property, sl eg.PropertyCtx) {
+	loadGenerators(cfg).Entity(cfg, prop, sl)
+}
+
+// ProjectGenerator renders the project templates for the entities of the
+// input schemas props, the templates are parsed on every call.
+func ProjectGenerator(cfg *eg.GeneratorConfig, props []eg.Property, sl eg.PropertyCtx) {
+	loadGenerators(cfg).Project(cfg, props, sl)
+}
+
+// Entity renders the entity templates for prop and the entities which are
+// used by it.
+func (g *Generators) Entity(cfg *eg.GeneratorConfig, prop eg.Property, sl eg.PropertyCtx) {
+	po, ok := prop.(eg.PropertyObject)
+	if !ok {
+		panic(fmt.Sprintf("TemplateGenerator: %s is not an object", prop.Id()))
+	}
+	for _, root := range entityRoots(po) {
+		data := EntityData{Config: cfg.EntityCfg, Entity: NewEntity(root)}
+		for _, tmpl := range g.templates.Entity {
+			if err := render(cfg, sl, tmpl, data, data.Entity.SchemaFile, eg.SchemaIdentity(root)); err != nil {
+				panic(err)
+			}
+		}
+	}
+}
+
+// Project renders the project templates for the entities of the input
+// schemas props.
+func (g *Generators) Project(cfg *eg.GeneratorConfig, props []eg.Property, sl eg.PropertyCtx) {
+	project := Project{Config: cfg.EntityCfg}
+	seen := map[string]bool{}
+	for _, prop := range props {
+		po, ok := prop.(eg.PropertyObject)
+		if !ok {
+			continue
+		}
+		for _, root := range entityRoots(po) {
+			e := NewEntity(root)
+			if seen[e.Name] {
+				continue
+			}
+			seen[e.Name] = true
+			project.Entities = append(project.Entities, e)
+		}
+	}
+	sort.Slice(project.Entities, func(i, j int) bool {
+		return project.Entities[i].Name < project.Entities[j].Name
+	})
+	for _, tmpl := range g.templates.Project {
+		if err := render(cfg, sl, tmpl, project, cfg.EntityCfg.TemplateDir, cfg.EntityCfg.TemplateDir); err != nil {
+			panic(err)
+		}
+	}
+}
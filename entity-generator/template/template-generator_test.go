@@ -0,0 +1,133 @@
+package template
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	eg "github.com/mabels/wueste/entity-generator"
+	"github.com/stretchr/testify/assert"
+)
+
+var templateSchemas = fstest.MapFS{
+	"tree.schema.json": {Data: []byte(`{
+	"$id": "https://Tree", "title": "Tree", "type": "object",
+	"description": "a tree",
+	"properties": {
+		"name": { "type": "string", "default": "oak", "x-groups": ["public"] },
+		"age": { "type": "integer", "minimum": 0, "maximum": 5000 },
+		"children": { "type": "array", "maxItems": 8, "items": { "$ref": "file://tree.schema.json" } },
+		"leaf": { "$ref": "file://leaf.schema.json" },
+		"pos": { "$id": "https://Pos", "title": "Pos", "type": "object", "properties": { "x": { "type": "number" } }, "required": ["x"] }
+	},
+	"required": ["name", "children"]
+}`)},
+	"leaf.schema.json": {Data: []byte(`{
+	"$id": "https://Leaf", "title": "Leaf", "type": "object",
+	"properties": { "color": { "type": "string" } }
+}`)},
+}
+
+func writeTemplates(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		fname := filepath.Join(dir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(fname), 0755))
+		assert.NoError(t, os.WriteFile(fname, []byte(content), 0644))
+	}
+	return dir
+}
+
+func TestNewEntity(t *testing.T) {
+	sl := eg.PropertyCtx{Registry: eg.NewSchemaRegistry(eg.NewFSSchemaLoader(templateSchemas))}
+	tree := NewEntity(eg.LoadSchemaFile(sl, "tree.schema.json").Ok().(eg.PropertyObject))
+	assert.Equal(t, "Tree", tree.Name)
+	assert.Equal(t, "tree", tree.FileName)
+	assert.Equal(t, []string{"https://Tree", "Tree"}, tree.Names)
+	assert.Equal(t, "a tree", tree.Description)
+	assert.Equal(t, []string{"Leaf", "Tree"}, tree.Refs)
+	assert.Equal(t, 5, len(tree.Properties))
+
+	name := tree.Properties[0]
+	assert.Equal(t, "name", name.Name)
+	assert.False(t, name.Optional)
+	assert.True(t, name.HasDefault)
+	assert.Equal(t, "oak", name.Default)
+	assert.Equal(t, []string{"public"}, name.Extensions["x-groups"])
+
+	age := tree.Properties[1]
+	assert.True(t, age.Optional)
+	assert.False(t, age.HasDefault)
+	assert.Equal(t, map[string]any{"minimum": 0, "maximum": 5000}, age.Constraints)
+
+	children := tree.Properties[2]
+	assert.Equal(t, map[string]any{"maxItems": 8}, children.Constraints)
+	assert.Equal(t, "Tree", children.Items.Ref)
+	assert.True(t, children.Items.Recursive)
+
+	leaf := tree.Properties[3]
+	assert.Equal(t, "Leaf", leaf.Ref)
+	assert.False(t, leaf.Recursive)
+	assert.Empty(t, leaf.Properties)
+
+	pos := tree.Properties[4]
+	assert.Equal(t, "", pos.Ref)
+	assert.Equal(t, "x", pos.Properties[0].Name)
+	assert.False(t, pos.Properties[0].Optional)
+}
+
+func TestTemplateGenerator(t *testing.T) {
+	dir := writeTemplates(t, map[string]string{
+		"field.tmpl": `{{define "field"}}- {{.Name}}: {{if .Ref}}{{.Ref}}{{else}}{{.Type}}{{end}}{{if .Optional}} (optional){{end}}{{end}}`,
+		"entity/{{.Entity.FileName}}.md.tmpl": `# {{.Entity.Title}} {{.Config.PackageName}}
+{{range .Entity.Properties}}{{template "field" .}}
+{{end}}`,
+		"project/index.md.tmpl": `{{range .Entities}}- [{{.Title}}]({{.FileName}}.md) {{snake .Name | quote}}
+{{end}}`,
+	})
+	sl := eg.PropertyCtx{Registry: eg.NewSchemaRegistry(eg.NewFSSchemaLoader(templateSchemas))}
+	out := eg.NewMemoryOutput()
+	cfg := &eg.GeneratorConfig{
+		OutputDir: "generated",
+		EntityCfg: eg.Config{TemplateDir: dir, PackageName: "pkg"},
+		Output:    out,
+		Log:       io.Discard,
+	}
+	tree := eg.LoadSchemaFile(sl, "tree.schema.json").Ok()
+	TemplateGenerator(cfg, tree, sl)
+	ProjectGenerator(cfg, []eg.Property{tree}, sl)
+	assert.Equal(t, []string{"generated/index.md", "generated/leaf.md", "generated/tree.md"}, out.FileNames())
+	assert.Equal(t, `# Tree pkg
+- name: string
+- age: integer (optional)
+- children: array
+- leaf: Leaf (optional)
+- pos: object (optional)
+`, string(out.File("generated/tree.md").Value()))
+	assert.Equal(t, "# Leaf pkg\n- color: string (optional)\n", string(out.File("generated/leaf.md").Value()))
+	assert.Equal(t, "- [Leaf](leaf.md) \"leaf\"\n- [Tree](tree.md) \"tree\"\n", string(out.File("generated/index.md").Value()))
+}
+
+func TestLoadTemplatesErrors(t *testing.T) {
+	assert.ErrorContains(t, LoadTemplates("").Err(), "needs a template dir")
+	assert.ErrorContains(t, LoadTemplates(t.TempDir()).Err(), "no templates")
+	assert.Error(t, LoadTemplates(writeTemplates(t, map[string]string{"entity/a.tmpl": "{{"})).Err())
+
+	dir := writeTemplates(t, map[string]string{"entity/{{.Entity.FileName}}.tmpl": "{{.Entity.Missing}}"})
+	sl := eg.PropertyCtx{Registry: eg.NewSchemaRegistry(eg.NewFSSchemaLoader(templateSchemas))}
+	cfg := &eg.GeneratorConfig{EntityCfg: eg.Config{TemplateDir: dir}, Output: eg.NewMemoryOutput(), Log: io.Discard}
+	assert.Panics(t, func() {
+		TemplateGenerator(cfg, eg.LoadSchemaFile(sl, "leaf.schema.json").Ok(), sl)
+	})
+
+	// the rendered name must stay in the output dir, a / is 47
+	dir = writeTemplates(t, map[string]string{`project/{{printf "%c%c%cescape.md" 46 46 47}}.tmpl`: "escape"})
+	out := eg.NewMemoryOutput()
+	cfg = &eg.GeneratorConfig{OutputDir: "generated", EntityCfg: eg.Config{TemplateDir: dir}, Output: out, Log: io.Discard}
+	assert.PanicsWithError(t, `{{printf "%c%c%cescape.md" 46 46 47}}: output ../escape.md is outside of generated`, func() {
+		ProjectGenerator(cfg, []eg.Property{eg.LoadSchemaFile(sl, "leaf.schema.json").Ok()}, sl)
+	})
+	assert.Empty(t, out.FileNames())
+}
@@ -0,0 +1,219 @@
+package template
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	eg "github.com/mabels/wueste/entity-generator"
+)
+
+// Entity is the view of an object schema which is generated by its own
+// name, the input schemas and the schemas loaded by a $ref.
+type Entity struct {
+	// Id is the $id of the schema.
+	Id    string
+	Title string
+	// Name is the title as identifier, the varname of the ts names.
+	Name string
+	// Names are the names of the payloads of the entity: the id, the title
+	// and the name without duplicates.
+	Names []string
+	// FileName is the lower case name without extension of the generated
+	// files of the entity.
+	FileName string
+	// SchemaFile is the file the schema is loaded from.
+	SchemaFile  string
+	Description string
+	Properties  []*Field
+	// Refs are the names of the entities which are used by the properties,
+	// sorted and without duplicates.
+	Refs       []string
+	Extensions map[string]any
+}
+
+// Field is the view of a property of an object or the items of an array.
+type Field struct {
+	// Name is the json name of the property, it is empty for items.
+	Name string
+	// Type is the json schema type: object, array, string, number,
+	// integer or boolean.
+	Type   string
+	Format string
+	// Optional is true if the property is not required.
+	Optional    bool
+	Description string
+	// Default is the value of default, HasDefault tells a default of nil
+	// from a missing one.
+	Default    any
+	HasDefault bool
+	// Constraints are the keywords minimum, maximum, minItems and maxItems
+	// of the property which are set.
+	Constraints map[string]any
+	// Ref is the name of the entity of an object which is loaded by a $ref.
+	Ref string
+	// Recursive is true if the Ref refers back to the entity, the field
+	// needs an indirection in most languages.
+	Recursive bool
+	// Items is the field of the items of an array.
+	Items *Field
+	// Properties are the fields of an object without Ref.
+	Properties []*Field
+	Extensions map[string]any
+}
+
+// Project is the dot of the project templates.
+type Project struct {
+	Config eg.Config
+	// Entities are sorted by name.
+	Entities []*Entity
+}
+
+// EntityData is the dot of the entity templates.
+type EntityData struct {
+	Config eg.Config
+	Entity *Entity
+}
+
+func extensions(prop eg.Property) map[string]any {
+	out := map[string]any{}
+	for _, ev := range prop.Meta().Extensions() {
+		out[ev.Extension.Name] = ev.Value
+	}
+	return out
+}
+
+// NewEntity returns the view of the entity po.
+func NewEntity(po eg.PropertyObject) *Entity {
+	title, allNames, varname := eg.PayloadNames(po)
+	e := &Entity{
+		Id:         po.Id(),
+		Title:      title,
+		Name:       varname,
+		Names:      allNames,
+		FileName:   strings.ToLower(varname),
+		Extensions: extensions(po),
+	}
+	if po.Meta().FileName().IsSome() {
+		e.SchemaFile = po.Meta().FileName().Value()
+	}
+	if po.Description().IsSome() {
+		e.Description = po.Description().Value()
+	}
+	refs := map[string]bool{}
+	for _, pi := range po.Items() {
+		f := newField(po, pi.Property(), refs)
+		f.Name = pi.Name()
+		f.Optional = pi.Optional()
+		e.Properties = append(e.Properties, f)
+	}
+	for ref := range refs {
+		e.Refs = append(e.Refs, ref)
+	}
+	sort.Strings(e.Refs)
+	return e
+}
+
+func newField(entity eg.PropertyObject, prop eg.Property, refs map[string]bool) *Field {
+	f := &Field{
+		Type:        prop.Type(),
+		Constraints: map[string]any{},
+		Extensions:  extensions(prop),
+	}
+	if prop.Description().IsSome() {
+		f.Description = prop.Description().Value()
+	}
+	setDefault := func(isSome bool, value func() any) {
+		if isSome {
+			f.Default = value()
+			f.HasDefault = true
+		}
+	}
+	setConstraint := func(name string, isSome bool, value func() any) {
+		if isSome {
+			f.Constraints[name] = value()
+		}
+	}
+	switch p := prop.(type) {
+	case eg.PropertyString:
+		if p.Format().IsSome() {
+			f.Format = p.Format().Value()
+		}
+		setDefault(p.Default().IsSome(), func() any { return p.Default().Value() })
+	case eg.PropertyBoolean:
+		setDefault(p.Default().IsSome(), func() any { return p.Default().Value() })
+	case eg.PropertyInteger:
+		if p.Format().IsSome() {
+			f.Format = p.Format().Value()
+		}
+		setDefault(p.Default().IsSome(), func() any { return p.Default().Value() })
+		setConstraint("minimum", p.Minimum().IsSome(), func() any { return p.Minimum().Value() })
+		setConstraint("maximum", p.Maximum().IsSome(), func() any { return p.Maximum().Value() })
+		setConstraint("exclusiveMinimum", p.ExclusiveMinimum().IsSome(), func() any { return p.ExclusiveMinimum().Value() })
+		setConstraint("exclusiveMaximum", p.ExclusiveMaximum().IsSome(), func() any { return p.ExclusiveMaximum().Value() })
+	case eg.PropertyNumber:
+		if p.Format().IsSome() {
+			f.Format = p.Format().Value()
+		}
+		setDefault(p.Default().IsSome(), func() any { return p.Default().Value() })
+		setConstraint("minimum", p.Minimum().IsSome(), func() any { return p.Minimum().Value() })
+		setConstraint("maximum", p.Maximum().IsSome(), func() any { return p.Maximum().Value() })
+		setConstraint("exclusiveMinimum", p.ExclusiveMinimum().IsSome(), func() any { return p.ExclusiveMinimum().Value() })
+		setConstraint("exclusiveMaximum", p.ExclusiveMaximum().IsSome(), func() any { return p.ExclusiveMaximum().Value() })
+	case eg.PropertyArray:
+		setConstraint("minItems", p.MinItems().IsSome(), func() any { return p.MinItems().Value() })
+		setConstraint("maxItems", p.MaxItems().IsSome(), func() any { return p.MaxItems().Value() })
+		f.Items = newField(entity, p.Items(), refs)
+	case eg.PropertyObject:
+		if eg.IsEntityRoot(p) {
+			_, _, f.Ref = eg.PayloadNames(p)
+			f.Recursive = eg.IsRecursiveRef(p, entity)
+			if _, isRecursive := p.(eg.PropertyRecursive); isRecursive {
+				f.Recursive = true
+			}
+			refs[f.Ref] = true
+			break
+		}
+		for _, pi := range p.Items() {
+			child := newField(entity, pi.Property(), refs)
+			child.Name = pi.Name()
+			child.Optional = pi.Optional()
+			f.Properties = append(f.Properties, child)
+		}
+	}
+	return f
+}
+
+// entityRoots returns prop and the entities which are used by it, every
+// schema file once.
+func entityRoots(prop eg.PropertyObject) []eg.PropertyObject {
+	seen := map[string]bool{}
+	out := []eg.PropertyObject{}
+	var walk func(p eg.Property, root bool)
+	walk = func(p eg.Property, root bool) {
+		if _, isRecursive := p.(eg.PropertyRecursive); isRecursive {
+			return
+		}
+		if po, ok := p.(eg.PropertyObject); ok && (root || eg.IsEntityRoot(po)) {
+			key := po.Id()
+			if po.Meta().FileName().IsSome() {
+				key = filepath.Clean(po.Meta().FileName().Value())
+			}
+			if seen[key] {
+				return
+			}
+			seen[key] = true
+			out = append(out, po)
+		}
+		switch v := p.(type) {
+		case eg.PropertyObject:
+			for _, pi := range v.Items() {
+				walk(pi.Property(), false)
+			}
+		case eg.PropertyArray:
+			walk(v.Items(), false)
+		}
+	}
+	walk(prop, true)
+	return out
+}
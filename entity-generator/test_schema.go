@@ -1,12 +1,12 @@
 package entity_generator
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
-	"os"
+	"io/fs"
 	"path"
-	"path/filepath"
-	"strings"
+	"time"
 
 	"github.com/mabels/wueste/entity-generator/rusty"
 )
@@ -25,73 +25,60 @@ import (
 }
 */
 
-type TestSchemaLoader struct {
-	includeDirs []string
-	// registry *SchemaRegistry
-}
+// schemaFS is a fs.FS of files in memory, it has no directories.
+type schemaFS map[string][]byte
 
-func NewTestSchemaLoader() *TestSchemaLoader {
-	return &TestSchemaLoader{
-		includeDirs: []string{"/abs", "./"},
+// Open implements fs.FS.
+func (m schemaFS) Open(name string) (fs.File, error) {
+	data, found := m[name]
+	if !found {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
 	}
+	return &schemaFSFile{Reader: bytes.NewReader(data), name: name, size: int64(len(data))}, nil
 }
 
-func NewTestContext() PropertyCtx {
-	return PropertyCtx{
-		Registry: NewSchemaRegistry(NewTestSchemaLoader()),
-	}
+// schemaFSFile is an open file of schemaFS, it is its own FileInfo.
+type schemaFSFile struct {
+	*bytes.Reader
+	name string
+	size int64
 }
 
-func (sl TestSchemaLoader) Clone(prependIncDirs ...string) SchemaLoader {
-	return &TestSchemaLoader{
-		includeDirs: append(prependIncDirs, sl.includeDirs...),
+func (f *schemaFSFile) Stat() (fs.FileInfo, error) { return f, nil }
+func (f *schemaFSFile) Close() error               { return nil }
+func (f *schemaFSFile) Name() string               { return path.Base(f.name) }
+func (f *schemaFSFile) Size() int64                { return f.size }
+func (f *schemaFSFile) Mode() fs.FileMode          { return 0444 }
+func (f *schemaFSFile) ModTime() time.Time         { return time.Time{} }
+func (f *schemaFSFile) IsDir() bool                { return false }
+func (f *schemaFSFile) Sys() any                   { return nil }
+
+// TestSchemaFS contains the test schemas below /abs.
+func TestSchemaFS() fs.FS {
+	marshal := func(jf JSonFile) []byte {
+		bytes, _ := json.MarshalIndent(jf.JSONProperty, "", "  ")
+		return bytes
 	}
-}
-
-func (t *TestSchemaLoader) IncludeDirs() []string {
-	return t.includeDirs
-}
-
-func (l *TestSchemaLoader) Abs(path string) (string, error) {
-	if strings.HasPrefix(path, "/abs/") {
-		return path, nil
+	return schemaFS{
+		"abs/unnamed_nested_object.schema.json": JSONUnnamedNestedObject(),
+		"abs/payload.schema.json":               marshal(TestJSONPayloadSchema()),
+		"abs/base.schema.json":                  JSONBase(),
+		"abs/sub.schema.json":                   JSONSub(),
+		"abs/wurst/sub2.schema.json":            JSONSub2(),
+		"abs/wurst/sub3.schema.json":            JSONSub3(),
+		"abs/simple_type.schema.json":           marshal(TestJsonFlatSchema()),
+		"abs/nested_type.schema.json":           marshal(TestJSONSchema()),
 	}
-	return filepath.Join(l.includeDirs[0], path), nil
 }
 
-// ReadFile implements SchemaLoader.
-func (l *TestSchemaLoader) ReadFile(path string) ([]byte, error) {
-	switch path {
-	case "/abs/unnamed_nested_object.schema.json":
-		return JSONUnnamedNestedObject(), nil
-	case "/abs/payload.schema.json":
-		jf := TestJSONPayloadSchema()
-		bytes, _ := json.MarshalIndent(jf.JSONProperty, "", "  ")
-		return bytes, nil
-	case "/abs/base.schema.json":
-		return JSONBase(), nil
-	case "/abs/sub.schema.json":
-		return JSONSub(), nil
-	case "/abs/wurst/sub2.schema.json":
-		return JSONSub2(), nil
-	case "/abs/wurst/sub3.schema.json":
-		return JSONSub3(), nil
-	case "/abs/simple_type.schema.json":
-		jf := TestJsonFlatSchema()
-		bytes, _ := json.MarshalIndent(jf.JSONProperty, "", "  ")
-		return bytes, nil
-	case "/abs/nested_type.schema.json":
-		jf := TestJSONSchema()
-		bytes, _ := json.MarshalIndent(jf.JSONProperty, "", "  ")
-		return bytes, nil
-	default:
-		return nil, fmt.Errorf("Test file not found: %s", path)
-	}
+func NewTestSchemaLoader() *FSSchemaLoader {
+	return NewFSSchemaLoader(TestSchemaFS(), "/abs")
 }
 
-// Unmarshal implements SchemaLoader.
-func (l *TestSchemaLoader) Unmarshal(bytes []byte, v interface{}) error {
-	return json.Unmarshal(bytes, v)
+func NewTestContext() PropertyCtx {
+	return PropertyCtx{
+		Registry: NewSchemaRegistry(NewTestSchemaLoader()),
+	}
 }
 
 func json2JSonFile(inp string) JSonFile {
@@ -127,7 +114,7 @@ func UnnamedNestedObject() JSonFile {
 }
 
 func JSONUnnamedNestedObject() []byte {
-	out, _ := json.MarshalIndent(UnnamedNestedObject, "", "  ")
+	out, _ := json.MarshalIndent(UnnamedNestedObject().JSONProperty, "", "  ")
 	return out
 }
 
@@ -743,39 +730,37 @@ func WriteTestSchema(cfg *GeneratorConfig) string {
 	jsonSchema := PropertyToJson(TestFlatSchema(NewTestContext()).Ok())
 	bytes, _ := json.MarshalIndent(jsonSchema, "", "  ")
 	schemaFile := path.Join(cfg.OutputDir, "simple_type.schema.json")
-	os.WriteFile(schemaFile, bytes, 0644)
+	cfg.WriteFile(schemaFile, bytes)
 	fmt.Println("Wrote schema to -> ", schemaFile)
 
 	jsonSchema = PropertyToJson(TestSchema(NewTestContext()))
 	bytes, _ = json.MarshalIndent(jsonSchema, "", "  ")
 	schemaFile = path.Join(cfg.OutputDir, "nested_type.schema.json")
-	os.WriteFile(schemaFile, bytes, 0644)
+	cfg.WriteFile(schemaFile, bytes)
 	fmt.Println("Wrote schema to -> ", schemaFile)
 
 	jsonSchema = BaseSchema().JSONProperty
 	bytes, _ = json.MarshalIndent(jsonSchema, "", "  ")
 	schemaFile = path.Join(cfg.OutputDir, "base.schema.json")
-	os.WriteFile(schemaFile, bytes, 0644)
+	cfg.WriteFile(schemaFile, bytes)
 	fmt.Println("Wrote schema to -> ", schemaFile)
 
 	jsonSchema = TestJsonSubSchema().JSONProperty
 	bytes, _ = json.MarshalIndent(jsonSchema, "", "  ")
 	schemaFile = path.Join(cfg.OutputDir, "sub.schema.json")
-	os.WriteFile(schemaFile, bytes, 0644)
+	cfg.WriteFile(schemaFile, bytes)
 	fmt.Println("Wrote schema to -> ", schemaFile)
 
 	jsonSchema = Sub2Schema().JSONProperty
 	bytes, _ = json.MarshalIndent(jsonSchema, "", "  ")
 	schemaFile = path.Join(cfg.OutputDir, "wurst/sub2.schema.json")
-	os.MkdirAll(path.Dir(schemaFile), 0755)
-	os.WriteFile(schemaFile, bytes, 0644)
+	cfg.WriteFile(schemaFile, bytes)
 	fmt.Println("Wrote schema to -> ", schemaFile)
 
 	jsonSchema = Sub3Schema().JSONProperty
 	bytes, _ = json.MarshalIndent(jsonSchema, "", "  ")
 	schemaFile = path.Join(cfg.OutputDir, "wurst/sub3.schema.json")
-	os.MkdirAll(path.Dir(schemaFile), 0755)
-	os.WriteFile(schemaFile, bytes, 0644)
+	cfg.WriteFile(schemaFile, bytes)
 	fmt.Println("Wrote schema to -> ", schemaFile)
 
 	return schemaFile
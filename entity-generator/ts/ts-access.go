@@ -0,0 +1,117 @@
+package ts
+
+import (
+	"fmt"
+	"strings"
+
+	eg "github.com/mabels/wueste/entity-generator"
+)
+
+// isReadOnly returns true for the attributes which are assigned by the
+// server, they are left out of the requests.
+func isReadOnly(p eg.Property) bool {
+	return p.ReadOnly().IsSome() && p.ReadOnly().Value()
+}
+
+// isWriteOnly returns true for the attributes which are only sent, like
+// secrets, they are left out of the responses.
+func isWriteOnly(p eg.Property) bool {
+	return p.WriteOnly().IsSome() && p.WriteOnly().Value()
+}
+
+// hasAccessModes returns true if an attribute of prop is readOnly or
+// writeOnly, only then the request and response shapes are generated.
+// Only the attributes of prop are checked, the readOnly and writeOnly
+// attributes of nested objects are kept in the shapes of prop.
+func hasAccessModes(prop eg.PropertyObject) bool {
+	for _, pi := range prop.Items() {
+		if isReadOnly(pi.Property()) || isWriteOnly(pi.Property()) {
+			return true
+		}
+	}
+	return false
+}
+
+// accessShape is the request or the response shape of an entity.
+type accessShape struct {
+	suffix string
+	// method of the builder, it is prefixed with To to not collide with
+	// the setters of attributes named request or response
+	method string
+	// skip returns true for the attributes which are not in the shape
+	skip func(p eg.Property) bool
+}
+
+var accessShapes = []accessShape{
+	{suffix: "Request", method: "ToRequest", skip: isReadOnly},
+	{suffix: "Response", method: "ToResponse", skip: isWriteOnly},
+}
+
+// generateAccessTypes writes the XxxRequest and XxxResponse types which are
+// the XxxObject without the readOnly or writeOnly attributes. XxxObject
+// keeps the writeOnly attributes, it is the input of Coerce and of the
+// requests as well, so XxxResponse is the wire type of the responses.
+func (g *tsGenerator) generateAccessTypes(prop eg.PropertyObject) {
+	if !hasAccessModes(prop) {
+		return
+	}
+	objectType := g.lang.PublicType(getObjectName(prop), "Object")
+	g.bodyWriter.FormatLine("// %s is the complete wire type, it keeps the writeOnly attributes", objectType)
+	g.bodyWriter.FormatLine("// to be sent in requests. The responses are %s without them,", g.lang.PublicType(getObjectName(prop), "Response"))
+	g.bodyWriter.FormatLine("// the requests are %s without the readOnly attributes.", g.lang.PublicType(getObjectName(prop), "Request"))
+	for _, shape := range accessShapes {
+		omit := []string{}
+		for _, pi := range prop.Items() {
+			if shape.skip(pi.Property()) {
+				omit = append(omit, g.lang.Quote(pi.Name()))
+			}
+		}
+		typ := objectType
+		if len(omit) > 0 {
+			typ = g.lang.Generics("Omit", objectType, strings.Join(omit, "|"))
+		}
+		g.bodyWriter.FormatLine("export type %s = %s;", g.lang.PublicType(getObjectName(prop), shape.suffix), typ)
+	}
+	g.bodyWriter.WriteLine()
+}
+
+// writeAccessShapes writes the ToRequest and ToResponse methods of the builder,
+// they validate only the attributes of the shape and return its object.
+func (g *tsGenerator) writeAccessShapes(wr *eg.ForIfWhileLangWriter, prop eg.PropertyObject) {
+	if !hasAccessModes(prop) {
+		return
+	}
+	for _, shape := range accessShapes {
+		items := []eg.PropertyItem{}
+		for _, pi := range prop.Items() {
+			if !shape.skip(pi.Property()) {
+				items = append(items, pi)
+			}
+		}
+		shapeType := g.lang.PublicType(getObjectName(prop), shape.suffix)
+		wr.WriteBlock("", g.lang.ReturnType(
+			g.lang.Call(shape.method), g.lang.Generics("WuesteResult", shapeType)), func(wr *eg.ForIfWhileLangWriter) {
+			wr.WriteBlock("const results =", "", func(wr *eg.ForIfWhileLangWriter) {
+				for _, pi := range items {
+					wr.FormatLine("%s: this._attr.%s.Get(),", g.lang.PrivateName(pi.Name()), g.lang.PrivateName(pi.Name()))
+				}
+			}, " {", "};")
+			wr.WriteLine("const errors: string[] = [];")
+			for _, pi := range items {
+				val := g.lang.CallDot("results", g.lang.PrivateName(pi.Name()))
+				wr.WriteBlock("if", "("+val+".is_err())", func(wr *eg.ForIfWhileLangWriter) {
+					wr.FormatLine("errors.push(%s.unwrap_err().message);", val)
+				})
+			}
+			wr.WriteBlock("if", "(errors.length > 0)", func(wr *eg.ForIfWhileLangWriter) {
+				wr.FormatLine("return WuesteResult.Err(Error(errors.join('\\n')));")
+			})
+			wr.WriteBlock("const v0 =", "", func(wr *eg.ForIfWhileLangWriter) {
+				for _, pi := range items {
+					wr.FormatLine("%s: results.%s.unwrap(),", g.lang.PublicName(pi.Name()), g.lang.PrivateName(pi.Name()))
+				}
+			}, " {", fmt.Sprintf("} as %s;", g.lang.PublicName(getObjectName(prop))))
+			g.writeItemsToObject(wr, items, fmt.Sprintf("WuesteResult.Ok(ret as unknown as %s)", shapeType))
+		})
+	}
+}
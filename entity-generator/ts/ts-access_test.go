@@ -0,0 +1,87 @@
+package ts
+
+import (
+	"io"
+	"testing"
+	"testing/fstest"
+
+	eg "github.com/mabels/wueste/entity-generator"
+	"github.com/stretchr/testify/assert"
+)
+
+var accessSchemas = fstest.MapFS{
+	"account.schema.json": {Data: []byte(`{
+	"$id": "https://Account", "title": "Account", "type": "object",
+	"properties": {
+		"id": { "type": "string", "readOnly": true },
+		"createdAt": { "type": "string", "format": "date-time", "readOnly": true },
+		"name": { "type": "string" },
+		"password": { "type": "string", "writeOnly": true }
+	},
+	"required": ["id", "createdAt", "name", "password"]
+}`)},
+	"call.schema.json": {Data: []byte(`{
+	"$id": "https://Call", "title": "Call", "type": "object",
+	"properties": {
+		"id": { "type": "string", "readOnly": true },
+		"request": { "type": "string" },
+		"response": { "type": "string" }
+	},
+	"required": ["id", "request", "response"]
+}`)},
+	"plain.schema.json": {Data: []byte(`{
+	"$id": "https://Plain", "title": "Plain", "type": "object",
+	"properties": { "name": { "type": "string" } },
+	"required": ["name"]
+}`)},
+}
+
+func generateAccess(t *testing.T, name string) string {
+	sl := eg.PropertyCtx{Registry: eg.NewSchemaRegistry(eg.NewFSSchemaLoader(accessSchemas))}
+	out := eg.NewMemoryOutput()
+	cfg := &eg.GeneratorConfig{
+		OutputDir: "generated",
+		EntityCfg: eg.Config{Indent: "  ", FromWueste: "wueste"},
+		Output:    out,
+		Log:       io.Discard,
+	}
+	TsGenerator(cfg, eg.LoadSchemaFile(sl, name+".schema.json").Ok(), sl)
+	return string(out.File("generated/" + name + ".ts").Value())
+}
+
+func TestGenerateAccessModes(t *testing.T) {
+	account := generateAccess(t, "account")
+	assert.Contains(t, account, "export interface AccountParam {\n  readonly id?: WuesteCoerceTypestring;\n")
+	assert.Contains(t, account, "  readonly name: WuesteCoerceTypestring;\n")
+	assert.Contains(t, account, `// AccountObject is the complete wire type, it keeps the writeOnly attributes
+// to be sent in requests. The responses are AccountResponse without them,
+// the requests are AccountRequest without the readOnly attributes.
+export type AccountRequest = Omit<AccountObject, "id"|"createdAt">;
+`)
+	assert.Contains(t, account, "export type AccountResponse = Omit<AccountObject, \"password\">;\n")
+	assert.Contains(t, account, `  ToRequest(): WuesteResult<AccountRequest> {
+    const results = {
+      _name: this._attr._name.Get(),
+      _password: this._attr._password.Get(),
+    };
+`)
+	assert.Contains(t, account, "    return WuesteResult.Ok(ret as unknown as AccountRequest);\n")
+	assert.Contains(t, account, `  ToResponse(): WuesteResult<AccountResponse> {
+    const results = {
+      _id: this._attr._id.Get(),
+      _createdAt: this._attr._createdAt.Get(),
+      _name: this._attr._name.Get(),
+    };
+`)
+
+	// the attributes request and response keep their setters
+	call := generateAccess(t, "call")
+	assert.Contains(t, call, "  request(v: WuesteCoerceTypestring|")
+	assert.Contains(t, call, "  response(v: WuesteCoerceTypestring|")
+	assert.Contains(t, call, "  ToRequest(): WuesteResult<CallRequest> {\n")
+	assert.Contains(t, call, "  ToResponse(): WuesteResult<CallResponse> {\n")
+
+	plain := generateAccess(t, "plain")
+	assert.NotContains(t, plain, "PlainRequest")
+	assert.NotContains(t, plain, "ToResponse()")
+}
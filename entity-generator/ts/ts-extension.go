@@ -0,0 +1,80 @@
+package ts
+
+import (
+	"encoding/json"
+
+	eg "github.com/mabels/wueste/entity-generator"
+	"github.com/mabels/wueste/entity-generator/rusty"
+)
+
+// ExtensionHook is the behaviour of an eg.Extension in the ts generator, it
+// is registered in Extension.Hooks with the key "ts".
+type ExtensionHook struct {
+	// Reflection returns the value of the extension in the schema
+	// reflection, false leaves it out. Without Reflection the json of the
+	// raw value is written.
+	Reflection func(value any) (string, bool)
+	// Comment returns lines which are written above the attribute in the
+	// interface of the entity.
+	Comment func(value any) []string
+	// Type returns the type of the attribute in the interface of the
+	// entity, false keeps the generated type. The builders still create
+	// the generated type, which has to be assignable to the returned one.
+	Type func(value any) (string, bool)
+}
+
+func extensionHook(ev eg.ExtensionValue) (ExtensionHook, bool) {
+	hook, found := ev.Hook("ts")
+	if !found {
+		return ExtensionHook{}, false
+	}
+	tsHook, ok := hook.(ExtensionHook)
+	return tsHook, ok
+}
+
+// reflectionValue returns the value of the x- keyword in the schema
+// reflection, none leaves it out.
+func reflectionValue(prop eg.Property, key string, raw any) rusty.Result[rusty.Optional[string]] {
+	if ev := prop.Meta().Extension(key); ev.IsSome() {
+		if hook, ok := extensionHook(ev.Value()); ok && hook.Reflection != nil {
+			value, ok := hook.Reflection(ev.Value().Value)
+			if !ok {
+				return rusty.Ok(rusty.None[string]())
+			}
+			return rusty.Ok(rusty.Some(value))
+		}
+	}
+	bytes, err := json.Marshal(raw)
+	if err != nil {
+		d := eg.NewDiagnostic(eg.DiagInvalidExtension, "%s: %v", key, err)
+		if prop.Meta().FileName().IsSome() {
+			d.File = prop.Meta().FileName().Value()
+		}
+		return rusty.Err[rusty.Optional[string]](d)
+	}
+	return rusty.Ok(rusty.Some(string(bytes)))
+}
+
+// extensionType returns the type of the first extension of prop which
+// overrides it.
+func extensionType(prop eg.Property) rusty.Optional[string] {
+	for _, ev := range prop.Meta().Extensions() {
+		if hook, ok := extensionHook(ev); ok && hook.Type != nil {
+			if typ, ok := hook.Type(ev.Value); ok {
+				return rusty.Some(typ)
+			}
+		}
+	}
+	return rusty.None[string]()
+}
+
+// extensionComments are the comment lines of the extensions of prop.
+func extensionComments(prop eg.Property) []string {
+	lines := []string{}
+	for _, ev := range prop.Meta().Extensions() {
+		if hook, ok := extensionHook(ev); ok && hook.Comment != nil {
+			lines = append(lines, hook.Comment(ev.Value)...)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,202 @@
+package ts
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	eg "github.com/mabels/wueste/entity-generator"
+)
+
+const (
+	// LayoutFlat writes all entities into the output dir
+	LayoutFlat = "flat"
+	// LayoutMirror writes the entities into the directories of their
+	// schemas relative to the common directory of the input files
+	LayoutMirror = "mirror"
+)
+
+const (
+	FileCaseLower = "lower"
+	FileCaseKebab = "kebab"
+	FileCaseSnake = "snake"
+	FileCaseAsIs  = "as-is"
+)
+
+var fileCases = map[string]func(string) string{
+	"":            strings.ToLower,
+	FileCaseLower: strings.ToLower,
+	FileCaseKebab: eg.KebabCase,
+	FileCaseSnake: eg.SnakeCase,
+	FileCaseAsIs:  func(name string) string { return name },
+}
+
+// the extensions of the imports, none is the resolution of bundlers and
+// node10, node16 needs .js
+var importExtensions = map[string]string{
+	"":     "",
+	"none": "",
+	".js":  ".js",
+	".ts":  ".ts",
+}
+
+// tsFiles are the names of the generated files and the imports between
+// them, the names are slash separated relative to the output dir without
+// extension.
+type tsFiles struct {
+	cfg *eg.GeneratorConfig
+	// root is the directory which is mirrored, it is empty for the flat
+	// layout
+	root      string
+	fileCase  func(string) string
+	extension string
+}
+
+// commonDir returns the directory which contains all files.
+func commonDir(files []string) string {
+	root := ""
+	for i, file := range files {
+		abs, err := filepath.Abs(file)
+		if err != nil {
+			return ""
+		}
+		dir := filepath.Dir(abs)
+		if i == 0 {
+			root = dir
+			continue
+		}
+		for root != dir && !strings.HasPrefix(dir, strings.TrimSuffix(root, string(filepath.Separator))+string(filepath.Separator)) {
+			parent := filepath.Dir(root)
+			if parent == root {
+				break
+			}
+			root = parent
+		}
+	}
+	return root
+}
+
+func newTsFiles(cfg *eg.GeneratorConfig) tsFiles {
+	f := tsFiles{cfg: cfg}
+	var found bool
+	if f.fileCase, found = fileCases[cfg.EntityCfg.FileCase]; !found {
+		panic(fmt.Sprintf("unknown file case %s, expected %s, %s, %s or %s", cfg.EntityCfg.FileCase,
+			FileCaseLower, FileCaseKebab, FileCaseSnake, FileCaseAsIs))
+	}
+	if f.extension, found = importExtensions[cfg.EntityCfg.ImportExtension]; !found {
+		panic(fmt.Sprintf("unknown import extension %s, expected none, .js or .ts", cfg.EntityCfg.ImportExtension))
+	}
+	switch cfg.EntityCfg.Layout {
+	case "", LayoutFlat:
+	case LayoutMirror:
+		f.root = commonDir(cfg.InputFiles)
+	default:
+		panic(fmt.Sprintf("unknown layout %s, expected %s or %s", cfg.EntityCfg.Layout, LayoutFlat, LayoutMirror))
+	}
+	return f
+}
+
+// entityFile returns the file of the object prop.
+func (f tsFiles) entityFile(prop eg.Property) string {
+	name := f.fileCase(getObjectName(prop))
+	if f.root == "" || prop.Meta().FileName().IsNone() {
+		return name
+	}
+	rel, err := filepath.Rel(f.root, filepath.Dir(prop.Meta().FileName().Value()))
+	// schemas outside of the root are written into the output dir
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return name
+	}
+	return path.Join(filepath.ToSlash(rel), name)
+}
+
+// outputFile returns the path of the generated file.
+func (f tsFiles) outputFile(file string) string {
+	return filepath.Join(f.cfg.OutputDir, filepath.FromSlash(file)+".ts")
+}
+
+// externalPath returns the module specifier of an import which is not
+// generated, relative specifiers like ../../wueste get the extension and
+// packages are kept.
+func (f tsFiles) externalPath(specifier string) string {
+	if strings.HasPrefix(specifier, "./") || strings.HasPrefix(specifier, "../") {
+		return specifier + f.extension
+	}
+	return specifier
+}
+
+// importPath returns the module specifier of the file to in the file from.
+func (f tsFiles) importPath(from, to string) string {
+	rel, err := filepath.Rel(filepath.FromSlash(path.Dir(from)), filepath.FromSlash(to))
+	if err != nil {
+		rel = to
+	}
+	rel = filepath.ToSlash(rel)
+	if !strings.HasPrefix(rel, "../") {
+		rel = "./" + rel
+	}
+	return rel + f.extension
+}
+
+// entityFiles returns the files which are generated for props, the named
+// objects are generated into their own files.
+func (f tsFiles) entityFiles(props []eg.Property) []string {
+	seen := map[string]bool{}
+	var walk func(p eg.Property)
+	walk = func(p eg.Property) {
+		switch v := p.(type) {
+		case eg.PropertyObject:
+			if isNamedType(v) {
+				file := f.entityFile(v)
+				if seen[file] {
+					return
+				}
+				seen[file] = true
+			}
+			if _, isRecursive := v.(eg.PropertyRecursive); isRecursive {
+				return
+			}
+			for _, pi := range v.Items() {
+				walk(pi.Property())
+			}
+		case eg.PropertyArray:
+			walk(v.Items())
+		}
+	}
+	for _, prop := range props {
+		walk(prop)
+	}
+	files := make([]string, 0, len(seen))
+	for file := range seen {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	return files
+}
+
+// TsProjectGenerator writes the index.ts which exports all entities of
+// props, if it is enabled by the config.
+func TsProjectGenerator(cfg *eg.GeneratorConfig, props []eg.Property, sl eg.PropertyCtx) {
+	if !cfg.EntityCfg.Index {
+		return
+	}
+	f := newTsFiles(cfg)
+	fname := f.outputFile("index")
+	rClaim := sl.Registry.ClaimOutput(fname, "index")
+	if rClaim.IsErr() {
+		panic(rClaim.Err())
+	}
+	if !rClaim.Ok() {
+		return
+	}
+	wr := eg.NewForIfWhileLangWriter(eg.ForIfWhileLangWriter{OfsIndent: cfg.EntityCfg.Indent})
+	for _, file := range f.entityFiles(props) {
+		wr.FormatLine("export * from %s;", (&tsLang{}).Quote(f.importPath("index", file)))
+	}
+	cfg.Logf("Generate: index -> %s\n", fname)
+	if err := cfg.WriteFile(fname, []byte(strings.Join(wr.Lines(), ""))); err != nil {
+		panic(err)
+	}
+}
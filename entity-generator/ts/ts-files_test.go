@@ -0,0 +1,153 @@
+package ts
+
+import (
+	"io"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	eg "github.com/mabels/wueste/entity-generator"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommonDir(t *testing.T) {
+	assert.Equal(t, "/a", commonDir([]string{"/a/b/x.json", "/a/c/y.json", "/a/z.json"}))
+	assert.Equal(t, "/a/b", commonDir([]string{"/a/b/x.json"}))
+	assert.Equal(t, "/", commonDir([]string{"/a/x.json", "/b/y.json"}))
+	assert.Equal(t, "/ab", commonDir([]string{"/ab/x.json", "/ab/c/y.json"}))
+	assert.Equal(t, "/", commonDir([]string{"/ab/x.json", "/a/y.json"}))
+	assert.Equal(t, "", commonDir(nil))
+}
+
+func TestImportPath(t *testing.T) {
+	f := tsFiles{}
+	assert.Equal(t, "./leaf", f.importPath("tree", "leaf"))
+	assert.Equal(t, "./sub/leaf", f.importPath("tree", "sub/leaf"))
+	assert.Equal(t, "../leaf", f.importPath("sub/tree", "leaf"))
+	assert.Equal(t, "../b/leaf", f.importPath("a/tree", "b/leaf"))
+	assert.Equal(t, "./leaf", f.importPath("a/tree", "a/leaf"))
+}
+
+var layoutSchemas = fstest.MapFS{
+	"app/user.schema.json": {Data: []byte(`{
+	"$id": "https://User", "title": "User", "type": "object",
+	"properties": {
+		"address": { "$ref": "file://../common/address.schema.json" },
+		"tags": { "type": "array", "items": { "$id": "https://Tag", "title": "Tag", "type": "object", "properties": { "name": { "type": "string" } } } }
+	}
+}`)},
+	"common/address.schema.json": {Data: []byte(`{
+	"$id": "https://Address", "title": "Address", "type": "object",
+	"properties": { "street": { "type": "string" } }
+}`)},
+}
+
+func TestGenerateMirrorLayout(t *testing.T) {
+	sl := eg.PropertyCtx{Registry: eg.NewSchemaRegistry(eg.NewFSSchemaLoader(layoutSchemas))}
+	out := eg.NewMemoryOutput()
+	cfg := &eg.GeneratorConfig{
+		OutputDir:  "generated",
+		InputFiles: []string{"/app/user.schema.json", "/common/address.schema.json"},
+		EntityCfg:  eg.Config{Indent: "  ", FromWueste: "wueste", Layout: LayoutMirror, Index: true},
+		Output:     out,
+		Log:        io.Discard,
+	}
+	user := eg.LoadSchemaFile(sl, "app/user.schema.json").Ok()
+	TsGenerator(cfg, user, sl)
+	TsProjectGenerator(cfg, []eg.Property{user}, sl)
+	assert.Equal(t, []string{
+		"generated/app/user$tag.ts",
+		"generated/app/user.ts",
+		"generated/common/user$address.ts",
+		"generated/index.ts",
+	}, out.FileNames())
+
+	userTs := string(out.File("generated/app/user.ts").Value())
+	assert.Contains(t, userTs, `} from "../common/user$address";`)
+	assert.Contains(t, userTs, `} from "./user$tag";`)
+	assert.Contains(t, userTs, `from "wueste";`)
+	assert.Equal(t, `export * from "./app/user";
+export * from "./app/user$tag";
+export * from "./common/user$address";
+`, string(out.File("generated/index.ts").Value()))
+}
+
+func TestGenerateIndexFlat(t *testing.T) {
+	sl := eg.PropertyCtx{Registry: eg.NewSchemaRegistry(eg.NewFSSchemaLoader(layoutSchemas))}
+	out := eg.NewMemoryOutput()
+	cfg := &eg.GeneratorConfig{
+		OutputDir: "generated",
+		EntityCfg: eg.Config{Indent: "  ", FromWueste: "wueste"},
+		Output:    out,
+		Log:       io.Discard,
+	}
+	user := eg.LoadSchemaFile(sl, "app/user.schema.json").Ok()
+	TsGenerator(cfg, user, sl)
+	// the index is optional
+	TsProjectGenerator(cfg, []eg.Property{user}, sl)
+	assert.Equal(t, []string{"generated/user$address.ts", "generated/user$tag.ts", "generated/user.ts"}, out.FileNames())
+	assert.Contains(t, string(out.File("generated/user.ts").Value()), `} from "./user$address";`)
+
+	cfg.EntityCfg.Index = true
+	TsProjectGenerator(cfg, []eg.Property{user}, sl)
+	assert.Equal(t, "export * from \"./user\";\nexport * from \"./user$address\";\nexport * from \"./user$tag\";\n",
+		string(out.File(filepath.Join("generated", "index.ts")).Value()))
+
+	cfg.EntityCfg.Layout = "nested"
+	assert.Panics(t, func() { newTsFiles(cfg) })
+}
+
+func TestGenerateFileNaming(t *testing.T) {
+	sl := eg.PropertyCtx{Registry: eg.NewSchemaRegistry(eg.NewFSSchemaLoader(layoutSchemas))}
+	out := eg.NewMemoryOutput()
+	cfg := &eg.GeneratorConfig{
+		OutputDir: "generated",
+		EntityCfg: eg.Config{Indent: "  ", FromWueste: "wueste", Index: true,
+			FileCase: FileCaseKebab, ImportExtension: ".js", TypeImports: true},
+		Output: out,
+		Log:    io.Discard,
+	}
+	user := eg.LoadSchemaFile(sl, "app/user.schema.json").Ok()
+	TsGenerator(cfg, user, sl)
+	TsProjectGenerator(cfg, []eg.Property{user}, sl)
+	assert.Equal(t, []string{"generated/index.ts", "generated/user-address.ts", "generated/user-tag.ts", "generated/user.ts"}, out.FileNames())
+	assert.Equal(t, "export * from \"./user.js\";\nexport * from \"./user-address.js\";\nexport * from \"./user-tag.js\";\n",
+		string(out.File("generated/index.ts").Value()))
+
+	userTs := string(out.File("generated/user.ts").Value())
+	assert.Contains(t, userTs, "import type { User$Address, User$AddressCoerceType, User$AddressObject } from \"./user-address.js\";\n")
+	assert.Contains(t, userTs, "import {\n  User$AddressBuilder,\n  User$AddressFactory,\n  User$AddressGetter,\n  User$AddressSchema\n} from \"./user-address.js\";\n")
+	assert.Contains(t, userTs, "import { User$TagBuilder, User$TagToObject } from \"./user-tag.js\";\n")
+	assert.Regexp(t, `import type \{[^}]*WuestenFactory,[^}]*\} from "wueste";`, userTs)
+	assert.Regexp(t, `import \{[^}]*WuesteResult,[^}]*\} from "wueste";`, userTs)
+	assert.NotContains(t, userTs, `from "./user";`)
+
+	for _, fileCase := range []struct{ fileCase, fname string }{
+		{FileCaseSnake, "user_tag"}, {FileCaseAsIs, "User$Tag"}, {FileCaseLower, "user$tag"}, {"", "user$tag"},
+	} {
+		cfg.EntityCfg.FileCase = fileCase.fileCase
+		assert.Equal(t, fileCase.fname, newTsFiles(cfg).entityFile(user.(eg.PropertyObject).Items()[1].Property().(eg.PropertyArray).Items()))
+	}
+	// a relative wueste is imported like the entities
+	cfg.EntityCfg.FromWueste = "../../wueste"
+	out = eg.NewMemoryOutput()
+	cfg.Output = out
+	sl = eg.PropertyCtx{Registry: eg.NewSchemaRegistry(eg.NewFSSchemaLoader(layoutSchemas))}
+	TsGenerator(cfg, eg.LoadSchemaFile(sl, "app/user.schema.json").Ok(), sl)
+	assert.Regexp(t, `import \{[^}]*WuesteResult,[^}]*\} from "../../wueste.js";`, string(out.File("generated/user.ts").Value()))
+
+	// the choices of the config are known
+	for _, fileCase := range eg.FileCases {
+		assert.Contains(t, fileCases, fileCase)
+	}
+	for _, ext := range eg.ImportExtensions {
+		assert.Contains(t, importExtensions, ext)
+	}
+	assert.Equal(t, []string{LayoutFlat, LayoutMirror}, eg.Layouts)
+
+	cfg.EntityCfg.FileCase = "camel"
+	assert.Panics(t, func() { newTsFiles(cfg) })
+	cfg.EntityCfg.FileCase = ""
+	cfg.EntityCfg.ImportExtension = ".mjs"
+	assert.Panics(t, func() { newTsFiles(cfg) })
+}
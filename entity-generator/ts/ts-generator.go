@@ -1,15 +1,13 @@
 package ts
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
-	"os"
-	"path/filepath"
 	"regexp"
 	"sort"
 	"strings"
 
-	"github.com/google/uuid"
 	eg "github.com/mabels/wueste/entity-generator"
 	"github.com/mabels/wueste/entity-generator/rusty"
 	"github.com/mabels/wueste/entity-generator/wueste"
@@ -420,21 +418,30 @@ func isNamedType(p eg.Property) bool {
 }
 
 func (g *tsGenerator) generateClass(prop eg.PropertyObject) {
+	writeJSDoc(g.bodyWriter, prop)
 	g.lang.Interface(g.bodyWriter, "export ", g.lang.PublicType(getObjectName(prop)), prop, func(pi eg.PropertyItem, wr *eg.ForIfWhileLangWriter) {
 		out := []string{}
+		writeJSDoc(wr, pi.Property())
+		for _, line := range extensionComments(pi.Property()) {
+			wr.FormatLine("// %s", line)
+		}
+		typ := g.lang.AsTypeNullable(pi.Property(),
+			WithAddType(func(typ string, addProp eg.Property) {
+				out = append(out, typ)
+				if addProp == nil {
+					g.includes.AddType(g.cfg.EntityCfg.FromWueste, typ)
+				} else {
+					g.includes.AddProperty(typ, addProp)
+				}
+			}),
+		)
+		if override := extensionType(pi.Property()); override.IsSome() {
+			typ = override.Value()
+		}
 		wr.WriteLine(g.lang.Line(
 			g.lang.ReturnType(
 				g.lang.Readonly(g.lang.Type(g.lang.PublicType(pi.Name()), pi.Optional())),
-				g.lang.AsTypeNullable(pi.Property(),
-					WithAddType(func(typ string, addProp eg.Property) {
-						out = append(out, typ)
-						if addProp == nil {
-							g.includes.AddType(g.cfg.EntityCfg.FromWueste, typ)
-						} else {
-							g.includes.AddProperty(typ, addProp)
-						}
-					}),
-				))))
+				typ)))
 		wr.FormatLine("// %v", out)
 
 	})
@@ -446,10 +453,11 @@ func (g *tsGenerator) generateClass(prop eg.PropertyObject) {
 			g.includes.AddProperty(paramName, pi.Property())
 			// g.includes.AddProperty(g.lang.PublicName(paramName, "Param"), pi.Property())
 		}
+		writeJSDoc(wr, pi.Property())
 		wr.WriteLine(g.lang.Line(
 			g.lang.ReturnType(
 				g.lang.Readonly(g.lang.Type(g.lang.PublicType(pi.Name()),
-					pi.Optional() || hasDefault(pi.Property()))),
+					pi.Optional() || hasDefault(pi.Property()) || isReadOnly(pi.Property()))),
 				g.lang.AsTypeNullable(pi.Property(),
 					WithAddType(func(typ string, prop eg.Property) {
 						if prop == nil {
@@ -465,6 +473,7 @@ func (g *tsGenerator) generateClass(prop eg.PropertyObject) {
 }
 
 func (g *tsGenerator) generateJSONDict(prop eg.PropertyObject) {
+	writeJSDoc(g.bodyWriter, prop)
 	g.lang.Interface(g.bodyWriter, "export ", g.lang.PublicType(getObjectName(prop), "Object"), prop, func(pi eg.PropertyItem, wr *eg.ForIfWhileLangWriter) {
 		typ := g.lang.AsTypeNullable(pi.Property())
 		if isNamedType(pi.Property()) {
@@ -474,6 +483,7 @@ func (g *tsGenerator) generateJSONDict(prop eg.PropertyObject) {
 			// 	typ = g.lang.OrType(typ, "undefined")
 			// }
 		}
+		writeJSDoc(wr, pi.Property())
 		wr.WriteLine(g.lang.Line(
 			g.lang.ReturnType(
 				g.lang.Readonly(g.lang.Type(g.lang.Quote(pi.Name()), pi.Optional())),
@@ -517,19 +527,8 @@ func boolAsValue(val bool) string {
 }
 
 func (g *tsGenerator) getNames(prop eg.PropertyObject) Names {
-	title := prop.Title()
-	if title == "" {
-		title = prop.Id()
-	}
-	names := []string{prop.Id()}
-	if prop.Id() != prop.Title() {
-		names = append(names, prop.Title())
-	}
-	varname := g.lang.PublicName(title)
-	if varname != prop.Id() && varname != title {
-		names = append(names, varname)
-	}
-	return Names{title: title, names: names, varname: varname}
+	title, names, varname := eg.PayloadNames(prop)
+	return Names{title: title, names: names, varname: g.lang.keyWordFilter(varname)}
 }
 
 func (g *tsGenerator) generateFactory(prop eg.PropertyObject) {
@@ -688,17 +687,21 @@ func (g *tsGenerator) writeSchema(wr *eg.ForIfWhileLangWriter, prop eg.Property,
 		wr.WriteLine(g.lang.Comma(g.lang.ReturnType("description", g.lang.Quote(prop.Description().Value()))))
 	}
 	if prop.XProperties() != nil {
-		for xk, xv := range prop.XProperties() {
-			jsonXk, err := json.Marshal(xk)
-			if err != nil {
-				panic(err)
+		xks := make([]string, 0, len(prop.XProperties()))
+		for xk := range prop.XProperties() {
+			xks = append(xks, xk)
+		}
+		sort.Strings(xks)
+		for _, xk := range xks {
+			rXv := reflectionValue(prop, xk, prop.XProperties()[xk])
+			if rXv.IsErr() {
+				g.diagnostics = append(g.diagnostics, eg.AsDiagnostics(rXv.Err())...)
+				continue
 			}
-			jsonXv, err := json.Marshal(xv)
-			if err != nil {
-				panic(err)
+			if rXv.Ok().IsNone() {
+				continue
 			}
-
-			wr.WriteLine(g.lang.Comma(g.lang.ReturnType(string(jsonXk), string(jsonXv))))
+			wr.WriteLine(g.lang.Comma(g.lang.ReturnType(g.lang.Quote(xk), rXv.Ok().Value())))
 		}
 	}
 	pdefault := getDefaultForProperty(prop)
@@ -736,7 +739,11 @@ func (g *tsGenerator) writeSchema(wr *eg.ForIfWhileLangWriter, prop eg.Property,
 						wr.WriteLine(g.lang.Comma(g.lang.ReturnType("name", g.lang.Quote(pi.Name()))))
 
 						wr.WriteLine(g.lang.Comma(g.lang.ReturnType("optional", boolAsValue(pi.Optional()))))
-						if pi.Property().Type() == eg.OBJECT && isNamedType(pi.Property()) {
+						if g.isRecursive(pi.Property()) {
+							wr.WriteBlock("property:", "", func(wr *eg.ForIfWhileLangWriter) {
+								g.writeRecursiveSchema(wr, pi.Property())
+							})
+						} else if pi.Property().Type() == eg.OBJECT && isNamedType(pi.Property()) {
 							reflection := g.lang.PublicName(getObjectName(pi.Property()), "Schema")
 							g.includes.AddProperty(reflection, pi.Property())
 							// builderName := g.lang.Call(g.lang.PublicType(pi.Name(), "Builder"))
@@ -770,11 +777,43 @@ func (g *tsGenerator) writeSchema(wr *eg.ForIfWhileLangWriter, prop eg.Property,
 	case eg.ARRAY:
 		pa := prop.(eg.PropertyArray)
 		wr.WriteBlock("items:", "", func(wr *eg.ForIfWhileLangWriter) {
-			g.writeSchema(wr, pa.Items())
+			if g.isRecursive(pa.Items()) {
+				g.writeRecursiveSchema(wr, pa.Items())
+			} else {
+				g.writeSchema(wr, pa.Items())
+			}
 		}, "{", "},")
 	}
 }
 
+// writeRecursiveSchema refers to the schema of a recursive entity by ref,
+// the schema const can not contain itself.
+func (g *tsGenerator) writeRecursiveSchema(wr *eg.ForIfWhileLangWriter, prop eg.Property) {
+	po := prop.(eg.PropertyObject)
+	if po.Id() != "" {
+		wr.WriteLine(g.lang.Comma(g.lang.ReturnType("id", g.lang.Quote(po.Id()))))
+	}
+	wr.WriteLine(g.lang.Comma(g.lang.ReturnType("type", g.lang.Quote(po.Type()))))
+	if po.Title() != "" {
+		wr.WriteLine(g.lang.Comma(g.lang.ReturnType("title", g.lang.Quote(po.Title()))))
+	}
+	wr.WriteLine(g.lang.Comma(g.lang.ReturnType("ref", g.lang.Quote(g.lang.PublicName(getObjectName(po))))))
+}
+
+// isRecursive returns true for the entities which refer back to the
+// generated one, their builders are created lazily.
+func (g *tsGenerator) isRecursive(prop eg.Property) bool {
+	return prop != g.entity && prop.Type() == eg.OBJECT && eg.IsRecursiveRef(prop, g.entity)
+}
+
+// isRecursiveAttribute includes the arrays of recursive entities.
+func (g *tsGenerator) isRecursiveAttribute(prop eg.Property) bool {
+	if pa, ok := prop.(eg.PropertyArray); ok {
+		return g.isRecursive(getItemType(pa))
+	}
+	return g.isRecursive(prop)
+}
+
 func getDefaultForProperty(prop eg.Property) *string {
 	{
 		p, ok := prop.(eg.PropertyString)
@@ -861,12 +900,6 @@ func getObjectName(p eg.Property, namess ...[]string) string {
 	return getObjectName(p.Meta().Parent().Value(), append(names, title))
 }
 
-func getObjectFileName(prop eg.Property) string {
-	fname := getObjectName(prop)
-	fname = fmt.Sprintf("./%s", strings.ToLower(fname))
-	return fname
-}
-
 func (g *tsGenerator) genWuesteBuilderAttribute(name string, pi eg.PropertyItem, paramFns ...func() string) string {
 	prop := pi.Property()
 	paramFn := func() string {
@@ -1090,6 +1123,13 @@ func (g *tsGenerator) generateAttributesClass(prop eg.PropertyObject, resultsCla
 				g.lang.Generics("WuestenAttributeParameter", g.lang.PublicName(getObjectName(prop))))))
 			for _, pi := range prop.Items() {
 				// wr.FormatLine("readonly %s = %s;", g.lang.PrivateName(prop.Name()), g.genWuesteBuilderAttribute(prop.Name(), prop.Property()))
+				field := func(typ string) {
+					if g.isRecursiveAttribute(pi.Property()) {
+						g.writeLazyAttribute(wr, pi, typ)
+						return
+					}
+					wr.WriteLine(g.lang.Readonly(g.lang.ReturnType(g.lang.PrivateName(pi.Name()), typ)))
+				}
 				if pi.Property().Type() == eg.OBJECT && isNamedType(pi.Property()) {
 					piAttrClassName := g.lang.PublicName(getObjectName(pi.Property()), "Builder")
 					g.includes.AddProperty(piAttrClassName, pi.Property())
@@ -1097,17 +1137,14 @@ func (g *tsGenerator) generateAttributesClass(prop eg.PropertyObject, resultsCla
 						coerceType := g.lang.PublicName(getObjectName(pi.Property()), "CoerceType")
 						g.includes.AddProperty(coerceType, pi.Property())
 						g.includes.AddType(g.cfg.EntityCfg.FromWueste, "WuestenObjectOptional")
-						wr.WriteLine(
-							g.lang.Readonly(g.lang.ReturnType(g.lang.PrivateName(pi.Name()),
-								g.lang.Generics("WuestenObjectOptional",
-									piAttrClassName,
-									// g.lang.AsTypeNullable(pi.Property(), WithOptional(pi.Optional()),
-									g.lang.PublicName(getObjectName(pi.Property())),
-									g.lang.AsTypeNullable(pi.Property(), WithAddCoerce(), WithOptional(pi.Optional())),
-								))))
+						field(g.lang.Generics("WuestenObjectOptional",
+							piAttrClassName,
+							// g.lang.AsTypeNullable(pi.Property(), WithOptional(pi.Optional()),
+							g.lang.PublicName(getObjectName(pi.Property())),
+							g.lang.AsTypeNullable(pi.Property(), WithAddCoerce(), WithOptional(pi.Optional())),
+						))
 					} else {
-						wr.WriteLine(
-							g.lang.Readonly(g.lang.ReturnType(g.lang.PrivateName(pi.Name()), piAttrClassName)))
+						field(piAttrClassName)
 					}
 				} else if pi.Property().Type() == eg.ARRAY {
 					name := getObjectName(pi.Property(), []string{pi.Name()})
@@ -1116,18 +1153,14 @@ func (g *tsGenerator) generateAttributesClass(prop eg.PropertyObject, resultsCla
 						coerceType := g.lang.PublicName(getObjectName(pi.Property()), "CoerceType")
 						g.includes.AddProperty(coerceType, pi.Property())
 						g.includes.AddType(g.cfg.EntityCfg.FromWueste, "WuestenObjectOptional")
-						wr.WriteLine(
-							g.lang.Readonly(g.lang.ReturnType(g.lang.PrivateName(pi.Name()),
-								g.lang.Generics("WuestenObjectOptional",
-									piAttrClassName,
-									// g.lang.AsTypeNullable(pi.Property(), WithOptional(pi.Optional()),
-									g.lang.AsType(pi.Property()),
-									g.lang.AsTypeNullable(pi.Property(), WithAddCoerce(), WithOptional(pi.Optional())),
-								))))
+						field(g.lang.Generics("WuestenObjectOptional",
+							piAttrClassName,
+							// g.lang.AsTypeNullable(pi.Property(), WithOptional(pi.Optional()),
+							g.lang.AsType(pi.Property()),
+							g.lang.AsTypeNullable(pi.Property(), WithAddCoerce(), WithOptional(pi.Optional())),
+						))
 					} else {
-						wr.WriteLine(
-							g.lang.Readonly(g.lang.ReturnType(g.lang.PrivateName(pi.Name()),
-								g.lang.PublicName(name, "Builder"))))
+						field(g.lang.PublicName(name, "Builder"))
 					}
 				} else {
 					g.includes.AddType(g.cfg.EntityCfg.FromWueste, "WuestenAttribute")
@@ -1152,6 +1185,9 @@ func (g *tsGenerator) generateAttributesClass(prop eg.PropertyObject, resultsCla
 					wr.WriteLine(g.lang.AssignDefault(g.lang.CallDot("this", "param"), "param"))
 					wr.WriteLine(g.lang.AssignDefault(g.lang.Const("baseName"), g.lang.Call("WuestenAttributeName", "param")))
 					for _, pi := range prop.Items() {
+						if g.isRecursiveAttribute(pi.Property()) {
+							continue
+						}
 						wr.WriteLine(g.lang.AssignDefault(
 							g.lang.CallDot("this", g.lang.PrivateName(pi.Name())), g.genWuesteBuilderAttribute(pi.Name(), pi)))
 					}
@@ -1226,6 +1262,20 @@ func (g *tsGenerator) generateAttributesClass(prop eg.PropertyObject, resultsCla
 	return attrsClassName
 }
 
+// writeLazyAttribute creates the attribute of a recursive entity on first
+// use, the builders would create each other endlessly otherwise.
+func (g *tsGenerator) writeLazyAttribute(wr *eg.ForIfWhileLangWriter, pi eg.PropertyItem, typ string) {
+	lazy := g.lang.CallDot("this", g.lang.PrivateName(pi.Name(), "Lazy"))
+	wr.WriteLine(g.lang.ReturnType(g.lang.Type(g.lang.PrivateName(pi.Name(), "Lazy"), true), typ))
+	wr.WriteBlock("get ", g.lang.ReturnType(g.lang.Call(g.lang.PrivateName(pi.Name())), typ), func(wr *eg.ForIfWhileLangWriter) {
+		wr.WriteIf(g.lang.RoundBrackets(lazy+" === undefined"), func(wr *eg.ForIfWhileLangWriter) {
+			wr.WriteLine(g.lang.AssignDefault(g.lang.Const("baseName"), g.lang.Call("WuestenAttributeName", "this.param")))
+			wr.WriteLine(g.lang.AssignDefault(lazy, g.genWuesteBuilderAttribute(pi.Name(), pi)))
+		})
+		wr.WriteLine(g.lang.Return(lazy))
+	})
+}
+
 func (g *tsGenerator) generateFunctionHandler(wr *eg.ForIfWhileLangWriter, pi eg.PropertyItem) {
 	wr.WriteIf(g.lang.RoundBrackets("typeof v === 'function'"), func(wr *eg.ForIfWhileLangWriter) {
 		switch pi.Property().Type() {
@@ -1335,6 +1385,7 @@ func (g *tsGenerator) generateBuilder(prop eg.PropertyObject) {
 			}
 			g.includes.AddType(g.cfg.EntityCfg.FromWueste, "WuestenFNGetBuilder")
 			paramTyp = g.lang.OrType(paramTyp, g.lang.Generics("WuestenFNGetBuilder", fnGetBuilderType))
+			writeJSDoc(wr, pi.Property())
 			wr.WriteBlock(
 				g.lang.ReturnType(
 					g.lang.Call(g.lang.Type(g.lang.PublicType(pi.Name()), false),
@@ -1407,89 +1458,139 @@ func (g *tsGenerator) generateBuilder(prop eg.PropertyObject) {
 				g.lang.Call("Get", ""), g.lang.Generics("WuesteResult", g.lang.PublicName(getObjectName(prop)))), func(wr *eg.ForIfWhileLangWriter) {
 				wr.WriteLine(g.lang.Return(g.lang.Call("this._attr.Get", "")))
 			})
+			g.writeAccessShapes(wr, prop)
 			// g.includes.AddType(g.cfg.EntityCfg.FromWueste, "WuestePayload")
 		})
 
 }
 
 type tsGenerator struct {
+	entity     eg.Property
 	cfg        *eg.GeneratorConfig
 	lang       tsLang
 	includes   *externalTypes
+	files      tsFiles
 	bodyWriter *eg.ForIfWhileLangWriter
+	// diagnostics are the problems of the entity, it is not written if
+	// there are any
+	diagnostics eg.Diagnostics
 }
 
 func (g *tsGenerator) generatePropertyObject(prop eg.PropertyObject, sl eg.PropertyCtx) {
 	g.generateClass(prop)
 	g.generateJSONDict(prop)
+	g.generateAccessTypes(prop)
 	g.generatePayload(prop)
 	g.generateBuilder(prop)
 	g.generateFactory(prop)
-
-	os.MkdirAll(g.cfg.OutputDir, 0755)
-
-	fname := filepath.Join(g.cfg.OutputDir, getObjectFileName(prop)+".ts")
-	tmpFname := filepath.Join(g.cfg.OutputDir, "."+getObjectFileName(prop)+uuid.New().String()+".ts")
-
-	fmt.Printf("Generate: %s -> %s\n", prop.Meta().FileName().Value(), fname)
-	wr, err := os.OpenFile(tmpFname, os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		panic(err)
+	if len(g.diagnostics) > 0 {
+		// the generators report their errors by panic
+		panic(g.diagnostics)
 	}
-	defer func() {
-		wr.Close()
-		os.Remove(fname)
-		os.Rename(tmpFname, fname)
-	}()
+
+	myFname := g.files.entityFile(prop)
+	fname := g.files.outputFile(myFname)
+	g.cfg.Logf("Generate: %s -> %s\n", prop.Meta().FileName().Value(), fname)
 
 	header := eg.NewForIfWhileLangWriter(eg.ForIfWhileLangWriter{OfsIndent: g.cfg.EntityCfg.Indent})
 	if len(g.includes.ActiveTypes()) > 0 {
-		myFname := getObjectFileName(prop)
 		for _, externalTyp := range g.includes.ActiveTypes() {
 			filename := externalTyp.tsFileName
 			if filename == myFname {
 				continue
 			}
+			if externalTyp.local {
+				filename = g.files.importPath(myFname, filename)
+			} else {
+				filename = g.files.externalPath(filename)
+			}
 			// if externalTyp.activated && externalTyp.property.IsSome() {
 			// 	filename = getObjectFileName(externalTyp.property.Value())
 			// }
 			// getObjectName(include.property)
-			if len(externalTyp.Types()) <= 3 {
-				header.FormatLine("import { %s } from %s;", strings.Join(externalTyp.Types(), ", "),
-					g.lang.Quote(filename))
+			if g.cfg.EntityCfg.TypeImports {
+				g.writeImport(header, "import type", externalTyp.Imports(true), filename)
+				g.writeImport(header, "import", externalTyp.Imports(false), filename)
 			} else {
-				header.WriteBlock("", "import", func(wr *eg.ForIfWhileLangWriter) {
-					for idx, t := range externalTyp.Types() {
-						comma := ","
-						if idx == len(externalTyp.Types())-1 {
-							comma = ""
-						}
-						wr.FormatLine("%s%s", t, comma)
-					}
-				}, " {", fmt.Sprintf("} from %s;", g.lang.Quote(filename)))
+				g.writeImport(header, "import", externalTyp.Types(), filename)
 			}
 		}
 		header.WriteLine()
 	}
 
+	content := bytes.Buffer{}
 	for _, line := range header.Lines() {
-		wr.Write([]byte(line))
+		content.WriteString(line)
 	}
-
 	for _, line := range g.bodyWriter.Lines() {
-		wr.Write([]byte(line))
+		content.WriteString(line)
+	}
+	err := g.cfg.WriteFile(fname, content.Bytes())
+	if err != nil {
+		panic(err)
 	}
-	// sl.Registry.SetWritten(prop)
 }
 
+func (g *tsGenerator) writeImport(header *eg.ForIfWhileLangWriter, keyword string, types []string, filename string) {
+	if len(types) == 0 {
+		return
+	}
+	if len(types) <= 3 {
+		header.FormatLine("%s { %s } from %s;", keyword, strings.Join(types, ", "), g.lang.Quote(filename))
+		return
+	}
+	header.WriteBlock("", keyword, func(wr *eg.ForIfWhileLangWriter) {
+		for idx, t := range types {
+			comma := ","
+			if idx == len(types)-1 {
+				comma = ""
+			}
+			wr.FormatLine("%s%s", t, comma)
+		}
+	}, " {", fmt.Sprintf("} from %s;", g.lang.Quote(filename)))
+}
+
+// wuesteTypes are the interfaces and types of the wueste runtime, they
+// have no value.
+var wuesteTypes = map[string]bool{
+	"WuestePayload":             true,
+	"WuestenAttribute":          true,
+	"WuestenAttributeParameter": true,
+	"WuestenBuilder":            true,
+	"WuestenDecoder":            true,
+	"WuestenEncoder":            true,
+	"WuestenFNGetBuilder":       true,
+	"WuestenFactory":            true,
+	"WuestenGetterFn":           true,
+	"WuestenNames":              true,
+	"WuestenReflection":         true,
+	"WuestenReflectionArray":    true,
+	"WuestenReflectionObject":   true,
+	"WuestenReflectionValue":    true,
+	"WuesteCoerceTypeDate":      true,
+	"WuesteCoerceTypeboolean":   true,
+	"WuesteCoerceTypenumber":    true,
+	"WuesteCoerceTypestring":    true,
+}
+
+// entityTypes are the suffixes of the interfaces and types of an entity.
+var entityTypes = []string{"", "Param", "Object", "Payload", "CoerceType"}
+
 type externalType struct {
 	// toGenerate bool
 	// activated  bool
 	// prefix     string
 	schemaFileName string
-	tsFileName     string
-	types          map[string]*string
-	fileProperty   rusty.Optional[eg.Property]
+	// tsFileName is a module specifier or the file of an entity, which is
+	// relative to the output dir if local
+	tsFileName string
+	local      bool
+	types      map[string]*string
+	// typeOnly are the types which have no value
+	typeOnly map[string]bool
+	// fileProperties are the entities of the file, the ones of different
+	// schemas collide in the output
+	fileProperties []eg.Property
 }
 
 type ImportType struct {
@@ -1499,18 +1600,35 @@ type ImportType struct {
 
 func (et *externalType) Types() []string {
 	types := make([]string, 0, len(et.types))
-	for k, alias := range et.types {
-		str := k
-		if alias != nil {
-			str = fmt.Sprintf("%s as %s", k, *alias)
+	for k := range et.types {
+		types = append(types, et.importName(k))
+	}
+	sort.Strings(types)
+	return types
+}
+
+// Imports returns the sorted types which have no value if typeOnly,
+// otherwise the others.
+func (et *externalType) Imports(typeOnly bool) []string {
+	types := []string{}
+	for k := range et.types {
+		if et.typeOnly[k] == typeOnly {
+			types = append(types, et.importName(k))
 		}
-		types = append(types, str)
 	}
 	sort.Strings(types)
 	return types
 }
 
+func (et *externalType) importName(k string) string {
+	if alias := et.types[k]; alias != nil {
+		return fmt.Sprintf("%s as %s", k, *alias)
+	}
+	return k
+}
+
 type externalTypes struct {
+	files tsFiles
 	types map[string]*externalType
 }
 
@@ -1527,25 +1645,35 @@ func (g *externalTypes) AddProperty(typ string, prop eg.Property) {
 	// 	// po = prop.(eg.PropertyObject)
 	// 	o_ok = true
 	// }
-	fileName := getObjectFileName(prop)
+	fileName := g.files.entityFile(prop)
 	et, ok := g.types[fileName]
 	if !ok {
 		et = &externalType{
 			// toGenerate:     true,
 			schemaFileName: prop.Meta().FileName().Value(),
 			tsFileName:     fileName,
+			local:          true,
 			types:          make(map[string]*string),
+			typeOnly:       make(map[string]bool),
 		}
 		g.types[fileName] = et
 	}
 	et.types[typ] = nil
+	for _, suffix := range entityTypes {
+		if typ == (&tsLang{}).PublicType(getObjectName(prop), suffix) {
+			et.typeOnly[typ] = true
+		}
+	}
 	po, ok := prop.(eg.PropertyObject)
-	if ok && et.fileProperty.IsNone() {
-		// fmt.Printf("TOP-AddProperty: %s -> %s\n", typ, fileName)
-		et.fileProperty = rusty.Some[eg.Property](po)
-	} else {
-		// fmt.Printf("AddProperty: %s -> %s\n", typ, fileName)
+	if !ok {
+		return
+	}
+	for _, fp := range et.fileProperties {
+		if eg.SchemaIdentity(fp) == eg.SchemaIdentity(po) {
+			return
+		}
 	}
+	et.fileProperties = append(et.fileProperties, po)
 }
 
 func (g *externalTypes) AddType(fileName, typeName string, optAlias ...string) *externalType {
@@ -1562,10 +1690,12 @@ func (g *externalTypes) AddType(fileName, typeName string, optAlias ...string) *
 			// toGenerate: false,
 			tsFileName: fileName,
 			types:      make(map[string]*string),
+			typeOnly:   make(map[string]bool),
 		}
 		g.types[fileName] = et
 	}
 	et.types[typeName] = alias
+	et.typeOnly[typeName] = wuesteTypes[typeName]
 	return et
 }
 
@@ -1582,8 +1712,9 @@ func (g *externalTypes) ActiveTypes() []*externalType {
 	return atyp
 }
 
-func newExternalTypes() *externalTypes {
+func newExternalTypes(files tsFiles) *externalTypes {
 	return &externalTypes{
+		files: files,
 		types: make(map[string]*externalType),
 	}
 }
@@ -1593,20 +1724,29 @@ func TsGenerator(cfg *eg.GeneratorConfig, prop eg.Property, sl eg.PropertyCtx) {
 	// if !found {
 	// 	panic("TsGenerator not a property object")
 	// }
-	// if sl.Registry.IsWritten(jsf) {
-	// 	return
-	// }
+	// entities reached through several refs are generated once
+	files := newTsFiles(cfg)
+	rClaim := sl.Registry.ClaimOutput(files.outputFile(files.entityFile(prop)), eg.SchemaIdentity(prop))
+	if rClaim.IsErr() {
+		panic(rClaim.Err())
+	}
+	if !rClaim.Ok() {
+		return
+	}
 	g := &tsGenerator{
+		entity:     prop,
 		cfg:        cfg,
-		includes:   newExternalTypes(),
+		files:      files,
+		includes:   newExternalTypes(files),
 		bodyWriter: eg.NewForIfWhileLangWriter(eg.ForIfWhileLangWriter{OfsIndent: cfg.EntityCfg.Indent}),
 	}
 
 	g.generatePropertyObject(prop.(eg.PropertyObject), sl)
 	for _, at := range g.includes.ActiveTypes() {
-		if at.fileProperty.IsSome() &&
-			at.fileProperty.Value().Id() != prop.Id() {
-			TsGenerator(cfg, at.fileProperty.Value(), sl)
+		for _, fp := range at.fileProperties {
+			if fp.Id() != prop.Id() {
+				TsGenerator(cfg, fp, sl)
+			}
 		}
 	}
 }
@@ -7,6 +7,7 @@ import (
 	"os/exec"
 	"strings"
 	"testing"
+	"testing/fstest"
 
 	eg "github.com/mabels/wueste/entity-generator"
 	"github.com/stretchr/testify/assert"
@@ -65,12 +66,6 @@ func TestTypescript(t *testing.T) {
 	// 		TsGenerator(cfg, prop.property.Value(), sl)
 	// 	}
 	// }
-	// for _, p := range sl.Registry.Items() {
-	// 	// if !p.Written() {
-	// 	// 	continue
-	// 	// }
-	// 	TsGenerator(cfg, p.Property(), sl)
-	// }
 	err := runCmd("npm run build:js")
 	if err != nil {
 		t.Fatal(err)
@@ -81,16 +76,149 @@ func TestTypescript(t *testing.T) {
 	}
 }
 
-func TestMainAction(t *testing.T) {
-	cfg := getConfig()
-	MainAction([]string{
-		"--write-test-schema", "true",
-		"--include-dir", "../../src/generated",
-		"--input-file", "go/base.schema.json",
-		"--input-file", "../../src/generated/go/simple_type.schema.json",
-		"--input-file", "../generated/go/nested_type.schema.json",
-		"--eg-from-wueste", cfg.EntityCfg.FromWueste,
-		// "--eg-from-result", cfg.EntityCfg.FromResult,
-		"--output-dir", "../../src/generated/go",
-	}, "test", "test")
+func TestGenerateInMemory(t *testing.T) {
+	fsys := fstest.MapFS{
+		"order.schema.json": {Data: []byte(`{
+	"$id": "https://Order", "title": "Order", "type": "object",
+	"properties": { "id": { "type": "string" } },
+	"required": ["id"]
+}`)},
+	}
+	sl := eg.PropertyCtx{Registry: eg.NewSchemaRegistry(eg.NewFSSchemaLoader(fsys))}
+	out := eg.NewMemoryOutput()
+	cfg := &eg.GeneratorConfig{
+		OutputDir: "generated",
+		EntityCfg: eg.Config{Indent: "  ", FromWueste: "wueste"},
+		Output:    out,
+	}
+	prop := eg.LoadSchemaFile(sl, "order.schema.json")
+	assert.True(t, prop.IsOk())
+	TsGenerator(cfg, prop.Ok(), sl)
+	assert.Equal(t, []string{"generated/order.ts"}, out.FileNames())
+	assert.True(t, strings.Contains(string(out.File("generated/order.ts").Value()), "export interface Order {"))
+}
+
+func TestGenerateRecursive(t *testing.T) {
+	fsys := fstest.MapFS{
+		"tree.schema.json": {Data: []byte(`{
+	"$id": "https://Tree", "title": "Tree", "type": "object",
+	"properties": {
+		"name": { "type": "string" },
+		"children": { "type": "array", "items": { "$ref": "file://tree.schema.json" } },
+		"leaf": { "$ref": "file://leaf.schema.json" }
+	},
+	"required": ["name", "children"]
+}`)},
+		"leaf.schema.json": {Data: []byte(`{
+	"$id": "https://Leaf", "title": "Leaf", "type": "object",
+	"properties": { "tree": { "$ref": "file://tree.schema.json" } }
+}`)},
+		"wood.schema.json": {Data: []byte(`{
+	"$id": "https://Wood", "title": "Wood", "type": "object",
+	"properties": { "leaf": { "$ref": "file://leaf.schema.json" } }
+}`)},
+	}
+	sl := eg.PropertyCtx{Registry: eg.NewSchemaRegistry(eg.NewFSSchemaLoader(fsys))}
+	out := eg.NewMemoryOutput()
+	cfg := &eg.GeneratorConfig{
+		OutputDir: "generated",
+		EntityCfg: eg.Config{Indent: "  ", FromWueste: "wueste"},
+		Output:    out,
+		Log:       io.Discard,
+	}
+	TsGenerator(cfg, eg.LoadSchemaFile(sl, "tree.schema.json").Ok(), sl)
+	TsGenerator(cfg, eg.LoadSchemaFile(sl, "wood.schema.json").Ok(), sl)
+	// every entity is generated once by its name below the referring entity
+	assert.Equal(t, []string{"generated/tree$leaf.ts", "generated/tree.ts", "generated/wood$leaf$tree.ts", "generated/wood$leaf.ts", "generated/wood.ts"}, out.FileNames())
+
+	tree := string(out.File("generated/tree.ts").Value())
+	assert.Contains(t, tree, "readonly children: Tree[];")
+	assert.Contains(t, tree, "get _children(): Tree$childrenBuilder {")
+	assert.Contains(t, tree, "get _leaf(): WuestenObjectOptional<Tree$LeafBuilder, Tree$Leaf, Tree$LeafCoerceType|undefined> {")
+	assert.Contains(t, tree, `ref: "Tree",`)
+	assert.NotContains(t, tree, "property: TreeSchema")
+	assert.Contains(t, tree, "  Tree$LeafFactory,\n  Tree$LeafGetter,\n  Tree$LeafObject\n} from \"./tree$leaf\";")
+
+	wood := string(out.File("generated/wood.ts").Value())
+	// wood is not part of the cycle
+	assert.Contains(t, wood, "readonly _leaf: WuestenObjectOptional<Wood$LeafBuilder, Wood$Leaf, Wood$LeafCoerceType|undefined>")
+	assert.Contains(t, wood, "property: Wood$LeafSchema")
+}
+
+func TestGenerateExtensionHooks(t *testing.T) {
+	fsys := fstest.MapFS{
+		"user.schema.json": {Data: []byte(`{
+	"$id": "https://User", "title": "User", "type": "object",
+	"properties": {
+		"name": { "type": "string", "x-groups": ["key"] },
+		"password": { "type": "string", "x-sensitive": true, "x-team": "auth" },
+		"id": { "type": "string", "x-ts-type": "UserId" }
+	}
+}`)},
+	}
+	sl := eg.PropertyCtx{Registry: eg.NewSchemaRegistry(eg.NewFSSchemaLoader(fsys))}
+	assert.NoError(t, sl.Registry.Extensions.Register(eg.Extension{
+		Name: "x-sensitive",
+		Hooks: map[string]any{
+			"ts": ExtensionHook{
+				Reflection: func(value any) (string, bool) { return "", false },
+				Comment: func(value any) []string {
+					return []string{"sensitive: do not log"}
+				},
+			},
+		},
+	}))
+	assert.NoError(t, sl.Registry.Extensions.Register(eg.Extension{
+		Name: "x-ts-type",
+		Hooks: map[string]any{
+			"ts": ExtensionHook{
+				Type: func(value any) (string, bool) {
+					typ, ok := value.(string)
+					return typ, ok
+				},
+			},
+		},
+	}))
+	out := eg.NewMemoryOutput()
+	cfg := &eg.GeneratorConfig{
+		OutputDir: "generated",
+		EntityCfg: eg.Config{Indent: "  ", FromWueste: "wueste"},
+		Output:    out,
+		Log:       io.Discard,
+	}
+	TsGenerator(cfg, eg.LoadSchemaFile(sl, "user.schema.json").Ok(), sl)
+	user := string(out.File("generated/user.ts").Value())
+	assert.Contains(t, user, "  readonly id?: UserId;\n")
+	assert.Contains(t, user, "  // sensitive: do not log\n  readonly password?: string;\n")
+	assert.Contains(t, user, `"x-groups": ["key"],`)
+	// unknown extensions are written as they are
+	assert.Contains(t, user, `"x-team": "auth",`)
+	assert.NotContains(t, user, `"x-sensitive"`)
+}
+
+func TestGenerateExtensionInvalidValue(t *testing.T) {
+	fsys := fstest.MapFS{
+		"user.schema.json5": {Data: []byte(`{
+	$id: "https://User", title: "User", type: "object",
+	properties: { name: { type: "string", "x-limit": NaN } },
+}`)},
+	}
+	sl := eg.PropertyCtx{Registry: eg.NewSchemaRegistry(eg.NewFSSchemaLoader(fsys))}
+	out := eg.NewMemoryOutput()
+	cfg := &eg.GeneratorConfig{
+		OutputDir: "generated",
+		EntityCfg: eg.Config{Indent: "  ", FromWueste: "wueste"},
+		Output:    out,
+		Log:       io.Discard,
+	}
+	var ds eg.Diagnostics
+	func() {
+		defer func() { ds, _ = recover().(eg.Diagnostics) }()
+		TsGenerator(cfg, eg.LoadSchemaFile(sl, "user.schema.json5").Ok(), sl)
+	}()
+	assert.Equal(t, 1, len(ds))
+	assert.Equal(t, eg.DiagInvalidExtension, ds[0].Code)
+	assert.Equal(t, "/user.schema.json5", ds[0].File)
+	assert.Contains(t, ds[0].Message, "x-limit: json: unsupported value: NaN")
+	assert.Equal(t, []string{}, out.FileNames())
 }
@@ -0,0 +1,93 @@
+package ts
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	eg "github.com/mabels/wueste/entity-generator"
+)
+
+// jsDocText escapes the end of the comment in text.
+func jsDocText(text string) string {
+	return strings.ReplaceAll(text, "*/", "*\\/")
+}
+
+// jsDocConstraints are the tags of the constraints of prop.
+func jsDocConstraints(prop eg.Property) []string {
+	tags := []string{}
+	tag := func(name string, isSome bool, value func() any) {
+		if isSome {
+			tags = append(tags, fmt.Sprintf("@%s %v", name, value()))
+		}
+	}
+	switch p := prop.(type) {
+	case eg.PropertyString:
+		tag("format", p.Format().IsSome(), func() any { return p.Format().Value() })
+	case eg.PropertyInteger:
+		tag("format", p.Format().IsSome(), func() any { return p.Format().Value() })
+		tag("minimum", p.Minimum().IsSome(), func() any { return p.Minimum().Value() })
+		tag("maximum", p.Maximum().IsSome(), func() any { return p.Maximum().Value() })
+		tag("exclusiveMinimum", p.ExclusiveMinimum().IsSome(), func() any { return p.ExclusiveMinimum().Value() })
+		tag("exclusiveMaximum", p.ExclusiveMaximum().IsSome(), func() any { return p.ExclusiveMaximum().Value() })
+	case eg.PropertyNumber:
+		tag("format", p.Format().IsSome(), func() any { return p.Format().Value() })
+		tag("minimum", p.Minimum().IsSome(), func() any { return p.Minimum().Value() })
+		tag("maximum", p.Maximum().IsSome(), func() any { return p.Maximum().Value() })
+		tag("exclusiveMinimum", p.ExclusiveMinimum().IsSome(), func() any { return p.ExclusiveMinimum().Value() })
+		tag("exclusiveMaximum", p.ExclusiveMaximum().IsSome(), func() any { return p.ExclusiveMaximum().Value() })
+	case eg.PropertyArray:
+		tag("minItems", p.MinItems().IsSome(), func() any { return p.MinItems().Value() })
+		tag("maxItems", p.MaxItems().IsSome(), func() any { return p.MaxItems().Value() })
+	}
+	return tags
+}
+
+// jsDoc returns the lines of the JSDoc of prop from the description, the
+// default, the constraints, the examples and deprecated. It is empty if
+// there is nothing to document.
+func jsDoc(prop eg.Property) []string {
+	text := []string{}
+	if prop.Description().IsSome() && strings.TrimSpace(prop.Description().Value()) != "" {
+		text = strings.Split(strings.TrimSpace(prop.Description().Value()), "\n")
+	}
+	tags := []string{}
+	if def := getDefaultForProperty(prop); def != nil {
+		tags = append(tags, "@default "+*def)
+	}
+	tags = append(tags, jsDocConstraints(prop)...)
+	for _, example := range prop.Examples() {
+		bytes, err := json.Marshal(example)
+		if err != nil {
+			panic(err)
+		}
+		tags = append(tags, "@example "+string(bytes))
+	}
+	if prop.Deprecated().IsSome() && prop.Deprecated().Value() {
+		tags = append(tags, "@deprecated")
+	}
+	switch len(text) + len(tags) {
+	case 0:
+		return nil
+	case 1:
+		return []string{"/** " + jsDocText(strings.Join(append(text, tags...), "")) + " */"}
+	}
+	lines := []string{"/**"}
+	for _, line := range text {
+		lines = append(lines, strings.TrimRight(" * "+jsDocText(line), " "))
+	}
+	if len(text) > 0 && len(tags) > 0 {
+		lines = append(lines, " *")
+	}
+	for _, tag := range tags {
+		lines = append(lines, " * "+jsDocText(tag))
+	}
+	return append(lines, " */")
+}
+
+// writeJSDoc writes the JSDoc of prop.
+func writeJSDoc(wr *eg.ForIfWhileLangWriter, prop eg.Property) {
+	for _, line := range jsDoc(prop) {
+		wr.WriteLine(line)
+	}
+}
@@ -0,0 +1,76 @@
+package ts
+
+import (
+	"io"
+	"testing"
+	"testing/fstest"
+
+	eg "github.com/mabels/wueste/entity-generator"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateJSDoc(t *testing.T) {
+	fsys := fstest.MapFS{
+		"user.schema.json": {Data: []byte(`{
+	"$id": "https://User", "title": "User", "type": "object",
+	"description": "a user of the app",
+	"properties": {
+		"name": { "type": "string", "description": "the login\nof the */ user", "default": "anon", "examples": ["bob"] },
+		"age": { "type": "integer", "minimum": 0, "maximum": 150 },
+		"nick": { "type": "string", "deprecated": true, "$comment": "not shown" },
+		"id": { "type": "string" }
+	}
+}`)},
+	}
+	sl := eg.PropertyCtx{Registry: eg.NewSchemaRegistry(eg.NewFSSchemaLoader(fsys))}
+	out := eg.NewMemoryOutput()
+	cfg := &eg.GeneratorConfig{
+		OutputDir: "generated",
+		EntityCfg: eg.Config{Indent: "  ", FromWueste: "wueste"},
+		Output:    out,
+		Log:       io.Discard,
+	}
+	TsGenerator(cfg, eg.LoadSchemaFile(sl, "user.schema.json").Ok(), sl)
+	user := string(out.File("generated/user.ts").Value())
+
+	assert.Contains(t, user, "/** a user of the app */\nexport interface User {\n")
+	assert.Contains(t, user, "/** a user of the app */\nexport interface UserObject {\n")
+	name := `  /**
+   * the login
+   * of the *\/ user
+   *
+   * @default "anon"
+   * @example "bob"
+   */
+`
+	assert.Contains(t, user, name+"  readonly name?: string;\n")
+	assert.Contains(t, user, name+"  readonly \"name\"?: string;\n")
+	assert.Contains(t, user, name+"  name(v?: ")
+	assert.Contains(t, user, "  /**\n   * @minimum 0\n   * @maximum 150\n   */\n  readonly age?: number;\n")
+	assert.Contains(t, user, "  /** @deprecated */\n  nick(v?: ")
+	assert.NotContains(t, user, "not shown")
+	assert.Contains(t, user, "\n  readonly id?: string;\n")
+	assert.NotContains(t, user, "*/\n  readonly id?: string;\n")
+}
+
+func TestGenerateJSDocDraft04Bounds(t *testing.T) {
+	fsys := fstest.MapFS{
+		"old.schema.json": {Data: []byte(`{
+	"$schema": "http://json-schema.org/draft-04/schema#",
+	"id": "https://Old", "title": "Old", "type": "object",
+	"properties": {
+		"pos": { "type": "integer", "minimum": 5, "exclusiveMinimum": true }
+	}
+}`)},
+	}
+	sl := eg.PropertyCtx{Registry: eg.NewSchemaRegistry(eg.NewFSSchemaLoader(fsys))}
+	out := eg.NewMemoryOutput()
+	cfg := &eg.GeneratorConfig{
+		OutputDir: "generated",
+		EntityCfg: eg.Config{Indent: "  ", FromWueste: "wueste"},
+		Output:    out,
+		Log:       io.Discard,
+	}
+	TsGenerator(cfg, eg.LoadSchemaFile(sl, "old.schema.json").Ok(), sl)
+	assert.Contains(t, string(out.File("generated/old.ts").Value()), "  /** @exclusiveMinimum 5 */\n  readonly pos?: number;\n")
+}
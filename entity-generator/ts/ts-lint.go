@@ -0,0 +1,91 @@
+package ts
+
+import (
+	"fmt"
+	"strings"
+
+	eg "github.com/mabels/wueste/entity-generator"
+)
+
+func lintPropertyNames(node eg.LintNode) []string {
+	v, found := node.Schema.Lookup("properties")
+	if !found {
+		return nil
+	}
+	props, ok := eg.AsJSONDict(v)
+	if !ok {
+		return nil
+	}
+	return props.Keys()
+}
+
+// lintNameCollision reports properties which get the same name in the
+// generated classes.
+func lintNameCollision(node eg.LintNode) []eg.LintFinding {
+	lang := &tsLang{}
+	byPublicName := map[string][]string{}
+	findings := []eg.LintFinding{}
+	for _, name := range lintPropertyNames(node) {
+		publicName := lang.PublicName(name)
+		prev := byPublicName[publicName]
+		if len(prev) > 0 {
+			findings = append(findings, eg.LintFinding{
+				Pointer: node.Pointer + "/properties/" + eg.EscapeJSONPointer(name),
+				Message: fmt.Sprintf("property %s collides with %s as %s", name, strings.Join(prev, ", "), publicName),
+			})
+		}
+		byPublicName[publicName] = append(prev, name)
+	}
+	return findings
+}
+
+func lintKeywordTitle(node eg.LintNode) []eg.LintFinding {
+	title, found := node.Schema.Lookup("title")
+	if !found || node.Type() != eg.OBJECT {
+		return nil
+	}
+	if !keyWords[strings.TrimLeft(reSplitNonAllowed.ReplaceAllString(fmt.Sprint(title), "_"), "_")] {
+		return nil
+	}
+	return []eg.LintFinding{{
+		Pointer: node.Pointer + "/title",
+		Message: fmt.Sprintf("title %v is a TypeScript keyword and can not be a type name", title),
+	}}
+}
+
+func lintKeywordProperty(node eg.LintNode) []eg.LintFinding {
+	findings := []eg.LintFinding{}
+	for _, name := range lintPropertyNames(node) {
+		if keyWords[name] {
+			findings = append(findings, eg.LintFinding{
+				Pointer: node.Pointer + "/properties/" + eg.EscapeJSONPointer(name),
+				Message: fmt.Sprintf("property %s is a TypeScript keyword and is quoted", name),
+			})
+		}
+	}
+	return findings
+}
+
+// LintRules are the rules of the TypeScript generator.
+func LintRules() []eg.LintRule {
+	return []eg.LintRule{
+		{
+			Name:        "ts-name-collision",
+			Description: "property names which are the same after sanitizing",
+			Severity:    eg.SeverityError,
+			Check:       lintNameCollision,
+		},
+		{
+			Name:        "ts-keyword-title",
+			Description: "titles which are TypeScript keywords",
+			Severity:    eg.SeverityError,
+			Check:       lintKeywordTitle,
+		},
+		{
+			Name:        "ts-keyword-property",
+			Description: "property names which are TypeScript keywords",
+			Severity:    eg.SeverityWarning,
+			Check:       lintKeywordProperty,
+		},
+	}
+}
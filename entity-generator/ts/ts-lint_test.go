@@ -0,0 +1,33 @@
+package ts
+
+import (
+	"encoding/json"
+	"testing"
+
+	eg "github.com/mabels/wueste/entity-generator"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLintRules(t *testing.T) {
+	js := eg.NewJSONDict()
+	assert.NoError(t, json.Unmarshal([]byte(`{
+		"$id": "https://Type", "title": "type", "type": "object",
+		"properties": {
+			"first-name": { "type": "string" },
+			"first_name": { "type": "string" },
+			"first name": { "type": "string" },
+			"new": { "type": "string" }
+		}
+	}`), js))
+	res := eg.Lint("type.schema.json", js, eg.LintOptions{Rules: LintRules()})
+	out := []string{}
+	for _, d := range res.Diagnostics {
+		out = append(out, d.Code+" #"+d.Pointer+": "+d.Message)
+	}
+	assert.Equal(t, []string{
+		"ts-name-collision #/properties/first_name: property first_name collides with first-name as first_name",
+		"ts-name-collision #/properties/first name: property first name collides with first-name, first_name as first_name",
+		"ts-keyword-title #/title: title type is a TypeScript keyword and can not be a type name",
+		"ts-keyword-property #/properties/new: property new is a TypeScript keyword and is quoted",
+	}, out)
+}
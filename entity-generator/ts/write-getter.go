@@ -228,7 +228,7 @@ func (g *tsGenerator) writeReflectionGetter(wr *eg.ForIfWhileLangWriter, baseNam
 				panic("there must be a level")
 			}
 			lastLevel := path[len(path)-1]
-			if lastLevel.prop.Type() == eg.OBJECTITEM {
+			if lastLevel.prop.Type() == eg.OBJECTITEM || g.isRecursive(prop) {
 				// SimpleType$PayloadGetter(v0.sub, [])
 				getterName := g.lang.PublicName(getObjectName(prop), "Getter")
 				g.includes.AddProperty(getterName, prop)
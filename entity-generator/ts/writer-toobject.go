@@ -103,8 +103,14 @@ func (g *tsGenerator) generateObjectToObject(pi eg.PropertyItem) string {
 }
 
 func (g *tsGenerator) writeObjectToObject(wr *eg.ForIfWhileLangWriter, prop eg.PropertyObject) {
+	g.writeItemsToObject(wr, prop.Items(), fmt.Sprintf("ret as unknown as %s", g.lang.PublicName(getObjectName(prop), "Object")))
+}
+
+// writeItemsToObject converts the items of v0 into ret which is returned by
+// the expression retExpr.
+func (g *tsGenerator) writeItemsToObject(wr *eg.ForIfWhileLangWriter, items []eg.PropertyItem, retExpr string) {
 	wr.WriteLine("const ret: Record<string, unknown> = {}")
-	for _, pi := range prop.Items() {
+	for _, pi := range items {
 		if !pi.Optional() {
 			wr.FormatLine("ret[%s] = %s", g.lang.Quote(pi.Name()), g.generateObjectToObject(pi))
 			continue
@@ -114,5 +120,5 @@ func (g *tsGenerator) writeObjectToObject(wr *eg.ForIfWhileLangWriter, prop eg.P
 			wr.FormatLine("ret[%s] = %s", g.lang.Quote(pi.Name()), g.generateObjectToObject(pi))
 		})
 	}
-	wr.FormatLine("return ret as unknown as %s;", g.lang.PublicName(getObjectName(prop), "Object"))
+	wr.FormatLine("return %s;", retExpr)
 }
@@ -0,0 +1,34 @@
+package entity_generator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	assert.Equal(t, "", UnifiedDiff("a", "b", []byte("x\ny\n"), []byte("x\ny\n")))
+	old := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n16\n17\n18\n19\n20\n"
+	updated := "1\n2\nthree\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n16\n17\n19\n20\n21"
+	assert.Equal(t, `--- a
++++ b
+@@ -1,6 +1,6 @@
+ 1
+ 2
+-3
++three
+ 4
+ 5
+ 6
+@@ -15,6 +15,6 @@
+ 15
+ 16
+ 17
+-18
+ 19
+ 20
++21
+\ No newline at end of file
+`, UnifiedDiff("a", "b", []byte(old), []byte(updated)))
+	assert.Equal(t, "--- /dev/null\n+++ b\n@@ -0,0 +1,2 @@\n+x\n+y\n", UnifiedDiff("/dev/null", "b", nil, []byte("x\ny\n")))
+}
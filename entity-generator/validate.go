@@ -0,0 +1,231 @@
+package entity_generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// ValidationError is a violation of the schema at the JSON pointer Path
+// of the validated value.
+type ValidationError struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("#%s: %s", e.Path, e.Message)
+}
+
+type validator struct {
+	errors []ValidationError
+}
+
+func (v *validator) errorf(path string, format string, args ...any) {
+	v.errors = append(v.errors, ValidationError{
+		Path:    path,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func jsonTypeOf(val any) string {
+	switch val.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return BOOLEAN
+	case string:
+		return STRING
+	case float64, float32, int, int32, int64, uint, uint32, uint64, json.Number:
+		return NUMBER
+	case []any:
+		return ARRAY
+	case map[string]any:
+		return OBJECT
+	}
+	if _, ok := AsJSONDict(val); ok {
+		return OBJECT
+	}
+	return fmt.Sprintf("%T", val)
+}
+
+func asFloat64(val any) (float64, bool) {
+	switch n := val.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func lookupObjectValue(val any, key string) (any, bool) {
+	if m, ok := val.(map[string]any); ok {
+		v, found := m[key]
+		return v, found
+	}
+	if js, ok := AsJSONDict(val); ok {
+		return js.Lookup(key)
+	}
+	return nil, false
+}
+
+func (v *validator) expectType(path string, typ Type, val any) bool {
+	got := jsonTypeOf(val)
+	if got == typ {
+		return true
+	}
+	v.errorf(path, "expected %s got %s", typ, got)
+	return false
+}
+
+func (v *validator) validateObject(po PropertyObject, path string, val any) {
+	if !v.expectType(path, OBJECT, val) {
+		return
+	}
+	for _, pi := range po.Items() {
+		itemPath := path + "/" + EscapeJSONPointer(pi.Name())
+		itemVal, found := lookupObjectValue(val, pi.Name())
+		if !found {
+			if !pi.Optional() {
+				v.errorf(path, "missing required property %s", pi.Name())
+			}
+			continue
+		}
+		v.validate(pi.Property(), itemPath, itemVal)
+	}
+}
+
+func (v *validator) validateArray(pa PropertyArray, path string, val any) {
+	if !v.expectType(path, ARRAY, val) {
+		return
+	}
+	items := val.([]any)
+	if pa.MinItems().IsSome() && len(items) < pa.MinItems().Value() {
+		v.errorf(path, "expected at least %d items got %d", pa.MinItems().Value(), len(items))
+	}
+	if pa.MaxItems().IsSome() && len(items) > pa.MaxItems().Value() {
+		v.errorf(path, "expected at most %d items got %d", pa.MaxItems().Value(), len(items))
+	}
+	for i, item := range items {
+		v.validate(pa.Items(), path+"/"+strconv.Itoa(i), item)
+	}
+}
+
+var stringFormatLayouts = map[StringFormat][]string{
+	DATE_TIME: {time.RFC3339Nano},
+	DATE:      {"2006-01-02"},
+	TIME:      {"15:04:05Z07:00", "15:04:05.999999999Z07:00", "15:04:05", "15:04:05.999999999"},
+}
+
+func (v *validator) validateString(ps PropertyString, path string, val any) {
+	if !v.expectType(path, STRING, val) {
+		return
+	}
+	if ps.Format().IsNone() {
+		return
+	}
+	layouts, found := stringFormatLayouts[ps.Format().Value()]
+	if !found {
+		// unknown formats are annotations only
+		return
+	}
+	for _, layout := range layouts {
+		if _, err := time.Parse(layout, val.(string)); err == nil {
+			return
+		}
+	}
+	v.errorf(path, "%q is not a valid %s", val, ps.Format().Value())
+}
+
+func (v *validator) validateInteger(pi PropertyInteger, path string, val any) {
+	f, ok := asFloat64(val)
+	if !ok {
+		v.errorf(path, "expected %s got %s", INTEGER, jsonTypeOf(val))
+		return
+	}
+	if f != math.Trunc(f) {
+		v.errorf(path, "expected %s got %v", INTEGER, val)
+		return
+	}
+	if pi.Format().IsSome() && pi.Format().Value() == "int32" && (f < math.MinInt32 || f > math.MaxInt32) {
+		v.errorf(path, "%v is out of the int32 range", val)
+	}
+	if pi.Minimum().IsSome() && f < float64(pi.Minimum().Value()) {
+		v.errorf(path, "%v is less than the minimum %d", val, pi.Minimum().Value())
+	}
+	if pi.Maximum().IsSome() && f > float64(pi.Maximum().Value()) {
+		v.errorf(path, "%v is greater than the maximum %d", val, pi.Maximum().Value())
+	}
+	if pi.ExclusiveMinimum().IsSome() && f <= float64(pi.ExclusiveMinimum().Value()) {
+		v.errorf(path, "%v is not greater than the exclusive minimum %d", val, pi.ExclusiveMinimum().Value())
+	}
+	if pi.ExclusiveMaximum().IsSome() && f >= float64(pi.ExclusiveMaximum().Value()) {
+		v.errorf(path, "%v is not less than the exclusive maximum %d", val, pi.ExclusiveMaximum().Value())
+	}
+}
+
+func (v *validator) validateNumber(pn PropertyNumber, path string, val any) {
+	f, ok := asFloat64(val)
+	if !ok {
+		v.errorf(path, "expected %s got %s", NUMBER, jsonTypeOf(val))
+		return
+	}
+	if pn.Minimum().IsSome() && f < pn.Minimum().Value() {
+		v.errorf(path, "%v is less than the minimum %v", val, pn.Minimum().Value())
+	}
+	if pn.Maximum().IsSome() && f > pn.Maximum().Value() {
+		v.errorf(path, "%v is greater than the maximum %v", val, pn.Maximum().Value())
+	}
+	if pn.ExclusiveMinimum().IsSome() && f <= pn.ExclusiveMinimum().Value() {
+		v.errorf(path, "%v is not greater than the exclusive minimum %v", val, pn.ExclusiveMinimum().Value())
+	}
+	if pn.ExclusiveMaximum().IsSome() && f >= pn.ExclusiveMaximum().Value() {
+		v.errorf(path, "%v is not less than the exclusive maximum %v", val, pn.ExclusiveMaximum().Value())
+	}
+}
+
+func (v *validator) validate(prop Property, path string, val any) {
+	switch prop.Type() {
+	case OBJECT:
+		v.validateObject(prop.(PropertyObject), path, val)
+	case ARRAY:
+		v.validateArray(prop.(PropertyArray), path, val)
+	case STRING:
+		v.validateString(prop.(PropertyString), path, val)
+	case INTEGER:
+		v.validateInteger(prop.(PropertyInteger), path, val)
+	case NUMBER:
+		v.validateNumber(prop.(PropertyNumber), path, val)
+	case BOOLEAN:
+		v.expectType(path, BOOLEAN, val)
+	default:
+		v.errorf(path, "unsupported schema type %s", prop.Type())
+	}
+}
+
+// Validate checks the value against the schema. The value is a decoded
+// json or yaml document, objects are map[string]any or JSONDict.
+// Properties which are not in the schema are not checked.
+func Validate(prop Property, v any) []ValidationError {
+	val := &validator{errors: []ValidationError{}}
+	val.validate(prop, "", v)
+	return val.errors
+}
@@ -0,0 +1,80 @@
+package entity_generator
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const validateSchema = `{
+	"$id": "https://Order",
+	"title": "Order",
+	"type": "object",
+	"properties": {
+		"id": { "type": "string" },
+		"count": { "type": "integer", "minimum": 1, "maximum": 10 },
+		"price": { "type": "number", "minimum": 0.5 },
+		"paid": { "type": "boolean" },
+		"createdAt": { "type": "string", "format": "date-time" },
+		"day": { "type": "string", "format": "date" },
+		"a/b": { "type": "integer", "format": "int32" },
+		"tags": { "type": "array", "items": { "type": "string" }, "maxItems": 2 },
+		"customer": {
+			"$id": "https://Order/customer",
+			"title": "Customer",
+			"type": "object",
+			"properties": { "name": { "type": "string" } },
+			"required": ["name"]
+		}
+	},
+	"required": ["id", "count"]
+}`
+
+func validateSchemaProperty(t *testing.T) Property {
+	js := NewJSONDict()
+	assert.NoError(t, json.Unmarshal([]byte(validateSchema), js))
+	return NewPropertiesBuilder(NewTestContext()).FromJson(js).Build().Ok()
+}
+
+func TestValidateOk(t *testing.T) {
+	prop := validateSchemaProperty(t)
+	var v any
+	assert.NoError(t, json.Unmarshal([]byte(`{
+		"id": "o1", "count": 3, "price": 1.5, "paid": true,
+		"createdAt": "2023-12-31T23:59:59Z", "day": "2023-12-31", "a/b": 7,
+		"tags": ["a"], "customer": { "name": "jojo" }, "unknown": 1
+	}`), &v))
+	assert.Empty(t, Validate(prop, v))
+
+	js := NewJSONDict()
+	assert.NoError(t, json.Unmarshal([]byte(`{ "id": "o1", "count": 3, "customer": { "name": "jojo" } }`), js))
+	assert.Empty(t, Validate(prop, js))
+	assert.Empty(t, Validate(prop, map[string]any{"id": "o1", "count": 3}))
+}
+
+func TestValidateErrors(t *testing.T) {
+	prop := validateSchemaProperty(t)
+	var v any
+	assert.NoError(t, json.Unmarshal([]byte(`{
+		"count": 3.5, "price": 0.1, "paid": "yes",
+		"createdAt": "yesterday", "day": "2023-13-01", "a/b": 3000000000,
+		"tags": ["a", 1, "c"], "customer": {}
+	}`), &v))
+	assert.Equal(t, []ValidationError{
+		{Path: "", Message: "missing required property id"},
+		{Path: "/count", Message: "expected integer got 3.5"},
+		{Path: "/price", Message: "0.1 is less than the minimum 0.5"},
+		{Path: "/paid", Message: "expected boolean got string"},
+		{Path: "/createdAt", Message: `"yesterday" is not a valid date-time`},
+		{Path: "/day", Message: `"2023-13-01" is not a valid date`},
+		{Path: "/a~1b", Message: "3e+09 is out of the int32 range"},
+		{Path: "/tags", Message: "expected at most 2 items got 3"},
+		{Path: "/tags/1", Message: "expected string got number"},
+		{Path: "/customer", Message: "missing required property name"},
+	}, Validate(prop, v))
+
+	errs := Validate(prop, map[string]any{"id": "o1", "count": 11})
+	assert.Equal(t, "#/count: 11 is greater than the maximum 10", errs[0].Error())
+	assert.Equal(t, "#: expected object got array", Validate(prop, []any{})[0].Error())
+}
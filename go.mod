@@ -8,6 +8,7 @@ require (
 	github.com/spf13/pflag v1.0.5
 	github.com/stretchr/testify v1.8.4
 	github.com/traefik/yaegi v0.15.1
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 replace github.com/iancoleman/orderedmap => github.com/mabels/orderedmap v0.0.0-20230926124100-82392f2f89fe
@@ -15,5 +16,4 @@ replace github.com/iancoleman/orderedmap => github.com/mabels/orderedmap v0.0.0-
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )